@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"net/http"
+)
+
+// Login logs in as a user; the token, if issued, authenticates the client's later requests.
+// When the user has two-factor authentication enabled, the response's challenge token must be
+// exchanged by CompleteTwoFactorLogin.
+func (c *Client) Login(ctx context.Context, login LoginRequest) (*LoginResponse, error) {
+	response, err := c.login(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Token != "" {
+		c.setToken(response.Token)
+	}
+
+	return response, nil
+}
+
+// CompleteTwoFactorLogin exchanges the challenge token from Login, along with a code from the
+// user's authenticator app or a recovery code, for a token authenticating the client's later requests
+func (c *Client) CompleteTwoFactorLogin(ctx context.Context, login TwoFactorLoginRequest) (*LoginResponse, error) {
+	response, err := c.completeTwoFactorLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setToken(response.Token)
+	return response, nil
+}
+
+// Register registers a user from the token of their invitation
+func (c *Client) Register(ctx context.Context, registration RegistrationRequest) (*User, error) {
+	var user User
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/register", body: registration, anonymous: true}, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SwitchAccount switches the account the client acts within to another the user is a member of
+func (c *Client) SwitchAccount(ctx context.Context, accountId string) error {
+	var response LoginResponse
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/switch-account", body: dto.SwitchAccountDto{AccountId: accountId}}, &response)
+	if err != nil {
+		return err
+	}
+
+	// Logging in again with the credentials should return to the same account
+	if c.credentials != nil {
+		c.mu.Lock()
+		c.credentials.AccountId = accountId
+		c.mu.Unlock()
+	}
+
+	c.setToken(response.Token)
+	return nil
+}
+
+func (c *Client) login(ctx context.Context, login LoginRequest) (*LoginResponse, error) {
+	var response LoginResponse
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/login", body: login, anonymous: true}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *Client) completeTwoFactorLogin(ctx context.Context, login TwoFactorLoginRequest) (*LoginResponse, error) {
+	var response LoginResponse
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/2fa", body: login, anonymous: true}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,297 @@
+// Package client is a typed Go client for the godo API, sharing its request and response types
+// with the server such that the two cannot drift apart.
+//
+//	c, err := client.New("http://localhost:8080", client.WithCredentials(client.Credentials{
+//		Email:    "jane@example.com",
+//		Password: "secret",
+//	}))
+//
+//	project, err := c.CreateProject(ctx, client.NewProject{Name: "Website", Description: "The new website"})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"godo/internal/api"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// How long before it expires a token is replaced, such that it does not expire in flight
+const tokenExpiryMargin = time.Minute
+
+// Client calls the godo API; it is safe for concurrent use
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	credentials *Credentials
+	twoFactor   TwoFactorFunc
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// Credentials the user the client logs in as, again whenever its token expires
+type Credentials struct {
+	Email    string
+	Password string
+
+	// The account to log in to; the user's default account is used if empty
+	AccountId string
+}
+
+// TwoFactorFunc returns the current code from the user's authenticator app when logging in as a
+// user with two-factor authentication enabled
+type TwoFactorFunc func(ctx context.Context) (string, error)
+
+type Option func(c *Client)
+
+// WithHTTPClient sends the requests with the given HTTP client rather than http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken authenticates the requests with the given token, such as one obtained by Login
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.setToken(token)
+	}
+}
+
+// WithCredentials logs in as the given user when first needed and again whenever the token expires
+func WithCredentials(credentials Credentials) Option {
+	return func(c *Client) {
+		c.credentials = &credentials
+	}
+}
+
+// WithTwoFactor completes logging in with the credentials when the user has two-factor authentication enabled
+func WithTwoFactor(f TwoFactorFunc) Option {
+	return func(c *Client) {
+		c.twoFactor = f
+	}
+}
+
+// New creates a client of the API served at baseURL, such as http://localhost:8080
+func New(baseURL string, options ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + api.NewVersion(1).Prefix + "/")
+	if err != nil {
+		return nil, fmt.Errorf("godo: the base URL is not valid: %w", err)
+	}
+
+	c := &Client{baseURL: u, httpClient: http.DefaultClient}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+// Token the token the client currently authenticates with, empty if it has not logged in
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+// request describes a call of the API
+type request struct {
+	method string
+	path   string
+	query  url.Values
+
+	// The body, encoded as JSON unless already encoded as a json.RawMessage
+	body        interface{}
+	contentType string
+
+	// Whether the request is sent without a token, such as when logging in
+	anonymous bool
+}
+
+// do sends the request, decoding the response into out, if not nil
+func (c *Client) do(ctx context.Context, req request, out interface{}) (*http.Response, error) {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(req.path, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.query) > 0 {
+		u.RawQuery = req.query.Encode()
+	}
+
+	var body []byte
+	if req.body != nil {
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("godo: could not encode the request: %w", err)
+		}
+	}
+
+	token := ""
+	if !req.anonymous {
+		if token, err = c.accessToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.roundTrip(ctx, req, u, body, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token may have been revoked, such as by the user changing their password, before it expired
+	if resp.StatusCode == http.StatusUnauthorized && token != "" && c.credentials != nil {
+		resp.Body.Close()
+		c.expire(token)
+
+		if token, err = c.accessToken(ctx); err != nil {
+			return nil, err
+		}
+
+		if resp, err = c.roundTrip(ctx, req, u, body, token); err != nil {
+			return nil, err
+		}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp, decodeError(resp)
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("godo: could not decode the response to %s %s: %w", req.method, u.Path, err)
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, req request, u *url.URL, body []byte, token string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		contentType := req.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// accessToken returns a token which has not expired, logging in with the credentials if need be
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expiresAt.IsZero() || time.Until(c.expiresAt) > tokenExpiryMargin) {
+		return c.token, nil
+	}
+
+	if c.credentials == nil {
+		if c.token != "" {
+			return "", ErrTokenExpired
+		}
+
+		return "", ErrNotAuthenticated
+	}
+
+	token, err := c.logIn(ctx, *c.credentials)
+	if err != nil {
+		return "", err
+	}
+
+	c.setTokenLocked(token)
+	return token, nil
+}
+
+// logIn obtains a token for the credentials, completing two-factor authentication if required
+func (c *Client) logIn(ctx context.Context, credentials Credentials) (string, error) {
+	login, err := c.login(ctx, LoginRequest{
+		Email:     credentials.Email,
+		Password:  credentials.Password,
+		AccountId: credentials.AccountId,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !login.TwoFactorRequired {
+		return login.Token, nil
+	}
+
+	if c.twoFactor == nil {
+		return "", ErrTwoFactorRequired
+	}
+
+	code, err := c.twoFactor(ctx)
+	if err != nil {
+		return "", fmt.Errorf("godo: could not get the two-factor code: %w", err)
+	}
+
+	login, err = c.completeTwoFactorLogin(ctx, TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: code})
+	if err != nil {
+		return "", err
+	}
+
+	return login.Token, nil
+}
+
+// expire discards the token, if still current, such that the next request logs in again
+func (c *Client) expire(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = ""
+		c.expiresAt = time.Time{}
+	}
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setTokenLocked(token)
+}
+
+// setTokenLocked makes the token current, reading when it expires from its claims
+func (c *Client) setTokenLocked(token string) {
+	c.token = token
+	c.expiresAt = time.Time{}
+
+	// The token is verified by the server; the client only needs to know when to replace it
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err == nil && claims.ExpiresAt != nil {
+		c.expiresAt = claims.ExpiresAt.Time
+	}
+}
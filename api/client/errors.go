@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/httperror"
+	"io"
+	"net/http"
+)
+
+// Errors of the client itself
+var (
+	ErrNotAuthenticated  = errors.New("godo: the client has no token; log in or use WithCredentials")
+	ErrTokenExpired      = errors.New("godo: the token has expired; log in again or use WithCredentials")
+	ErrTwoFactorRequired = errors.New("godo: the user must complete two-factor authentication; use WithTwoFactor")
+)
+
+// Errors responded by the API, which an *Error may be compared with by errors.Is
+var (
+	ErrUnauthorized          = ehand.ErrorUnauthorized
+	ErrPermissionDenied      = ehand.ErrorPermissionDenied
+	ErrValidationFailed      = ehand.ErrorValidationFailed
+	ErrInvalidCredentials    = ehand.ErrorUserAuthentication
+	ErrTwoFactorInvalid      = ehand.ErrorTwoFactorInvalidCode
+	ErrProjectNotFound       = ehand.ErrorProjectNotFound
+	ErrProjectMemberNotFound = ehand.ErrorProjectMemberNotFound
+	ErrStoryNotFound         = ehand.ErrorStoryNotFound
+	ErrTaskNotFound          = ehand.ErrorTaskNotFound
+	ErrTagNotFound           = ehand.ErrorTagNotFound
+	ErrTagAlreadyExists      = ehand.ErrorTagAlreadyExists
+)
+
+// Error a problem responded by the API; it wraps the error the server responded with, if known,
+// such that errors.Is(err, client.ErrProjectNotFound) holds
+type Error struct {
+	httperror.Problem
+}
+
+func (e *Error) Error() string {
+	code := e.Code
+	if code == "" {
+		code = e.Title
+	}
+
+	if e.Detail == "" {
+		return fmt.Sprintf("godo: %d %s", e.Status, code)
+	}
+
+	return fmt.Sprintf("godo: %d %s: %s", e.Status, code, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	return ehand.ErrorForCode(e.Code)
+}
+
+// decodeError reads the problem from the response; responses which are not problems, such as
+// those of a proxy, are described by their status
+func decodeError(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("godo: could not read the %d response: %w", resp.StatusCode, err)
+	}
+
+	apiErr := &Error{}
+	if err := json.Unmarshal(body, &apiErr.Problem); err != nil || apiErr.Code == "" {
+		apiErr.Problem = *httperror.New(resp.StatusCode, "", string(body))
+		apiErr.Type = ""
+	}
+
+	return apiErr
+}
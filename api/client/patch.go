@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// The media types of the patches the API accepts
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// PatchOperation an operation of a JSON patch, as described by RFC 6902, such as
+// PatchOperation{Op: "replace", Path: "/name", Value: "Website"}
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// MarshalJSON includes the value, even if null, false or zero, in the operations which take one
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	operation := map[string]interface{}{"op": o.Op, "path": o.Path}
+
+	switch o.Op {
+	case "move", "copy":
+		operation["from"] = o.From
+	case "add", "replace", "test":
+		operation["value"] = o.Value
+	}
+
+	return json.Marshal(operation)
+}
+
+// mergePatch applies a JSON merge patch, as described by RFC 7396, to the resource at path;
+// only the fields present in the patch are changed and a null field is removed
+func mergePatch[T any](ctx context.Context, c *Client, path string, patch interface{}) (*T, error) {
+	var patched T
+	_, err := c.do(ctx, request{method: http.MethodPatch, path: path, body: patch, contentType: mergePatchContentType}, &patched)
+	if err != nil {
+		return nil, err
+	}
+
+	return &patched, nil
+}
+
+// jsonPatch applies the operations of a JSON patch to the resource at path, failing without change
+// unless every operation succeeds
+func jsonPatch[T any](ctx context.Context, c *Client, path string, operations []PatchOperation) (*T, error) {
+	var patched T
+	_, err := c.do(ctx, request{method: http.MethodPatch, path: path, body: operations, contentType: jsonPatchContentType}, &patched)
+	if err != nil {
+		return nil, err
+	}
+
+	return &patched, nil
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ProjectFilter narrows the projects listed
+type ProjectFilter struct {
+	// Only the projects owned by the team
+	TeamId string
+}
+
+// Projects lists the projects visible to the user
+func (c *Client) Projects(ctx context.Context, filter ProjectFilter) ([]*ProjectInfo, error) {
+	query := url.Values{}
+	if filter.TeamId != "" {
+		query.Set("team", filter.TeamId)
+	}
+
+	var projects []*ProjectInfo
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/project", query: query}, &projects)
+	return projects, err
+}
+
+// Project returns the project along with its stories and tags
+func (c *Client) Project(ctx context.Context, projectId string) (*Project, error) {
+	var project Project
+	_, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(projectId)}, &project)
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (c *Client) CreateProject(ctx context.Context, project NewProject) (*Project, error) {
+	var created Project
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/project", body: project}, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// UpdateProject replaces the name, description and status of the project
+func (c *Client) UpdateProject(ctx context.Context, projectId string, project UpdateProject) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: projectPath(projectId), body: project}, nil)
+	return err
+}
+
+// MergePatchProject changes only the fields of the project present in the patch, such as
+// map[string]interface{}{"description": "The new website"}
+func (c *Client) MergePatchProject(ctx context.Context, projectId string, patch interface{}) (*Project, error) {
+	return mergePatch[Project](ctx, c, projectPath(projectId), patch)
+}
+
+// JSONPatchProject applies the operations to the project, described as a ProjectPatch
+func (c *Client) JSONPatchProject(ctx context.Context, projectId string, operations []PatchOperation) (*Project, error) {
+	return jsonPatch[Project](ctx, c, projectPath(projectId), operations)
+}
+
+func (c *Client) DeleteProject(ctx context.Context, projectId string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: projectPath(projectId)}, nil)
+	return err
+}
+
+func (c *Client) UpdateProjectStatus(ctx context.Context, projectId string, status ProjectStatus) error {
+	body := dto.ProjectStatusUpdateDto{Status: status}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: projectPath(projectId) + "/status", body: body}, nil)
+	return err
+}
+
+// UpdateProjectVisibility restricts the project to its members, or opens it to the whole account
+func (c *Client) UpdateProjectVisibility(ctx context.Context, projectId string, restricted bool) error {
+	body := dto.ProjectVisibilityDto{Restricted: restricted}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: projectPath(projectId) + "/visibility", body: body}, nil)
+	return err
+}
+
+// UpdateProjectTeam sets the team owning the project; nil for no team
+func (c *Client) UpdateProjectTeam(ctx context.Context, projectId string, teamId *string) error {
+	body := dto.ProjectTeamDto{TeamId: teamId}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: projectPath(projectId) + "/team", body: body}, nil)
+	return err
+}
+
+// ProjectMembers lists the members of the project and their roles
+func (c *Client) ProjectMembers(ctx context.Context, projectId string) ([]*ProjectMember, error) {
+	var members []*ProjectMember
+	_, err := c.do(ctx, request{method: http.MethodGet, path: projectPath(projectId) + "/member"}, &members)
+	return members, err
+}
+
+// SetProjectMember adds the user to the project, or changes their role if already a member
+func (c *Client) SetProjectMember(ctx context.Context, projectId string, userId uint, role ProjectRole) (*ProjectMember, error) {
+	var member ProjectMember
+	body := dto.ProjectMemberDto{Role: role}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: projectMemberPath(projectId, userId), body: body}, &member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (c *Client) RemoveProjectMember(ctx context.Context, projectId string, userId uint) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: projectMemberPath(projectId, userId)}, nil)
+	return err
+}
+
+func projectPath(projectId string) string {
+	return "/project/" + url.PathEscape(projectId)
+}
+
+func projectMemberPath(projectId string, userId uint) string {
+	return projectPath(projectId) + "/member/" + strconv.FormatUint(uint64(userId), 10)
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Stories lists the stories of the projects visible to the user
+func (c *Client) Stories(ctx context.Context) ([]*StoryInfo, error) {
+	var stories []*StoryInfo
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/story"}, &stories)
+	return stories, err
+}
+
+// Story returns the story along with its tasks
+func (c *Client) Story(ctx context.Context, storyId string) (*Story, error) {
+	var story Story
+	_, err := c.do(ctx, request{method: http.MethodGet, path: storyPath(storyId)}, &story)
+	if err != nil {
+		return nil, err
+	}
+
+	return &story, nil
+}
+
+func (c *Client) CreateStory(ctx context.Context, story NewStory) (*Story, error) {
+	var created Story
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/story", body: story}, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// UpdateStory replaces the name, description and project of the story
+func (c *Client) UpdateStory(ctx context.Context, storyId string, story NewStory) (*Story, error) {
+	var updated Story
+	_, err := c.do(ctx, request{method: http.MethodPut, path: storyPath(storyId), body: story}, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// MergePatchStory changes only the fields of the story present in the patch
+func (c *Client) MergePatchStory(ctx context.Context, storyId string, patch interface{}) (*Story, error) {
+	return mergePatch[Story](ctx, c, storyPath(storyId), patch)
+}
+
+// JSONPatchStory applies the operations to the story, described as a StoryPatch
+func (c *Client) JSONPatchStory(ctx context.Context, storyId string, operations []PatchOperation) (*Story, error) {
+	return jsonPatch[Story](ctx, c, storyPath(storyId), operations)
+}
+
+func (c *Client) DeleteStory(ctx context.Context, storyId string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: storyPath(storyId)}, nil)
+	return err
+}
+
+func storyPath(storyId string) string {
+	return "/story/" + url.PathEscape(storyId)
+}
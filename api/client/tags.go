@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// AddProjectTag creates a tag in the project, which may then be added to its tasks; the project's
+// tags are listed by Project
+func (c *Client) AddProjectTag(ctx context.Context, projectId string, tag NewTag) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: projectPath(projectId) + "/tag", body: tag}, nil)
+	return err
+}
+
+func (c *Client) DeleteProjectTag(ctx context.Context, projectId string, tagId uint) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: projectPath(projectId) + "/tag/" + tagPath(tagId)}, nil)
+	return err
+}
+
+// AddTaskTag adds one of the tags of the task's project to the task
+func (c *Client) AddTaskTag(ctx context.Context, taskId string, tagId uint) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: taskPath(taskId) + "/tag/" + tagPath(tagId)}, nil)
+	return err
+}
+
+func (c *Client) RemoveTaskTag(ctx context.Context, taskId string, tagId uint) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: taskPath(taskId) + "/tag/" + tagPath(tagId)}, nil)
+	return err
+}
+
+func tagPath(tagId uint) string {
+	return strconv.FormatUint(uint64(tagId), 10)
+}
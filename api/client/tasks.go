@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"net/http"
+	"net/url"
+)
+
+// TaskFilter narrows the tasks listed
+type TaskFilter struct {
+	// Only the tasks of the projects owned by the team
+	TeamId string
+}
+
+// Tasks lists the tasks of the projects visible to the user
+func (c *Client) Tasks(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	query := url.Values{}
+	if filter.TeamId != "" {
+		query.Set("team", filter.TeamId)
+	}
+
+	var tasks []*Task
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/task", query: query}, &tasks)
+	return tasks, err
+}
+
+func (c *Client) Task(ctx context.Context, taskId string) (*Task, error) {
+	var task Task
+	_, err := c.do(ctx, request{method: http.MethodGet, path: taskPath(taskId)}, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (c *Client) CreateTask(ctx context.Context, task NewTask) (*Task, error) {
+	var created Task
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/task", body: task}, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (c *Client) UpdateTask(ctx context.Context, taskId string, task UpdateTask) (*Task, error) {
+	var updated Task
+	_, err := c.do(ctx, request{method: http.MethodPut, path: taskPath(taskId), body: task}, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// MergePatchTask changes only the fields of the task present in the patch, such as
+// map[string]interface{}{"assignee_id": nil} to unassign it
+func (c *Client) MergePatchTask(ctx context.Context, taskId string, patch interface{}) (*Task, error) {
+	return mergePatch[Task](ctx, c, taskPath(taskId), patch)
+}
+
+// JSONPatchTask applies the operations to the task, described as a TaskPatch
+func (c *Client) JSONPatchTask(ctx context.Context, taskId string, operations []PatchOperation) (*Task, error) {
+	return jsonPatch[Task](ctx, c, taskPath(taskId), operations)
+}
+
+func (c *Client) UpdateTaskStatus(ctx context.Context, taskId string, status ProgressStatus) (*Task, error) {
+	var updated Task
+	body := dto.UpdateTaskStatusDto{Status: status}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: taskPath(taskId) + "/status", body: body}, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (c *Client) UpdateTaskType(ctx context.Context, taskId string, taskType TaskType) (*Task, error) {
+	var updated Task
+	body := dto.UpdateTaskTypeDto{Type: taskType}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: taskPath(taskId) + "/type", body: body}, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func taskPath(taskId string) string {
+	return "/task/" + url.PathEscape(taskId)
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"godo/internal/api/dto"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+)
+
+// The requests and responses of the API are those of the server, aliased here as its packages are internal
+
+type (
+	LoginRequest          = dto.LoginRequestDto
+	LoginResponse         = dto.LoginResponseDto
+	TwoFactorLoginRequest = dto.TwoFactorLoginRequestDto
+	RegistrationRequest   = dto.RegistrationRequestDto
+	User                  = entities.User
+)
+
+type (
+	Project       = entities.Project
+	ProjectInfo   = entities.ProjectInfo
+	ProjectMember = entities.ProjectMember
+	NewProject    = dto.NewProjectDto
+	UpdateProject = dto.UpdateProjectDto
+	ProjectPatch  = dto.ProjectPatchDto
+	ProjectStatus = enums.ProjectStatus
+	ProjectRole   = enums.ProjectRole
+)
+
+type (
+	Story      = entities.Story
+	StoryInfo  = entities.StoryInfo
+	NewStory   = dto.NewStoryDto
+	StoryPatch = dto.StoryPatchDto
+)
+
+type (
+	Task           = entities.Task
+	NewTask        = dto.NewTaskDto
+	UpdateTask     = dto.UpdateTaskDto
+	TaskPatch      = dto.TaskPatchDto
+	TaskType       = enums.TaskType
+	ProgressStatus = enums.ProgressStatus
+)
+
+type (
+	Tag    = entities.Tag
+	NewTag = dto.NewTagDto
+)
+
+const (
+	ProjectOpen   = enums.Open
+	ProjectClosed = enums.Closed
+
+	ProjectReader      = enums.Reader
+	ProjectContributor = enums.Contributor
+	ProjectMaintainer  = enums.Maintainer
+
+	TaskTypeTask = enums.Task
+	TaskTypeBug  = enums.Bug
+	TaskTypeTest = enums.Test
+
+	StatusNew        = enums.New
+	StatusInProgress = enums.InProgress
+	StatusComplete   = enums.Complete
+)
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"godo/client"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+)
+
+// The server used when neither the flag nor the config file name one
+const defaultServer = "http://localhost:8080"
+
+// config the server and credentials kept between commands
+type config struct {
+	Server    string `json:"server"`
+	Email     string `json:"email,omitempty"`
+	AccountId string `json:"account_id,omitempty"`
+	Token     string `json:"token,omitempty"`
+}
+
+// defaultConfigFile e.g. ~/.config/godo/config.yaml
+func defaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".godo.yaml"
+	}
+
+	return filepath.Join(dir, "godo", "config.yaml")
+}
+
+// loadConfig reads the config file, which does not exist until the first login
+func loadConfig(opts *options) (*config, error) {
+	conf := &config{}
+
+	data, err := os.ReadFile(opts.configFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("%s is not valid: %w", opts.configFile, err)
+	}
+
+	if opts.server != "" {
+		conf.Server = opts.server
+	}
+
+	if conf.Server == "" {
+		conf.Server = defaultServer
+	}
+
+	return conf, nil
+}
+
+// save writes the config file, readable only by the user as it holds their token
+func (c *config) save(opts *options) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.configFile), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(opts.configFile, data, 0600)
+}
+
+// newClient creates a client of the configured server, authenticated by the saved token
+func newClient(opts *options) (*client.Client, error) {
+	conf, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Token == "" {
+		return nil, errors.New("you are not logged in, run `godo login`")
+	}
+
+	return client.New(conf.Server, client.WithToken(conf.Token))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"godo/client"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newLoginCommand(opts *options) *cobra.Command {
+	var email, accountId string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in, saving the token to the config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if email == "" {
+				email = conf.Email
+			}
+
+			if email == "" {
+				if email, err = prompt("Email: "); err != nil {
+					return err
+				}
+			}
+
+			password, err := promptSecret("Password: ")
+			if err != nil {
+				return err
+			}
+
+			c, err := client.New(conf.Server)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			login, err := c.Login(ctx, client.LoginRequest{Email: email, Password: password, AccountId: accountId})
+			if err != nil {
+				return err
+			}
+
+			if login.TwoFactorRequired {
+				code, err := prompt("Two-factor code: ")
+				if err != nil {
+					return err
+				}
+
+				login, err = c.CompleteTwoFactorLogin(ctx, client.TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: code})
+				if err != nil {
+					return err
+				}
+			}
+
+			conf.Email = email
+			conf.AccountId = accountId
+			conf.Token = login.Token
+			if err := conf.save(opts); err != nil {
+				return fmt.Errorf("could not save the token: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s as %s\n", conf.Server, email)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "email", "", "the email address of the user; prompted for if not saved")
+	cmd.Flags().StringVar(&accountId, "account", "", "the account to log in to; the user's default account if omitted")
+
+	return cmd
+}
+
+func newLogoutCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Forget the saved token",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			conf.Token = ""
+			return conf.save(opts)
+		},
+	}
+}
+
+// Shared by the prompts such that input buffered by one is not lost to the next
+var stdin = bufio.NewReader(os.Stdin)
+
+func prompt(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+
+	line, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// promptSecret reads without echoing, when reading from a terminal
+func promptSecret(label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(label)
+	}
+
+	fmt.Fprint(os.Stderr, label)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+
+	return string(secret), err
+}
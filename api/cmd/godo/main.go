@@ -0,0 +1,52 @@
+// Command godo manages projects, stories and tasks from the terminal through the godo API, e.g.
+//
+//	godo login --server http://localhost:8080
+//	godo task add "Fix login" --story <id> --type bug --tag backend
+//	godo task mv <id> --status "In Progress"
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// options the flags shared by every command
+type options struct {
+	configFile string
+	server     string
+	output     string
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:           "godo",
+		Short:         "Manage godo projects, stories and tasks",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&opts.configFile, "config", defaultConfigFile(), "the file the server and credentials are kept in")
+	root.PersistentFlags().StringVar(&opts.server, "server", "", "the URL of the godo server, e.g. http://localhost:8080; saved by login")
+	root.PersistentFlags().StringVarP(&opts.output, "output", "o", outputTable, "the output format: table, json or yaml")
+
+	root.AddCommand(
+		newLoginCommand(opts),
+		newLogoutCommand(opts),
+		newProjectCommand(opts),
+		newStoryCommand(opts),
+		newTaskCommand(opts),
+	)
+
+	return root
+}
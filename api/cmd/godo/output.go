@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+)
+
+// The output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table the rows printed by the table format, the JSON and YAML formats printing the value itself;
+// a table without headers lists the fields of a single value
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// printOutput writes the value in the chosen format
+func printOutput(w io.Writer, opts *options, value interface{}, t *table) error {
+	switch opts.output {
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		if len(t.headers) > 0 {
+			fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		}
+
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+
+		return tw.Flush()
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case outputYAML:
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+		return err
+	}
+
+	return fmt.Errorf("the output format must be %s, %s or %s, not %q", outputTable, outputJSON, outputYAML, opts.output)
+}
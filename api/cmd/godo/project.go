@@ -0,0 +1,51 @@
+package main
+
+import (
+	"godo/client"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newProjectCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "project",
+		Aliases: []string{"projects"},
+		Short:   "Manage projects",
+	}
+
+	cmd.AddCommand(newProjectListCommand(opts))
+	return cmd
+}
+
+func newProjectListCommand(opts *options) *cobra.Command {
+	var teamId string
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the projects visible to you",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+
+			projects, err := c.Projects(cmd.Context(), client.ProjectFilter{TeamId: teamId})
+			if err != nil {
+				return err
+			}
+
+			t := &table{headers: []string{"ID", "NAME", "STATUS", "STORIES", "TAGS"}}
+			for _, p := range projects {
+				t.add(p.ID, p.Name, p.StatusValue, strconv.Itoa(int(p.StoryCount)), strconv.Itoa(int(p.TagCount)))
+			}
+
+			return printOutput(cmd.OutOrStdout(), opts, projects, t)
+		},
+	}
+
+	cmd.Flags().StringVar(&teamId, "team", "", "only the projects owned by the team")
+	return cmd
+}
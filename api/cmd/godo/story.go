@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newStoryCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "story",
+		Aliases: []string{"stories"},
+		Short:   "Manage stories",
+	}
+
+	cmd.AddCommand(newStoryListCommand(opts))
+	return cmd
+}
+
+func newStoryListCommand(opts *options) *cobra.Command {
+	var projectId string
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the stories visible to you, or those of a project",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+
+			t := &table{headers: []string{"ID", "NAME", "STATUS", "TASKS"}}
+
+			// The stories of a project are returned along with it
+			if projectId != "" {
+				project, err := c.Project(cmd.Context(), projectId)
+				if err != nil {
+					return err
+				}
+
+				for _, s := range project.Stories {
+					t.add(s.ID, s.Name, s.StatusValue, strconv.Itoa(len(s.Tasks)))
+				}
+
+				return printOutput(cmd.OutOrStdout(), opts, project.Stories, t)
+			}
+
+			stories, err := c.Stories(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			for _, s := range stories {
+				t.add(s.ID, s.Name, s.StatusValue, strconv.Itoa(int(s.TaskCount)))
+			}
+
+			return printOutput(cmd.OutOrStdout(), opts, stories, t)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectId, "project", "", "only the stories of the project")
+	return cmd
+}
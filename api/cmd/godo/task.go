@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"godo/client"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newTaskCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "task",
+		Aliases: []string{"tasks"},
+		Short:   "Manage tasks",
+	}
+
+	cmd.AddCommand(
+		newTaskListCommand(opts),
+		newTaskAddCommand(opts),
+		newTaskMoveCommand(opts),
+		newTaskShowCommand(opts),
+	)
+
+	return cmd
+}
+
+func newTaskListCommand(opts *options) *cobra.Command {
+	var teamId string
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the tasks visible to you",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+
+			tasks, err := c.Tasks(cmd.Context(), client.TaskFilter{TeamId: teamId})
+			if err != nil {
+				return err
+			}
+
+			t := &table{headers: []string{"ID", "NAME", "TYPE", "STATUS", "TAGS"}}
+			for _, task := range tasks {
+				t.add(task.ID, task.Name, task.TypeValue, task.StatusValue, tagNames(task.Tags))
+			}
+
+			return printOutput(cmd.OutOrStdout(), opts, tasks, t)
+		},
+	}
+
+	cmd.Flags().StringVar(&teamId, "team", "", "only the tasks of the projects owned by the team")
+	return cmd
+}
+
+func newTaskAddCommand(opts *options) *cobra.Command {
+	var storyId, description, taskType string
+	var tags []string
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a task to a story",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parsedType, err := parseEnum[client.TaskType]("type", taskType)
+			if err != nil {
+				return err
+			}
+
+			c, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			// The tags are those of the story's project, found before creating the task such that a
+			// misspelt tag does not leave the task half made
+			var tagIds []uint
+			if len(tags) > 0 {
+				story, err := c.Story(ctx, storyId)
+				if err != nil {
+					return err
+				}
+
+				project, err := c.Project(ctx, story.ProjectId)
+				if err != nil {
+					return err
+				}
+
+				if tagIds, err = findTags(project, tags); err != nil {
+					return err
+				}
+			}
+
+			task, err := c.CreateTask(ctx, client.NewTask{
+				Name:        args[0],
+				Description: description,
+				Type:        parsedType,
+				StoryId:     storyId,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, tagId := range tagIds {
+				if err := c.AddTaskTag(ctx, task.ID, tagId); err != nil {
+					return fmt.Errorf("created task %s but could not tag it: %w", task.ID, err)
+				}
+			}
+
+			if len(tagIds) > 0 {
+				if task, err = c.Task(ctx, task.ID); err != nil {
+					return err
+				}
+			}
+
+			return printOutput(cmd.OutOrStdout(), opts, task, taskTable(task))
+		},
+	}
+
+	cmd.Flags().StringVar(&storyId, "story", "", "the story the task belongs to")
+	cmd.Flags().StringVar(&description, "description", "", "the description of the task")
+	cmd.Flags().StringVar(&taskType, "type", client.TaskTypeTask.String(), "the type of task: task, bug or test")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "a tag of the story's project to add to the task; may be repeated")
+	_ = cmd.MarkFlagRequired("story")
+
+	return cmd
+}
+
+func newTaskMoveCommand(opts *options) *cobra.Command {
+	var status string
+
+	cmd := &cobra.Command{
+		Use:   "mv <id>",
+		Short: "Move a task to another status",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parsedStatus, err := parseEnum[client.ProgressStatus]("status", status)
+			if err != nil {
+				return err
+			}
+
+			c, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+
+			task, err := c.UpdateTaskStatus(cmd.Context(), args[0], parsedStatus)
+			if err != nil {
+				return err
+			}
+
+			return printOutput(cmd.OutOrStdout(), opts, task, taskTable(task))
+		},
+	}
+
+	cmd.Flags().StringVar(&status, "status", "", `the status of the task: "New", "In Progress" or "Complete"`)
+	_ = cmd.MarkFlagRequired("status")
+
+	return cmd
+}
+
+func newTaskShowCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a task",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+
+			task, err := c.Task(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			t := &table{}
+			t.add("ID:", task.ID)
+			t.add("Name:", task.Name)
+			t.add("Description:", task.Description)
+			t.add("Type:", task.TypeValue)
+			t.add("Status:", task.StatusValue)
+			t.add("Story:", task.StoryId)
+			t.add("Creator:", task.Creator.Name)
+			t.add("Assignee:", assignee(task.AssigneeId))
+			t.add("Tags:", tagNames(task.Tags))
+
+			return printOutput(cmd.OutOrStdout(), opts, task, t)
+		},
+	}
+}
+
+func taskTable(task *client.Task) *table {
+	t := &table{headers: []string{"ID", "NAME", "TYPE", "STATUS", "TAGS"}}
+	t.add(task.ID, task.Name, task.TypeValue, task.StatusValue, tagNames(task.Tags))
+	return t
+}
+
+// findTags the IDs of the project's tags with the given names
+func findTags(project *client.Project, names []string) ([]uint, error) {
+	var ids []uint
+	for _, name := range names {
+		found := false
+		for _, tag := range project.Tags {
+			if strings.EqualFold(tag.Name, name) {
+				ids = append(ids, tag.ID)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("project %s has no tag %q; its tags are: %s", project.Name, name, tagNames(project.Tags))
+		}
+	}
+
+	return ids, nil
+}
+
+func tagNames(tags []client.Tag) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func assignee(userId *uint) string {
+	if userId == nil {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(*userId), 10)
+}
+
+// enum a type and status of the API, which are named by their String method
+type enum interface {
+	~uint8
+	String() string
+	IsValid() bool
+}
+
+// parseEnum finds the value with the name, ignoring case, spaces, dashes and underscores such
+// that "in-progress" names In Progress
+func parseEnum[T enum](kind, name string) (T, error) {
+	normalise := strings.NewReplacer(" ", "", "-", "", "_", "")
+	wanted := strings.ToLower(normalise.Replace(name))
+
+	var names []string
+	for value := T(0); value.IsValid(); value++ {
+		if strings.ToLower(normalise.Replace(value.String())) == wanted {
+			return value, nil
+		}
+
+		names = append(names, strconv.Quote(value.String()))
+	}
+
+	return 0, fmt.Errorf("the %s must be one of %s, not %q", kind, strings.Join(names, ", "), name)
+}
@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"godo/internal/helper/ilog"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,7 +14,45 @@ type Config struct {
 	DatabaseUsername string `mapstructure:"DB_UNAME"`
 	DatabasePassword string `mapstructure:"DB_PASSWORD"`
 	ApiPort          string `mapstructure:"API_PORT"`
-	JWTKey           string `mapstructure:"JWT_KEY"`
+
+	// JWTKey the legacy HMAC secret; when set, tokens signed with it are still accepted until
+	// JWTKeyGracePeriod has passed since the first key of the key set replaced it
+	JWTKey            string        `mapstructure:"JWT_KEY"`
+	JWTAlgorithm      string        `mapstructure:"JWT_ALGORITHM"`
+	JWTKeyGracePeriod time.Duration `mapstructure:"JWT_KEY_GRACE_PERIOD"`
+
+	// WebUIURL the base URL of the web UI, used for links in emails
+	WebUIURL     string `mapstructure:"WEB_UI_URL"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+
+	// AccountDeletionGracePeriod how long after deletion is requested an account is erased
+	AccountDeletionGracePeriod time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+
+	// LegacyApiSunset the date, e.g. 2006-01-02, after which the deprecated unversioned /api prefix
+	// is no longer served, advertised in the Sunset header; empty when not yet decided
+	LegacyApiSunset string `mapstructure:"LEGACY_API_SUNSET"`
+
+	// OpenApiValidation whether requests are validated against the API document: off, requests or,
+	// in development, responses, which validates the responses too
+	OpenApiValidation string `mapstructure:"OPENAPI_VALIDATION"`
+
+	// GraphQLMaxDepth and GraphQLMaxComplexity limit the queries of the GraphQL API; a complexity of
+	// 0 is not limited. The depth must allow for the introspection query of GraphQL tools, 13 deep.
+	GraphQLMaxDepth      int `mapstructure:"GRAPHQL_MAX_DEPTH"`
+	GraphQLMaxComplexity int `mapstructure:"GRAPHQL_MAX_COMPLEXITY"`
+
+	// GrpcPort the port of the gRPC API, such as 9090; it is only served when one is set
+	GrpcPort string `mapstructure:"GRPC_PORT"`
+
+	// GrpcReflection whether the gRPC reflection service is served, for debugging with tools such as grpcurl
+	GrpcReflection bool `mapstructure:"GRPC_REFLECTION"`
+
+	// GrpcWatchInterval how often a watched project is checked for changes
+	GrpcWatchInterval time.Duration `mapstructure:"GRPC_WATCH_INTERVAL"`
 }
 
 func LoadDevConfig(logger ilog.StdLogger) (conf Config) {
@@ -32,6 +71,23 @@ func makeConfig(configName string, log ilog.StdLogger) (conf Config) {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("JWT_ALGORITHM", "RS256")
+	viper.SetDefault("JWT_KEY_GRACE_PERIOD", "24h")
+	viper.SetDefault("WEB_UI_URL", "http://localhost:4200")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("MAIL_FROM", "godo@localhost")
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_PERIOD", "720h")
+	viper.SetDefault("LEGACY_API_SUNSET", "")
+	viper.SetDefault("OPENAPI_VALIDATION", "requests")
+	viper.SetDefault("GRAPHQL_MAX_DEPTH", 15)
+	viper.SetDefault("GRAPHQL_MAX_COMPLEXITY", 5000)
+	viper.SetDefault("GRPC_PORT", "")
+	viper.SetDefault("GRPC_REFLECTION", false)
+	viper.SetDefault("GRPC_WATCH_INTERVAL", "5s")
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		log.Errorln(err)
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	ehand "godo/internal/api/errorhandler"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/golang/gddo/httputil/header"
+)
+
+// The size of the largest request body read
+const maxBodySize = 1048576
+
+// ReadBody reads the request body as JSON, transcoding it from the format of its Content-Type, which is
+// taken to be JSON when not given, returning a request error describing why the body cannot be read
+func ReadBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	format := FormatOf(JSONFormat.MediaType)
+	if r.Header.Get("Content-Type") != "" {
+		value, _ := header.ParseValueAndParams(r.Header, "Content-Type")
+		if format = FormatOf(value); format == nil || format.Decode == nil {
+			msg := fmt.Errorf("Content-Type header must be one of %s", strings.Join(DecodableMediaTypes(), ", "))
+			return nil, ehand.NewRequestError(http.StatusUnsupportedMediaType, ehand.CodeUnsupportedMediaType, msg)
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		errMsg := errors.New("request body must not be larger than 1MB")
+		return nil, ehand.NewRequestError(http.StatusRequestEntityTooLarge, ehand.CodeBodyTooLarge, errMsg)
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		errMsg := errors.New("request body must not be empty")
+		return nil, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeEmptyBody, errMsg)
+	}
+
+	data, err := format.Decode(body)
+	if err != nil {
+		errMsg := fmt.Errorf("request body contains badly-formed %s: %s", format.MediaType, err)
+		return nil, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedBody, errMsg)
+	}
+
+	return data, nil
+}
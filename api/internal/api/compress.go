@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/golang/gddo/httputil/header"
+)
+
+// The size of the smallest body compressed, below which compression saves too little to be worth it
+const minCompressedSize = 1024
+
+// contentCoding a content coding responses may be compressed with
+type contentCoding struct {
+	name   string
+	writer func(w io.Writer) io.WriteCloser
+}
+
+// The content codings, by order of preference when the client prefers neither
+var contentCodings = []contentCoding{
+	{"br", func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotli.DefaultCompression) }},
+	{"gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+}
+
+// Compress compresses the body with the content coding the client most prefers of those it accepts by the
+// Accept-Encoding header of its request, returning the name of the coding; small bodies, and those of
+// clients accepting no coding, are returned as they are with no coding
+func Compress(r *http.Request, body []byte) (string, []byte, error) {
+	if len(body) < minCompressedSize {
+		return "", body, nil
+	}
+
+	coding := chooseCoding(header.ParseAccept(r.Header, "Accept-Encoding"))
+	if coding == nil {
+		return "", body, nil
+	}
+
+	var buf bytes.Buffer
+	cw := coding.writer(&buf)
+	if _, err := cw.Write(body); err != nil {
+		return "", nil, err
+	}
+
+	if err := cw.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return coding.name, buf.Bytes(), nil
+}
+
+// chooseCoding the content coding with the highest quality given by the client, that of the coding itself
+// or else that of any coding
+func chooseCoding(accept []header.AcceptSpec) *contentCoding {
+	var chosen *contentCoding
+	var chosenQ float64
+
+	for i, coding := range contentCodings {
+		q, specified := 0.0, false
+		for _, spec := range accept {
+			if strings.EqualFold(spec.Value, coding.name) {
+				q, specified = spec.Q, true
+			} else if spec.Value == "*" && !specified {
+				q = spec.Q
+			}
+		}
+
+		if q > chosenQ {
+			chosen, chosenQ = &contentCodings[i], q
+		}
+	}
+
+	return chosen
+}
+
+// EncodedETag the entity tag of the representation once compressed with the content coding, which is a
+// different representation, so may not be given the same strong tag; see RFC 9110 section 8.8.3.3
+func EncodedETag(etag, coding string) string {
+	return strings.TrimSuffix(etag, `"`) + "-" + coding + `"`
+}
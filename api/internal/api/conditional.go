@@ -0,0 +1,159 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"godo/internal/repository/entities"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Validators identify the representation of a resource, such that clients polling it may ask for it
+// only when it has changed; see RFC 9110 section 8.8. The ETag is computed from the versions of the
+// entities in the response and the Last-Modified time is that of the most recently updated.
+type Validators struct {
+	hash         hash.Hash
+	lastModified time.Time
+}
+
+func NewValidators() *Validators {
+	return &Validators{hash: sha256.New()}
+}
+
+// Version adds the entity, identified by its type and id, at its version
+func (v *Validators) Version(kind, id string, version uint, updatedAt time.Time) *Validators {
+	fmt.Fprintf(v.hash, "%s:%s:%d;", kind, id, version)
+	return v.Modified(updatedAt)
+}
+
+// Value adds the entity, which has no version, by the values it is represented by
+func (v *Validators) Value(kind string, values ...interface{}) *Validators {
+	fmt.Fprintf(v.hash, "%s:%v;", kind, values)
+	return v
+}
+
+// Modified moves the Last-Modified time forward to the given time, if after it
+func (v *Validators) Modified(t time.Time) *Validators {
+	if t.After(v.lastModified) {
+		v.lastModified = t
+	}
+
+	return v
+}
+
+// ETag the strong entity tag of the representation in the media type; each format of the same entities is
+// a different representation of them, so has a different tag
+func (v *Validators) ETag(mediaType string) string {
+	sum := sha256.Sum256(append(v.hash.Sum(nil), mediaType...))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// LastModified the time the representation was last modified, or zero if unknown
+func (v *Validators) LastModified() time.Time {
+	return v.lastModified
+}
+
+// ProjectValidators the validators of the project, with the stories, tasks and tags loaded with it
+func ProjectValidators(project *entities.Project) *Validators {
+	v := NewValidators().Version("project", project.ID, project.Version, project.UpdatedAt)
+	addCreator(v, project.Creator)
+
+	for i := range project.Stories {
+		addStory(v, &project.Stories[i])
+	}
+
+	for _, tag := range project.Tags {
+		v.Value("tag", tag.ID, tag.Name)
+	}
+
+	return v
+}
+
+// StoryValidators the validators of the story, with the tasks loaded with it
+func StoryValidators(story *entities.Story) *Validators {
+	v := NewValidators()
+	addStory(v, story)
+
+	return v
+}
+
+// TaskValidators the validators of the tasks, with the tags loaded with them
+func TaskValidators(tasks ...*entities.Task) *Validators {
+	v := NewValidators()
+	for _, task := range tasks {
+		addTask(v, task)
+	}
+
+	return v
+}
+
+func addStory(v *Validators, story *entities.Story) {
+	v.Version("story", story.ID, story.Version, story.UpdatedAt)
+	addCreator(v, story.Creator)
+
+	for i := range story.Tasks {
+		addTask(v, &story.Tasks[i])
+	}
+}
+
+func addTask(v *Validators, task *entities.Task) {
+	v.Version("task", task.ID, task.Version, task.UpdatedAt)
+	addCreator(v, task.Creator)
+
+	for _, tag := range task.Tags {
+		v.Value("tag", tag.ID, tag.Name)
+	}
+}
+
+// addCreator adds the creator, if loaded; a change to the user changes the representation of the
+// entities they created without changing the entities themselves, so is not a modification of them
+func addCreator(v *Validators, creator entities.User) {
+	if creator.ID != 0 {
+		v.Value("user", creator.ID, creator.UpdatedAt.UnixNano())
+	}
+}
+
+// BodyETag the strong entity tag of a response for which no validators were computed, from its body
+func BodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified whether the conditional GET request may be answered with 304 Not Modified, its
+// representation being that with the ETag and Last-Modified headers of the response. If-Modified-Since
+// is evaluated only without If-None-Match, as it cannot see two changes made within the same second;
+// changes which leave no timestamp of their own, such as the deletion of a story from a project, update
+// that of the entity holding them.
+func NotModified(r *http.Request, header http.Header) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		etag := header.Get("ETag")
+		return etag != "" && matchesETag(match, etag)
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(since)
+}
+
+// matchesETag whether the If-None-Match list matches the entity tag, by the weak comparison
+func matchesETag(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,16 +1,56 @@
 package dto
 
-import "github.com/go-playground/validator"
+import (
+	"godo/internal/helper/validate"
+	"godo/internal/repository/enums"
+)
 
 type NewAccountDto struct {
-	Name         string `json:"name" validation:"required"`
-	UserName     string `json:"user_name" validation:"required"`
-	UserUsername string `json:"user_username" validation:"required"`
-	UserEmail    string `json:"user_email" validation:"required"`
+	Name         string `json:"name" validate:"required"`
+	UserName     string `json:"user_name" validate:"required,max=25"`
+	UserUsername string `json:"user_username" validate:"required,excludes=#"`
+	UserEmail    string `json:"user_email" validate:"required,email"`
 	Password     string `json:"password" validate:"required"`
 }
 
 func (na *NewAccountDto) Validate() error {
-	validate := validator.New()
 	return validate.Struct(na)
 }
+
+// UpdateAccountDto model for updating the authenticated account; omitted fields are left unchanged
+// swagger:model updateAccountDto
+type UpdateAccountDto struct {
+	// the name of the account
+	//
+	// required: false
+	Name *string `json:"name" validate:"omitempty,min=1"`
+
+	// the contact email address of the account
+	//
+	// required: false
+	Email *string `json:"email" validate:"omitempty,email"`
+
+	// the IANA time zone used by default for the account's users
+	//
+	// required: false
+	// example: Europe/London
+	TimeZone *string `json:"time_zone"`
+}
+
+// TwoFactorRequirementDto model for requiring two-factor authentication across an account
+// swagger:model twoFactorRequirementDto
+type TwoFactorRequirementDto struct {
+	// whether every user in the account must enroll in two-factor authentication
+	Required bool `json:"required"`
+}
+
+// RoleUpdateDto model for changing the role of a user within the account
+// swagger:model roleUpdateDto
+type RoleUpdateDto struct {
+	// numeric representation of the role; 0 member, 1 viewer, 2 admin, 3 owner
+	//
+	// required: true
+	// min: 0
+	// max: 3
+	Role enums.AccountRole `json:"role" validate:"enum"`
+}
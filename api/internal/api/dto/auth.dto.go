@@ -10,31 +10,66 @@ type LoginRequestDto struct {
 	// the user's password
 	// required: true
 	Password string `json:"password" validate:"required"`
+
+	// the account to log in to; the user's default account is used if omitted
+	// required: false
+	AccountId string `json:"account_id" validate:"omitempty,uuid"`
 }
 
 // LoginResponseDto model for returning a JWT
 // swagger:model JWTTokenResponse
 type LoginResponseDto struct {
-	// the token authenticating the user
-	Token string `json:"token"`
+	// the token authenticating the user, empty when a second factor is required
+	Token string `json:"token,omitempty"`
+
+	// set when the user must complete two-factor authentication to obtain a token
+	TwoFactorRequired bool `json:"two_factor_required"`
+
+	// the short-lived token to be exchanged along with a second factor at /auth/2fa
+	ChallengeToken string `json:"challenge_token,omitempty"`
 }
 
-// RegistrationRequestDto model for registering a new user
+// TwoFactorLoginRequestDto model for completing a login with a second factor
+// swagger:model twoFactorLoginRequestDto
+type TwoFactorLoginRequestDto struct {
+	// the challenge token returned from the login request
+	// required: true
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+
+	// the code from the user's authenticator app, or one of their recovery codes
+	// required: true
+	Code string `json:"code" validate:"required"`
+}
+
+// RegistrationRequestDto model for registering a new user from an invitation
 // swagger:model registrationRequestDto
 type RegistrationRequestDto struct {
 	// required: true
 	Name string `json:"name" validate:"required"`
 
-	// required: true
-	Email string `json:"email" validate:"required"`
-
 	// required: true
 	Username string `json:"username" validate:"required"`
 
 	// required: true
 	Password string `json:"password" validate:"required"`
 
-	// the account to which the user is to be associated
+	// the token from the invitation email, which determines the user's email address, account and role
+	// required: true
+	InvitationToken string `json:"invitation_token" validate:"required"`
+}
+
+// SwitchAccountDto model for switching the account the authenticated user is acting within
+// swagger:model switchAccountDto
+type SwitchAccountDto struct {
+	// the ID of an account the user is a member of
+	// required: true
+	AccountId string `json:"account_id" validate:"required,uuid"`
+}
+
+// JoinAccountDto model for an existing user accepting an invitation to join another account
+// swagger:model joinAccountDto
+type JoinAccountDto struct {
+	// the token from the invitation email
 	// required: true
-	AccountId string `json:"account_id" validate:"required"`
+	InvitationToken string `json:"invitation_token" validate:"required"`
 }
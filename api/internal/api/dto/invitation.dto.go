@@ -0,0 +1,19 @@
+package dto
+
+import "godo/internal/repository/enums"
+
+// NewInvitationDto model for inviting a user to the account
+// swagger:model newInvitationDto
+type NewInvitationDto struct {
+	// the email address the invitation is sent to
+	//
+	// required: true
+	Email string `json:"email" validate:"required,email"`
+
+	// numeric representation of the role the user is given on joining
+	//
+	// required: false
+	// min: 0
+	// max: 2
+	Role enums.AccountRole `json:"role" validate:"enum"`
+}
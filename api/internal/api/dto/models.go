@@ -0,0 +1,42 @@
+package dto
+
+import "godo/internal/helper/validate"
+
+// Every model decoded from a request body and validated is checked for misspelt struct tags and unknown
+// validation rules when the API starts
+func init() {
+	validate.Register(
+		NewAccountDto{},
+		UpdateAccountDto{},
+		TwoFactorRequirementDto{},
+		RoleUpdateDto{},
+		LoginRequestDto{},
+		TwoFactorLoginRequestDto{},
+		RegistrationRequestDto{},
+		SwitchAccountDto{},
+		JoinAccountDto{},
+		NewInvitationDto{},
+		NewProjectDto{},
+		UpdateProjectDto{},
+		ProjectPatchDto{},
+		ProjectStatusUpdateDto{},
+		ProjectVisibilityDto{},
+		ProjectTeamDto{},
+		ProjectMemberDto{},
+		NewStoryDto{},
+		StoryPatchDto{},
+		NewTagDto{},
+		NewTaskDto{},
+		UpdateTaskDto{},
+		TaskPatchDto{},
+		UpdateTaskStatusDto{},
+		UpdateTaskTypeDto{},
+		NewTeamDto{},
+		UpdateTeamDto{},
+		TwoFactorCodeDto{},
+		UpdateProfileDto{},
+		ChangePasswordDto{},
+		OffboardUserDto{},
+		EraseMeDto{},
+	)
+}
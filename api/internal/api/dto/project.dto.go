@@ -5,21 +5,26 @@ import (
 )
 
 // NewProjectDto model for creating a new project
-// swagger:model newTagDto
+// swagger:model newProjectDto
 type NewProjectDto struct {
 	// the name of the project
 	//
 	// required: true
-	Name string `json:"name" validation:"required"`
+	Name string `json:"name" validate:"required,min=1,max=40"`
 
 	// the description of the project
 	//
 	// required: true
-	Description string `json:"description" validation:"required"`
+	Description string `json:"description" validate:"required,max=280"`
+
+	// whether the project is only visible to its members
+	//
+	// required: false
+	Restricted bool `json:"restricted"`
 }
 
 // UpdateProjectDto model for updating the project
-// swagger:model newTagDto
+// swagger:model updateProjectDto
 type UpdateProjectDto struct {
 	// the name of the project
 	//
@@ -31,19 +36,66 @@ type UpdateProjectDto struct {
 	// required: false
 	Description string `json:"description" validate:"max=280"`
 
-	// numeric representation of the project status
+	// numeric representation of the project status; 0 open, 1 closed
 	//
 	// required: false
 	// min: 0
-	Status enums.ProjectStatus `json:"status" validate:"gte=0"`
+	// max: 1
+	Status enums.ProjectStatus `json:"status" validate:"enum"`
+}
+
+// ProjectPatchDto the document a merge patch or JSON patch of a project is applied to
+// swagger:model projectPatchDto
+type ProjectPatchDto struct {
+	// the name of the project
+	Name string `json:"name" validate:"required,min=1,max=40"`
+
+	// the description of the project
+	Description string `json:"description" validate:"max=280"`
+
+	// numeric representation of the project status; 0 open, 1 closed
+	//
+	// min: 0
+	// max: 1
+	Status enums.ProjectStatus `json:"status" validate:"enum"`
 }
 
 // ProjectStatusUpdateDto model for updating the status of the project
-// swagger:model newTagDto
+// swagger:model projectStatusUpdateDto
 type ProjectStatusUpdateDto struct {
-	// numeric representation of the project status
+	// numeric representation of the project status; 0 open, 1 closed
 	//
-	// required: false
+	// required: true
+	// min: 0
+	// max: 1
+	Status enums.ProjectStatus `json:"status" validate:"enum"`
+}
+
+// ProjectVisibilityDto model for restricting the project to its members
+// swagger:model projectVisibilityDto
+type ProjectVisibilityDto struct {
+	// whether the project is only visible to its members
+	//
+	// required: true
+	Restricted bool `json:"restricted"`
+}
+
+// ProjectTeamDto model for setting the team owning the project
+// swagger:model projectTeamDto
+type ProjectTeamDto struct {
+	// the ID of the team owning the project, or null for no team
+	//
+	// required: true
+	TeamId *string `json:"team_id" validate:"omitempty,uuid"`
+}
+
+// ProjectMemberDto model for setting the role of a member of the project
+// swagger:model projectMemberDto
+type ProjectMemberDto struct {
+	// numeric representation of the role; 0 reader, 1 contributor, 2 maintainer
+	//
+	// required: true
 	// min: 0
-	Status enums.ProjectStatus `json:"status" validate:"required,gte=0"`
+	// max: 2
+	Role enums.ProjectRole `json:"role" validate:"enum"`
 }
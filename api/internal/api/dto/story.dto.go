@@ -1,8 +1,6 @@
 package dto
 
-import (
-	"github.com/go-playground/validator"
-)
+import "godo/internal/helper/validate"
 
 type NewStoryDto struct {
 	Name        string `json:"name" validate:"required"`
@@ -11,6 +9,18 @@ type NewStoryDto struct {
 }
 
 func (s *NewStoryDto) Validate() error {
-	validate := validator.New()
 	return validate.Struct(s)
 }
+
+// StoryPatchDto the document a merge patch or JSON patch of a story is applied to
+// swagger:model storyPatchDto
+type StoryPatchDto struct {
+	// the name of the story
+	Name string `json:"name" validate:"required,min=1,max=40"`
+
+	// the description of the story
+	Description string `json:"description" validate:"max=280"`
+
+	// the ID of the project the story belongs to
+	ProjectId string `json:"project_id" validate:"required,uuid"`
+}
@@ -7,23 +7,47 @@ import (
 type NewTaskDto struct {
 	Name        string               `json:"name" validate:"required,min=1,max=40"`
 	Description string               `json:"description"`
-	Type        enums.TaskType       `json:"type"`
-	Status      enums.ProgressStatus `json:"status"`
+	Type        enums.TaskType       `json:"type" validate:"enum"`
+	Status      enums.ProgressStatus `json:"status" validate:"enum"`
 	StoryId     string               `json:"story_id" validate:"required,uuid"`
+	AssigneeId  *uint                `json:"assignee_id"`
 }
 
 type UpdateTaskDto struct {
 	Name        string               `json:"name" validate:"min=1,max=40"`
 	Description string               `json:"description"`
-	Type        enums.TaskType       `json:"type"`
-	Status      enums.ProgressStatus `json:"status"`
+	Type        enums.TaskType       `json:"type" validate:"enum"`
+	Status      enums.ProgressStatus `json:"status" validate:"enum"`
 	StoryId     string               `json:"story_id"`
+	AssigneeId  *uint                `json:"assignee_id"`
 }
 
 type UpdateTaskStatusDto struct {
-	Status enums.ProgressStatus `json:"status"`
+	Status enums.ProgressStatus `json:"status" validate:"enum"`
 }
 
 type UpdateTaskTypeDto struct {
-	Type enums.TaskType `json:"type"`
+	Type enums.TaskType `json:"type" validate:"enum"`
+}
+
+// TaskPatchDto the document a merge patch or JSON patch of a task is applied to
+// swagger:model taskPatchDto
+type TaskPatchDto struct {
+	// the name of the task
+	Name string `json:"name" validate:"required,min=1,max=40"`
+
+	// the description of the task
+	Description string `json:"description" validate:"max=280"`
+
+	// numeric representation of the task type
+	Type enums.TaskType `json:"type" validate:"enum"`
+
+	// numeric representation of the task status
+	Status enums.ProgressStatus `json:"status" validate:"enum"`
+
+	// the ID of the story the task belongs to
+	StoryId string `json:"story_id" validate:"required,uuid"`
+
+	// the ID of the user the task is assigned to, or null for no assignee
+	AssigneeId *uint `json:"assignee_id"`
 }
@@ -0,0 +1,29 @@
+package dto
+
+// NewTeamDto model for creating a team
+// swagger:model newTeamDto
+type NewTeamDto struct {
+	// the name of the team
+	//
+	// required: true
+	Name string `json:"name" validate:"required,min=1,max=40"`
+
+	// the ID of the user leading the team, who becomes its first member
+	//
+	// required: false
+	LeadId *uint `json:"lead_id"`
+}
+
+// UpdateTeamDto model for updating a team; omitted fields are left unchanged
+// swagger:model updateTeamDto
+type UpdateTeamDto struct {
+	// the name of the team
+	//
+	// required: false
+	Name *string `json:"name" validate:"omitempty,min=1,max=40"`
+
+	// the ID of the user leading the team, who is added to the team if they are not a member
+	//
+	// required: false
+	LeadId *uint `json:"lead_id"`
+}
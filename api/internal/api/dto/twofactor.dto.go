@@ -0,0 +1,29 @@
+package dto
+
+// TwoFactorEnrollmentDto model detailing the secret to be added to an authenticator app
+// swagger:model twoFactorEnrollmentDto
+type TwoFactorEnrollmentDto struct {
+	// the base32 encoded TOTP secret, for manual entry
+	Secret string `json:"secret"`
+
+	// the otpauth:// URI understood by authenticator apps
+	ProvisioningURI string `json:"provisioning_uri"`
+
+	// the provisioning URI rendered as a PNG data URI, for scanning
+	QRCode string `json:"qr_code"`
+}
+
+// TwoFactorCodeDto model for providing a two-factor authentication code
+// swagger:model twoFactorCodeDto
+type TwoFactorCodeDto struct {
+	// the code from the user's authenticator app
+	// required: true
+	Code string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesDto model returning the recovery codes generated on enrollment
+// swagger:model recoveryCodesDto
+type RecoveryCodesDto struct {
+	// single-use codes which may be used in place of an authenticator app code
+	RecoveryCodes []string `json:"recovery_codes"`
+}
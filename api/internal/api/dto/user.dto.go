@@ -0,0 +1,48 @@
+package dto
+
+// UpdateProfileDto model for updating the authenticated user's profile; omitted fields are left unchanged
+// swagger:model updateProfileDto
+type UpdateProfileDto struct {
+	// the name of the user
+	//
+	// required: false
+	Name *string `json:"name" validate:"omitempty,min=1,max=25"`
+
+	// the username of the user; changing it issues a new discriminator
+	//
+	// required: false
+	Username *string `json:"username" validate:"omitempty,min=1,excludes=#"`
+}
+
+// ChangePasswordDto model for changing the authenticated user's password
+// swagger:model changePasswordDto
+type ChangePasswordDto struct {
+	// the user's current password
+	//
+	// required: true
+	CurrentPassword string `json:"current_password" validate:"required"`
+
+	// the password replacing the current password
+	//
+	// required: true
+	// min length: 8
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// OffboardUserDto model for offboarding a user who is leaving the account
+// swagger:model offboardUserDto
+type OffboardUserDto struct {
+	// the ID of the user who takes over the leaving user's open tasks and projects
+	//
+	// required: true
+	SuccessorId uint `json:"successor_id" validate:"required"`
+}
+
+// EraseMeDto model for confirming the erasure of the authenticated user's personal data
+// swagger:model eraseMeDto
+type EraseMeDto struct {
+	// the user's password
+	//
+	// required: true
+	Password string `json:"password" validate:"required"`
+}
@@ -1,37 +1,140 @@
 package errorhandler
 
 import (
-	"fmt"
-	"godo/internal/api"
+	"errors"
+	"godo/internal/api/httperror"
+	"godo/internal/helper/ilog"
 	"net/http"
+
+	"github.com/go-playground/validator"
+)
+
+// problemType the status and stable code of the problem responses for an error
+type problemType struct {
+	status int
+	code   string
+}
+
+var (
+	errorMap = makeErrorMap()
+	logger   = ilog.MakeLoggerWithTag("ErrorHandler")
 )
 
 type ErrorHandler struct {
-	em map[error]int
+	log ilog.StdLogger
+	em  map[error]problemType
 }
 
 func New() ErrorHandler {
 	return ErrorHandler{
-		em: makeErrorMap(),
+		log: logger,
+		em:  errorMap,
 	}
 }
 
-func (e ErrorHandler) GetStatus(err error) (int, error) {
-	status, prs := e.em[err]
-	if !prs {
-		return -1, fmt.Errorf("the given error does not exist in the error map")
-	}
+// The codes of problems with the request itself
+const (
+	CodeMalformedJSON        = "malformed_json"
+	CodeMalformedBody        = "malformed_body"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeInvalidFieldType     = "invalid_field_type"
+	CodeUnknownField         = "unknown_field"
+	CodeEmptyBody            = "empty_body"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeInvalidPatch         = "invalid_patch"
+	CodePatchTestFailed      = "patch_test_failed"
+	CodeRequestNotValid      = "request_not_valid"
+	CodeIncludeNotValid      = "include_not_valid"
+	CodeFieldsNotValid       = "fields_not_valid"
+)
+
+// RequestError an error in the request itself, such as a malformed body, carrying its own status and code
+type RequestError struct {
+	Status int
+	Code   string
+	Err    error
+}
 
-	return status, nil
+func NewRequestError(status int, code string, err error) *RequestError {
+	return &RequestError{Status: status, Code: code, Err: err}
 }
 
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// GetStatus returns the status of the response for the error; unknown errors are internal server errors
+func (e ErrorHandler) GetStatus(err error) int {
+	return e.Problem(err).Status
+}
+
+// HandleApiError writes the error as a problem response, returning its status.
+// http.StatusOK is returned, and nothing written, if there is no error.
 func (e ErrorHandler) HandleApiError(w http.ResponseWriter, err error) int {
 	if err == nil {
 		return http.StatusOK
 	}
 
-	status, _ := e.GetStatus(err)
-	api.ReturnError(err, status, w)
+	problem := e.Problem(err)
+	problem.Write(w)
+
+	return problem.Status
+}
+
+// Problem describes the error as a problem. Errors are matched with errors.Is and errors.As so that
+// wrapped errors still resolve; unknown errors are logged and described only as internal errors.
+func (e ErrorHandler) Problem(err error) *httperror.Problem {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return httperror.New(requestErr.Status, requestErr.Code, requestErr.Err.Error())
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		t := e.em[ErrorValidationFailed]
+		problem := httperror.New(t.status, t.code, ErrorValidationFailed.Error())
+		problem.Errors = fieldErrors(validationErrs)
+		return problem
+	}
+
+	if known, t, ok := e.lookup(err); ok {
+		return httperror.New(t.status, t.code, known.Error())
+	}
+
+	e.log.Errorf("Responding with an internal error for an unhandled error: %s", err)
+
+	t := e.em[ErrorInternal]
+	return httperror.New(t.status, t.code, ErrorInternal.Error())
+}
+
+// ErrorForCode returns the known error responded with the problem code, such as project_not_found,
+// or nil if the code is not that of a known error
+func ErrorForCode(code string) error {
+	for known, t := range errorMap {
+		if t.code == code {
+			return known
+		}
+	}
+
+	return nil
+}
+
+// lookup finds the known error which err is, or wraps
+func (e ErrorHandler) lookup(err error) (error, problemType, bool) {
+	if t, ok := e.em[err]; ok {
+		return err, t, true
+	}
+
+	for known, t := range e.em {
+		if errors.Is(err, known) {
+			return known, t, true
+		}
+	}
 
-	return status
+	return nil, problemType{}, false
 }
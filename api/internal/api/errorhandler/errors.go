@@ -5,49 +5,128 @@ import (
 	"net/http"
 )
 
-func makeErrorMap() map[error]int {
-	return map[error]int{
-		ErrorAccountNotFound:      http.StatusNotFound,
-		ErrorAccountNotCreated:    http.StatusInternalServerError,
-		ErrorAccountAlreadyExists: http.StatusBadRequest,
-		ErrorUserNotFound:         http.StatusNotFound,
-		ErrorUserAlreadyExists:    http.StatusBadRequest,
-		ErrorUserAuthentication:   http.StatusUnauthorized,
-		ErrorProjectNotFound:      http.StatusNotFound,
-		ErrorProjectNotCreated:    http.StatusInternalServerError,
-		ErrorProjectJSONParse:     http.StatusBadRequest,
-		ErrorTaskNotFound:         http.StatusNotFound,
-		ErrorTaskNotCreated:       http.StatusInternalServerError,
-		ErrorTaskNotUpdated:       http.StatusInternalServerError,
-		ErrorStoryNotFound:        http.StatusNotFound,
-		ErrorStoryNotCreated:      http.StatusInternalServerError,
-		ErrorStoryNotUpdated:      http.StatusInternalServerError,
-		ErrorStoryNotDeleted:      http.StatusInternalServerError,
-		ErrorStoryJsonParse:       http.StatusBadRequest,
-		ErrorTagNotFound:          http.StatusNotFound,
-		ErrorTagNotCreated:        http.StatusInternalServerError,
-		ErrorTagNotUpdated:        http.StatusInternalServerError,
-		ErrorTagMalformedId:       http.StatusBadRequest,
-		ErrorTagAlreadyExists:     http.StatusBadRequest,
+func makeErrorMap() map[error]problemType {
+	return map[error]problemType{
+		ErrorInternal:         {http.StatusInternalServerError, "internal_error"},
+		ErrorUnauthorized:     {http.StatusUnauthorized, "unauthorized"},
+		ErrorValidationFailed: {http.StatusBadRequest, "validation_failed"},
+		ErrorRouteNotFound:    {http.StatusNotFound, "route_not_found"},
+		ErrorMethodNotAllowed: {http.StatusMethodNotAllowed, "method_not_allowed"},
+
+		ErrorAccountNotFound:             {http.StatusNotFound, "account_not_found"},
+		ErrorAccountNotCreated:           {http.StatusInternalServerError, "account_not_created"},
+		ErrorAccountAlreadyExists:        {http.StatusBadRequest, "account_already_exists"},
+		ErrorAccountNotUpdated:           {http.StatusInternalServerError, "account_not_updated"},
+		ErrorTimeZoneNotValid:            {http.StatusBadRequest, "time_zone_not_valid"},
+		ErrorAccountDeletionScheduled:    {http.StatusBadRequest, "account_deletion_scheduled"},
+		ErrorAccountDeletionNotScheduled: {http.StatusBadRequest, "account_deletion_not_scheduled"},
+		ErrorUserNotFound:                {http.StatusNotFound, "user_not_found"},
+		ErrorUserAlreadyExists:           {http.StatusBadRequest, "user_already_exists"},
+		ErrorUserAuthentication:          {http.StatusUnauthorized, "invalid_credentials"},
+		ErrorUserNotUpdated:              {http.StatusInternalServerError, "user_not_updated"},
+		ErrorPasswordIncorrect:           {http.StatusBadRequest, "password_incorrect"},
+		ErrorUsernameUnavailable:         {http.StatusConflict, "username_unavailable"},
+		ErrorHandleNotValid:              {http.StatusBadRequest, "handle_not_valid"},
+		ErrorPermissionDenied:            {http.StatusForbidden, "permission_denied"},
+		ErrorLastOwner:                   {http.StatusBadRequest, "last_owner"},
+		ErrorRoleNotValid:                {http.StatusBadRequest, "role_not_valid"},
+		ErrorUserDeactivated:             {http.StatusForbidden, "user_deactivated"},
+		ErrorUserAlreadyDeactivated:      {http.StatusBadRequest, "user_already_deactivated"},
+		ErrorUserNotDeactivated:          {http.StatusBadRequest, "user_not_deactivated"},
+		ErrorDeactivateSelf:              {http.StatusBadRequest, "deactivate_self"},
+		ErrorSuccessorNotValid:           {http.StatusBadRequest, "successor_not_valid"},
+		ErrorAccountMemberNotFound:       {http.StatusForbidden, "account_member_not_found"},
+		ErrorAccountMemberAlreadyExists:  {http.StatusBadRequest, "account_member_already_exists"},
+		ErrorProjectNotFound:             {http.StatusNotFound, "project_not_found"},
+		ErrorProjectNotCreated:           {http.StatusInternalServerError, "project_not_created"},
+		ErrorProjectJSONParse:            {http.StatusBadRequest, "project_json_parse"},
+		ErrorProjectMemberNotFound:       {http.StatusNotFound, "project_member_not_found"},
+		ErrorTeamNotFound:                {http.StatusNotFound, "team_not_found"},
+		ErrorTeamNotCreated:              {http.StatusInternalServerError, "team_not_created"},
+		ErrorTeamNotUpdated:              {http.StatusInternalServerError, "team_not_updated"},
+		ErrorTeamMemberNotFound:          {http.StatusNotFound, "team_member_not_found"},
+		ErrorTaskNotFound:                {http.StatusNotFound, "task_not_found"},
+		ErrorTaskNotCreated:              {http.StatusInternalServerError, "task_not_created"},
+		ErrorTaskNotUpdated:              {http.StatusInternalServerError, "task_not_updated"},
+		ErrorStoryNotFound:               {http.StatusNotFound, "story_not_found"},
+		ErrorStoryNotCreated:             {http.StatusInternalServerError, "story_not_created"},
+		ErrorStoryNotUpdated:             {http.StatusInternalServerError, "story_not_updated"},
+		ErrorStoryNotDeleted:             {http.StatusInternalServerError, "story_not_deleted"},
+		ErrorStoryJsonParse:              {http.StatusBadRequest, "story_json_parse"},
+		ErrorTagNotFound:                 {http.StatusNotFound, "tag_not_found"},
+		ErrorTagNotCreated:               {http.StatusInternalServerError, "tag_not_created"},
+		ErrorTagNotUpdated:               {http.StatusInternalServerError, "tag_not_updated"},
+		ErrorTagMalformedId:              {http.StatusBadRequest, "tag_malformed_id"},
+		ErrorTagAlreadyExists:            {http.StatusBadRequest, "tag_already_exists"},
+
+		ErrorTwoFactorAlreadyEnabled:     {http.StatusBadRequest, "two_factor_already_enabled"},
+		ErrorTwoFactorNotEnrolled:        {http.StatusBadRequest, "two_factor_not_enrolled"},
+		ErrorTwoFactorInvalidCode:        {http.StatusUnauthorized, "two_factor_invalid_code"},
+		ErrorTwoFactorChallengeInvalid:   {http.StatusUnauthorized, "two_factor_challenge_invalid"},
+		ErrorTwoFactorEnrollmentRequired: {http.StatusForbidden, "two_factor_enrollment_required"},
+
+		ErrorInvitationNotFound:       {http.StatusNotFound, "invitation_not_found"},
+		ErrorInvitationNotCreated:     {http.StatusInternalServerError, "invitation_not_created"},
+		ErrorInvitationNotValid:       {http.StatusBadRequest, "invitation_not_valid"},
+		ErrorInvitationAlreadyExists:  {http.StatusBadRequest, "invitation_already_exists"},
+		ErrorInvitationRoleNotAllowed: {http.StatusBadRequest, "invitation_role_not_allowed"},
 	}
 }
 
+var (
+	ErrorInternal         = errors.New("an unexpected error occurred")
+	ErrorUnauthorized     = errors.New("the request must be authenticated with a valid token")
+	ErrorValidationFailed = errors.New("the request failed validation")
+	ErrorRouteNotFound    = errors.New("the requested resource does not exist")
+	ErrorMethodNotAllowed = errors.New("the requested resource does not support the method")
+)
+
 var (
 	ErrorAccountNotFound      = errors.New("the specified account could not be found")
 	ErrorAccountNotCreated    = errors.New("the account could not be created")
 	ErrorAccountAlreadyExists = errors.New("the specified account already exists")
+	ErrorAccountNotUpdated    = errors.New("the account could not be updated")
+	ErrorTimeZoneNotValid     = errors.New("the time zone must be a valid IANA time zone, such as Europe/London")
+
+	ErrorAccountDeletionScheduled    = errors.New("the account is already scheduled for deletion")
+	ErrorAccountDeletionNotScheduled = errors.New("the account is not scheduled for deletion")
 )
 
 var (
-	ErrorUserNotFound       = errors.New("a user with the specified email address could not be found")
-	ErrorUserAlreadyExists  = errors.New("a username with the given email address already exists")
-	ErrorUserAuthentication = errors.New("a user with the given email and password combination could not be found")
+	ErrorUserNotFound        = errors.New("a user with the specified email address could not be found")
+	ErrorUserAlreadyExists   = errors.New("a username with the given email address already exists")
+	ErrorUserAuthentication  = errors.New("a user with the given email and password combination could not be found")
+	ErrorUserNotUpdated      = errors.New("the user could not be updated")
+	ErrorPasswordIncorrect   = errors.New("the current password is incorrect")
+	ErrorUsernameUnavailable = errors.New("too many users share the given username, please choose another")
+	ErrorHandleNotValid      = errors.New("the handle must be in the format username#discriminator")
+	ErrorPermissionDenied    = errors.New("you do not have permission to perform this action")
+	ErrorLastOwner           = errors.New("the account must be left with at least one owner")
+	ErrorRoleNotValid        = errors.New("the given role is not valid")
+
+	ErrorUserDeactivated        = errors.New("the user has been deactivated")
+	ErrorUserAlreadyDeactivated = errors.New("the user has already been deactivated")
+	ErrorUserNotDeactivated     = errors.New("the user has not been deactivated")
+	ErrorDeactivateSelf         = errors.New("you cannot deactivate yourself")
+	ErrorSuccessorNotValid      = errors.New("work can only be reassigned to another active user in the account who may change it")
+
+	ErrorAccountMemberNotFound      = errors.New("you are not a member of the specified account")
+	ErrorAccountMemberAlreadyExists = errors.New("the user is already a member of the account")
 )
 
 var (
 	ErrorProjectNotFound   = errors.New("the requested project could not be found")
 	ErrorProjectNotCreated = errors.New("the project could not be created")
 	ErrorProjectJSONParse  = errors.New("could not process the given project")
+
+	ErrorProjectMemberNotFound = errors.New("the user is not a member of the project")
+)
+
+var (
+	ErrorTeamNotFound       = errors.New("the specified team could not be found")
+	ErrorTeamNotCreated     = errors.New("the team could not be created")
+	ErrorTeamNotUpdated     = errors.New("the team could not be updated")
+	ErrorTeamMemberNotFound = errors.New("the user is not a member of the team")
 )
 
 var (
@@ -71,3 +150,19 @@ var (
 	ErrorTagMalformedId   = errors.New("the tagId is not in an appropriate format - expected uint")
 	ErrorTagAlreadyExists = errors.New("a tag with that name already exists for the given project")
 )
+
+var (
+	ErrorTwoFactorAlreadyEnabled     = errors.New("two-factor authentication is already enabled for this user")
+	ErrorTwoFactorNotEnrolled        = errors.New("two-factor authentication enrollment has not been started for this user")
+	ErrorTwoFactorInvalidCode        = errors.New("the two-factor authentication code is not valid")
+	ErrorTwoFactorChallengeInvalid   = errors.New("the two-factor challenge token is not valid or has expired")
+	ErrorTwoFactorEnrollmentRequired = errors.New("the account requires two-factor authentication to be enabled before continuing")
+)
+
+var (
+	ErrorInvitationNotFound       = errors.New("the specified invitation could not be found")
+	ErrorInvitationNotCreated     = errors.New("the invitation could not be created")
+	ErrorInvitationNotValid       = errors.New("the invitation has expired, been revoked or already been used")
+	ErrorInvitationAlreadyExists  = errors.New("a pending invitation already exists for the given email address")
+	ErrorInvitationRoleNotAllowed = errors.New("users cannot be invited with the given role")
+)
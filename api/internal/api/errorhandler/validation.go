@@ -0,0 +1,33 @@
+package errorhandler
+
+import (
+	"godo/internal/api/httperror"
+	"godo/internal/helper/validate"
+	"strings"
+
+	"github.com/go-playground/validator"
+)
+
+// fieldErrors describes each field which failed validation by its path in the request body
+func fieldErrors(errs validator.ValidationErrors) []httperror.FieldError {
+	fields := make([]httperror.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, httperror.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: validate.Message(fe),
+		})
+	}
+
+	return fields
+}
+
+// fieldPath removes the name of the validated struct from the field's namespace
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Resource a type of entity served by the read endpoints, named by the fields query parameter, e.g.
+// fields[task]=name,status, with the relations which may be included with it
+type Resource struct {
+	name      string
+	fields    map[string]bool
+	relations map[string]relation
+
+	// The relations included when the include query parameter is not given
+	defaults []string
+}
+
+// relation a relation of a resource, named by its field in the response
+type relation struct {
+	association string // The gorm association loading the relation, e.g. Stories
+	resource    *Resource
+}
+
+// The resources of the read endpoints
+var (
+	userResource = newResource("user", entities.User{}, nil)
+	tagResource  = newResource("tag", entities.Tag{}, nil)
+
+	TaskResource = newResource("task", entities.Task{}, map[string]relation{
+		"creator": {"Creator", userResource},
+		"tags":    {"Tags", tagResource},
+	}, "creator")
+
+	StoryResource = newResource("story", entities.Story{}, map[string]relation{
+		"creator": {"Creator", userResource},
+		"tasks":   {"Tasks", TaskResource},
+	}, "creator")
+
+	ProjectResource = newResource("project", entities.Project{}, map[string]relation{
+		"creator": {"Creator", userResource},
+		"stories": {"Stories", StoryResource},
+		"tags":    {"Tags", tagResource},
+	}, "creator", "stories.creator", "stories.tasks.creator", "stories.tasks.tags", "tags")
+
+	ProjectInfoResource = newResource("project", entities.ProjectInfo{}, nil)
+	StoryInfoResource   = newResource("story", entities.StoryInfo{}, nil)
+)
+
+// The fields responded with whichever fields are asked for
+var alwaysSelected = map[string]bool{"id": true, "_links": true}
+
+func newResource(name string, entity interface{}, relations map[string]relation, defaults ...string) *Resource {
+	fields := make(map[string]bool)
+	addJSONFields(reflect.TypeOf(entity), fields)
+
+	return &Resource{name: name, fields: fields, relations: relations, defaults: defaults}
+}
+
+// addJSONFields adds the names of the fields of the struct as encoded in JSON, including those of
+// embedded structs
+func addJSONFields(t reflect.Type, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+
+		if field.Anonymous && tag == "" {
+			addJSONFields(field.Type, fields)
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = true
+	}
+}
+
+// Expansion the relations included in a response and the fields of each resource it is sent with, as
+// asked for by the include and fields query parameters, e.g.
+//
+//	?include=stories.tasks.tags,creator&fields[task]=name,status
+type Expansion struct {
+	resource *Resource
+
+	// The paths of the included relations, with those of their parents, e.g. stories and stories.tasks
+	include map[string]bool
+
+	// The fields of each resource, by its name; all fields are sent for the resources not given
+	fields map[string]map[string]bool
+
+	// Whether either parameter was given, without which the response is sent as it is
+	asked bool
+}
+
+// ParseExpansion reads the include and fields query parameters of a request for the resource, rejecting
+// relations which cannot be included and fields which the resources do not have
+func ParseExpansion(query url.Values, resource *Resource) (*Expansion, error) {
+	e := &Expansion{
+		resource: resource,
+		include:  make(map[string]bool),
+		fields:   make(map[string]map[string]bool),
+	}
+
+	paths := resource.defaults
+	if values, ok := query["include"]; ok {
+		e.asked = true
+		paths = splitList(values)
+	}
+
+	for _, path := range paths {
+		if err := e.addInclude(path); err != nil {
+			return nil, err
+		}
+	}
+
+	resources := resource.reachable(make(map[string]*Resource))
+	for key, values := range query {
+		if !strings.HasPrefix(key, "fields[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+
+		e.asked = true
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "fields["), "]")
+
+		r, ok := resources[name]
+		if !ok {
+			err := fmt.Errorf("%s is not the type of a resource of the response", name)
+			return nil, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeFieldsNotValid, err)
+		}
+
+		fields := make(map[string]bool)
+		for _, field := range splitList(values) {
+			if !r.fields[field] {
+				err := fmt.Errorf("%s is not a field of %s", field, name)
+				return nil, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeFieldsNotValid, err)
+			}
+
+			fields[field] = true
+		}
+
+		e.fields[name] = fields
+	}
+
+	return e, nil
+}
+
+// addInclude includes the relation at the path, and its parents
+func (e *Expansion) addInclude(path string) error {
+	r := e.resource
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		rel, ok := r.relations[name]
+		if !ok {
+			err := fmt.Errorf("%s is not a relation which can be included", path)
+			return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeIncludeNotValid, err)
+		}
+
+		e.include[strings.Join(names[:i+1], ".")] = true
+		r = rel.resource
+	}
+
+	return nil
+}
+
+// reachable adds the resource, and those of its relations, by their names
+func (r *Resource) reachable(resources map[string]*Resource) map[string]*Resource {
+	resources[r.name] = r
+	for _, rel := range r.relations {
+		rel.resource.reachable(resources)
+	}
+
+	return resources
+}
+
+// Preload the associations loading the included relations, parents first
+func (e *Expansion) Preload() repository.Preload {
+	paths := make([]string, 0, len(e.include))
+	for path := range e.include {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	preload := make(repository.Preload, 0, len(paths))
+	for _, path := range paths {
+		r := e.resource
+		associations := make([]string, 0)
+
+		for _, name := range strings.Split(path, ".") {
+			rel := r.relations[name]
+			associations = append(associations, rel.association)
+			r = rel.resource
+		}
+
+		preload = append(preload, strings.Join(associations, "."))
+	}
+
+	return preload
+}
+
+// Select returns the response with only the included relations and the asked for fields; without
+// either query parameter, the response is returned as it is
+func (e *Expansion) Select(i interface{}) (interface{}, error) {
+	if e == nil || !e.asked {
+		return i, nil
+	}
+
+	var buf bytes.Buffer
+	if err := ToJSON(i, &buf); err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	e.selectFields(doc, e.resource, "")
+	return doc, nil
+}
+
+// selectFields removes, from the decoded JSON of the resource at the path, the relations which are not
+// included and the fields which are not asked for
+func (e *Expansion) selectFields(doc interface{}, r *Resource, path string) {
+	switch v := doc.(type) {
+	case []interface{}:
+		for _, item := range v {
+			e.selectFields(item, r, path)
+		}
+
+	case map[string]interface{}:
+		fields, sparse := e.fields[r.name]
+
+		for key, value := range v {
+			if sparse && !fields[key] && !alwaysSelected[key] {
+				delete(v, key)
+				continue
+			}
+
+			rel, ok := r.relations[key]
+			if !ok {
+				continue
+			}
+
+			relPath := key
+			if path != "" {
+				relPath = path + "." + key
+			}
+
+			if !e.include[relPath] {
+				delete(v, key)
+				continue
+			}
+
+			e.selectFields(value, rel.resource, relPath)
+		}
+	}
+}
+
+// splitList splits the comma separated values of a query parameter
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+
+	return list
+}
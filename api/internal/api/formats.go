@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// The formats of the API; each is encoded from, and decoded to, the JSON of the body, such that every
+// format has the same fields as the JSON documented by the OpenAPI document
+var (
+	JSONFormat = Format{
+		MediaType: "application/json",
+		Encode:    ToJSON,
+		Decode:    func(body []byte) ([]byte, error) { return body, nil },
+	}
+
+	YAMLFormat = Format{
+		MediaType: "application/yaml",
+		Aliases:   []string{"application/x-yaml", "text/yaml"},
+		Encode:    toYAML,
+		Decode:    yaml.YAMLToJSON,
+	}
+
+	CSVFormat = Format{
+		MediaType: "text/csv",
+		Encode:    toCSV,
+		ListsOnly: true,
+	}
+
+	MessagePackFormat = Format{
+		MediaType: "application/msgpack",
+		Aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		Encode:    toMessagePack,
+		Decode:    messagePackToJSON,
+	}
+)
+
+func init() {
+	RegisterFormat(JSONFormat)
+	RegisterFormat(YAMLFormat)
+	RegisterFormat(CSVFormat)
+	RegisterFormat(MessagePackFormat)
+}
+
+// toDocument the value as decoded from its JSON, keeping numbers as json.Number
+func toDocument(i interface{}) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := ToJSON(i, &buf); err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	err := dec.Decode(&doc)
+
+	return doc, err
+}
+
+func toYAML(i interface{}, w io.Writer) error {
+	b, err := yaml.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// toCSV writes the list as a header of the fields of its items, the id first, and a row for each item;
+// fields holding objects or lists are written as their JSON. A nil list, null in JSON, has no rows.
+func toCSV(i interface{}, w io.Writer) error {
+	doc, err := toDocument(i)
+	if err != nil {
+		return err
+	}
+
+	items, ok := doc.([]interface{})
+	if !ok && doc != nil {
+		return errors.New("only lists can be written as CSV")
+	}
+
+	columns := csvColumns(items)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		fields, _ := item.(map[string]interface{})
+		row := make([]string, len(columns))
+
+		for c, column := range columns {
+			if row[c], err = csvCell(fields[column]); err != nil {
+				return err
+			}
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvColumns the fields of any of the items, the id first and the rest by name
+func csvColumns(items []interface{}) []string {
+	names := make(map[string]bool)
+	for _, item := range items {
+		if fields, ok := item.(map[string]interface{}); ok {
+			for name := range fields {
+				names[name] = true
+			}
+		}
+	}
+
+	columns := make([]string, 0, len(names))
+	for name := range names {
+		if name != "id" {
+			columns = append(columns, name)
+		}
+	}
+
+	sort.Strings(columns)
+	if names["id"] {
+		columns = append([]string{"id"}, columns...)
+	}
+
+	return columns
+}
+
+func csvCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+// toMessagePack writes the JSON document of the value, such that its fields are named as in JSON
+func toMessagePack(i interface{}, w io.Writer) error {
+	doc, err := toDocument(i)
+	if err != nil {
+		return err
+	}
+
+	return msgpack.NewEncoder(w).Encode(fromJSONNumbers(doc))
+}
+
+// fromJSONNumbers replaces the numbers of the document with integers, where they are whole, or floats
+func fromJSONNumbers(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+
+	case []interface{}:
+		for i := range v {
+			v[i] = fromJSONNumbers(v[i])
+		}
+
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = fromJSONNumbers(v[key])
+		}
+	}
+
+	return doc
+}
+
+func messagePackToJSON(body []byte) ([]byte, error) {
+	var doc interface{}
+	dec := msgpack.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.DecodeInterface(); err != io.EOF {
+		return nil, errors.New("the body must only contain a single MessagePack value")
+	}
+
+	return json.Marshal(doc)
+}
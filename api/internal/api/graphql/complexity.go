@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// The number of items each list is assumed to have when estimating the complexity of a query
+const assumedListSize = 10
+
+// complexity estimates the cost of resolving a query before it is executed, rejecting those which
+// cost more than the maximum. Each field costs 1, plus the cost of its selections, which is
+// multiplied by assumedListSize for lists, such that
+//
+//	{ project(id: "...") { name stories { name tasks { name } } } }
+//
+// costs 1 + 1 + (1 + 10 × (1 + (1 + 10 × 1))) = 123
+type complexity struct {
+	schema *ast.Schema
+	max    int
+}
+
+func newComplexity(schema string, max int) (*complexity, error) {
+	parsed, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: schema})
+	if err != nil {
+		return nil, fmt.Errorf("could not load the GraphQL schema to estimate complexity: %w", err)
+	}
+
+	return &complexity{schema: parsed, max: max}, nil
+}
+
+// check returns an error if the query is more complex than the maximum. Queries which are not valid
+// are left to be rejected by their execution, which describes why.
+func (c *complexity) check(query, operationName string) *gqlerrors.QueryError {
+	if c.max <= 0 {
+		return nil
+	}
+
+	doc, errs := gqlparser.LoadQuery(c.schema, query)
+	if len(errs) > 0 {
+		return nil
+	}
+
+	operation := doc.Operations.ForName(operationName)
+	if operation == nil {
+		return nil
+	}
+
+	if cost := c.selections(operation.SelectionSet); cost > c.max {
+		return &gqlerrors.QueryError{
+			Message:    fmt.Sprintf("the query has a complexity of %d, more than the maximum of %d", cost, c.max),
+			Extensions: map[string]interface{}{"code": "query_too_complex"},
+		}
+	}
+
+	return nil
+}
+
+func (c *complexity) selections(set ast.SelectionSet) int {
+	cost := 0
+	for _, selection := range set {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost += c.field(selection)
+		case *ast.InlineFragment:
+			cost += c.selections(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			cost += c.selections(selection.Definition.SelectionSet)
+		}
+	}
+
+	return cost
+}
+
+func (c *complexity) field(field *ast.Field) int {
+	// Introspection is bounded by the maximum depth alone
+	if strings.HasPrefix(field.Name, "__") {
+		return 0
+	}
+
+	cost := c.selections(field.SelectionSet)
+	if field.Definition != nil && field.Definition.Type.Elem != nil {
+		cost *= assumedListSize
+	}
+
+	return 1 + cost
+}
@@ -0,0 +1,37 @@
+package graphql
+
+import (
+	"fmt"
+	"godo/internal/repository/enums"
+)
+
+// The values of the schema's enums
+
+var projectStatuses = map[enums.ProjectStatus]string{
+	enums.Open:   "OPEN",
+	enums.Closed: "CLOSED",
+}
+
+var progressStatuses = map[enums.ProgressStatus]string{
+	enums.New:        "NEW",
+	enums.InProgress: "IN_PROGRESS",
+	enums.Complete:   "COMPLETE",
+}
+
+var taskTypes = map[enums.TaskType]string{
+	enums.Task: "TASK",
+	enums.Bug:  "BUG",
+	enums.Test: "TEST",
+}
+
+// enumOf returns the enum with the given value; values are validated against the schema before
+// being resolved, so an unknown value is a mismatch between the schema and the map
+func enumOf[T comparable](values map[T]string, value string) T {
+	for e, v := range values {
+		if v == value {
+			return e
+		}
+	}
+
+	panic(fmt.Sprintf("%s is not a value of %T", value, *new(T)))
+}
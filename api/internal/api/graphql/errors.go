@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/httperror"
+	"godo/internal/auth"
+	"godo/internal/repository/entities"
+)
+
+// resolverError an error of a resolver, described in its extensions as it is by the REST API's problems
+//
+//	{"message": "project not found", "path": ["project"], "extensions": {"code": "project_not_found", "status": 404}}
+type resolverError struct {
+	problem *httperror.Problem
+}
+
+func failure(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &resolverError{problem: ehand.New().Problem(err)}
+}
+
+func (e *resolverError) Error() string {
+	return e.problem.Detail
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":   e.problem.Code,
+		"status": e.problem.Status,
+	}
+
+	if len(e.problem.Errors) > 0 {
+		extensions["errors"] = e.problem.Errors
+	}
+
+	return extensions
+}
+
+// authorize returns the user of the request if their role grants the permission, as does the REST route
+// of the same operation
+func authorize(l *loaders, permission auth.Permission) (entities.User, error) {
+	if !auth.HasPermission(l.user.Role, permission) {
+		return l.user, failure(ehand.ErrorPermissionDenied)
+	}
+
+	return l.user, nil
+}
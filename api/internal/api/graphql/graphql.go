@@ -0,0 +1,103 @@
+// Package graphql serves the projects, stories, tasks and tags visible to the user as a GraphQL schema.
+// It is resolved by the same services as the REST API, and so with the same account scoping and
+// authorization; the relations of each entity are fetched by per-request loaders, which batch the
+// keys requested by sibling resolvers into a single query.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"godo/internal/helper/validate"
+	"godo/internal/repository/entities"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Services the services resolving the schema
+type Services struct {
+	Projects services.ProjectService
+	Stories  services.StoryService
+	Tasks    services.TaskService
+	Tags     services.TagService
+	Users    services.UserService
+}
+
+// Limits the limits on the cost of a query, checked before it is executed
+type Limits struct {
+	// MaxDepth the deepest the fields of a query may be nested
+	MaxDepth int
+
+	// MaxComplexity the greatest complexity of a query; see complexity
+	MaxComplexity int
+}
+
+// Request a query, as posted to the endpoint
+type Request struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+
+	// Extensions of the request, such as those of persisted queries, which are not supported
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+func init() {
+	validate.Register(Request{})
+}
+
+type API struct {
+	log        ilog.StdLogger
+	schema     *graphqlgo.Schema
+	complexity *complexity
+	services   Services
+}
+
+func New(svc Services, limits Limits, logger ilog.StdLogger) (*API, error) {
+	parsed, err := graphqlgo.ParseSchema(
+		schema,
+		&resolver{services: svc},
+		graphqlgo.UseStringDescriptions(),
+		graphqlgo.MaxDepth(limits.MaxDepth),
+		graphqlgo.PanicHandler(&panicHandler{log: logger}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the GraphQL schema: %w", err)
+	}
+
+	complexity, err := newComplexity(schema, limits.MaxComplexity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &API{log: logger, schema: parsed, complexity: complexity, services: svc}, nil
+}
+
+// Exec executes the query as the authenticated user in the context
+func (a *API) Exec(ctx context.Context, req Request) *graphqlgo.Response {
+	if err := a.complexity.check(req.Query, req.OperationName); err != nil {
+		a.log.Infof("Rejecting a GraphQL query: %s", err.Message)
+		return &graphqlgo.Response{Errors: []*gqlerrors.QueryError{err}}
+	}
+
+	user := ctx.Value(entities.UserKey{}).(entities.User)
+	ctx = withLoaders(ctx, newLoaders(a.services, user))
+
+	return a.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// panicHandler logs a panic within a resolver, responding only that the field could not be resolved
+type panicHandler struct {
+	log ilog.StdLogger
+}
+
+func (h *panicHandler) MakePanicError(ctx context.Context, value interface{}) *gqlerrors.QueryError {
+	h.log.Errorf("Panic while resolving a GraphQL query: %v", value)
+	return gqlerrors.Errorf("internal error")
+}
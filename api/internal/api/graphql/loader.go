@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How long a loader waits for the keys requested by sibling resolvers before fetching them together
+const batchWait = 2 * time.Millisecond
+
+// The most keys fetched by a single batch
+const maxBatch = 500
+
+// batchFunc fetches the values of the keys, omitting those which do not exist
+type batchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// loader collects the keys requested by resolvers running concurrently, fetching them in a single
+// batch, and caches the values for the rest of the request
+type loader[K comparable, V any] struct {
+	fetch batchFunc[K, V]
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results map[K]*result[V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[K comparable, V any](fetch batchFunc[K, V]) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: make(map[K]*result[V])}
+}
+
+// load returns the value of the key, and whether it exists, once the batch including it has been fetched
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, bool, error) {
+	res := l.request(key)
+
+	select {
+	case <-res.done:
+		return res.value, res.found, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+}
+
+// prime caches the value of the key, such as one fetched by its parent, if it is not already
+func (l *loader[K, V]) prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+
+	res := &result[V]{done: make(chan struct{}), value: value, found: true}
+	close(res.done)
+	l.cache[key] = res
+}
+
+// clear forgets the cached values; keys already requested are still fetched by their batch
+func (l *loader[K, V]) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cache = make(map[K]*result[V])
+}
+
+func (l *loader[K, V]) request(key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if res, ok := l.cache[key]; ok {
+		return res
+	}
+
+	res := &result[V]{done: make(chan struct{})}
+	l.cache[key] = res
+
+	if l.pending == nil {
+		l.pending = &batch[K, V]{results: make(map[K]*result[V])}
+		pending := l.pending
+
+		time.AfterFunc(batchWait, func() {
+			l.dispatch(pending)
+		})
+	}
+
+	l.pending.keys = append(l.pending.keys, key)
+	l.pending.results[key] = res
+
+	// A full batch is fetched without waiting for more keys
+	if len(l.pending.keys) >= maxBatch {
+		pending := l.pending
+		l.pending = nil
+		go l.run(pending)
+	}
+
+	return res
+}
+
+// dispatch fetches the batch once it has waited for more keys, unless it was fetched when full
+func (l *loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+func (l *loader[K, V]) run(b *batch[K, V]) {
+	values, err := l.fetch(b.keys)
+
+	for key, res := range b.results {
+		res.value, res.found = values[key]
+		res.err = err
+		close(res.done)
+	}
+}
@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"godo/internal/repository/entities"
+)
+
+type loadersKey struct{}
+
+// loaders the loaders of a single request, fetching only what is visible to its user
+type loaders struct {
+	user entities.User
+
+	projects       *loader[string, *entities.Project]
+	stories        *loader[string, *entities.Story]
+	projectStories *loader[string, []*entities.Story]
+	projectTags    *loader[string, entities.TagList]
+	storyTasks     *loader[string, entities.TaskList]
+	taskTags       *loader[string, entities.TagList]
+	users          *loader[uint, *entities.User]
+}
+
+func newLoaders(svc Services, user entities.User) *loaders {
+	return &loaders{
+		user: user,
+
+		projects: newLoader(func(ids []string) (map[string]*entities.Project, error) {
+			projects, err := svc.Projects.GetProjectsByIds(user, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byId := make(map[string]*entities.Project, len(projects))
+			for _, project := range projects {
+				byId[project.ID] = project
+			}
+
+			return byId, nil
+		}),
+
+		stories: newLoader(func(ids []string) (map[string]*entities.Story, error) {
+			stories, err := svc.Stories.GetStoriesByIds(user, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byId := make(map[string]*entities.Story, len(stories))
+			for _, story := range stories {
+				byId[story.ID] = story
+			}
+
+			return byId, nil
+		}),
+
+		projectStories: newLoader(func(projectIds []string) (map[string][]*entities.Story, error) {
+			stories, err := svc.Stories.GetStoriesByProjectIds(user, projectIds)
+			if err != nil {
+				return nil, err
+			}
+
+			byProject := make(map[string][]*entities.Story)
+			for _, story := range stories {
+				byProject[story.ProjectId] = append(byProject[story.ProjectId], story)
+			}
+
+			return byProject, nil
+		}),
+
+		projectTags: newLoader(func(projectIds []string) (map[string]entities.TagList, error) {
+			tags, err := svc.Tags.GetTagsByProjectIds(projectIds)
+			if err != nil {
+				return nil, err
+			}
+
+			byProject := make(map[string]entities.TagList)
+			for _, tag := range tags {
+				byProject[tag.ProjectId] = append(byProject[tag.ProjectId], tag)
+			}
+
+			return byProject, nil
+		}),
+
+		storyTasks: newLoader(func(storyIds []string) (map[string]entities.TaskList, error) {
+			tasks, err := svc.Tasks.GetTasksByStoryIds(user, storyIds)
+			if err != nil {
+				return nil, err
+			}
+
+			byStory := make(map[string]entities.TaskList)
+			for _, task := range tasks {
+				byStory[task.StoryId] = append(byStory[task.StoryId], task)
+			}
+
+			return byStory, nil
+		}),
+
+		taskTags: newLoader(func(taskIds []string) (map[string]entities.TagList, error) {
+			return svc.Tags.GetTagsByTaskIds(taskIds)
+		}),
+
+		users: newLoader(func(ids []uint) (map[uint]*entities.User, error) {
+			users, err := svc.Users.GetUsersByIds(user.AccountId, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byId := make(map[uint]*entities.User, len(users))
+			for _, u := range users {
+				byId[u.ID] = u
+			}
+
+			return byId, nil
+		}),
+	}
+}
+
+// clear forgets everything loaded, such that the fields of a mutation's result are fetched after it
+func (l *loaders) clear() {
+	l.projects.clear()
+	l.stories.clear()
+	l.projectStories.clear()
+	l.projectTags.clear()
+	l.storyTasks.clear()
+	l.taskTags.clear()
+	l.users.clear()
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
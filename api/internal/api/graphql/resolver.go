@@ -0,0 +1,595 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
+	"godo/internal/helper/validate"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"strconv"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+)
+
+// resolver resolves the fields of the Query and Mutation types. Each checks the permission of the
+// REST route of the same operation, the services then scoping and authorizing it as they do for REST.
+type resolver struct {
+	services Services
+}
+
+// Queries
+
+func (r *resolver) Projects(ctx context.Context, args struct{ TeamId *graphqlgo.ID }) ([]*projectResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionProjectRead)
+	if err != nil {
+		return nil, err
+	}
+
+	teamId := ""
+	if args.TeamId != nil {
+		teamId = string(*args.TeamId)
+	}
+
+	info, err := r.services.Projects.GetProjects(user, teamId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	ids := make([]string, 0, len(info))
+	for _, project := range info {
+		ids = append(ids, project.ID)
+	}
+
+	projects, err := r.services.Projects.GetProjectsByIds(user, ids)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	byId := make(map[string]*entities.Project, len(projects))
+	for _, project := range projects {
+		byId[project.ID] = project
+		l.projects.prime(project.ID, project)
+	}
+
+	// In the order of GetProjects, most recently created first
+	resolvers := make([]*projectResolver, 0, len(projects))
+	for _, id := range ids {
+		if project, ok := byId[id]; ok {
+			resolvers = append(resolvers, &projectResolver{project: project})
+		}
+	}
+
+	return resolvers, nil
+}
+
+func (r *resolver) Project(ctx context.Context, args struct{ Id graphqlgo.ID }) (*projectResolver, error) {
+	l := loadersFrom(ctx)
+	if _, err := authorize(l, auth.PermissionProjectRead); err != nil {
+		return nil, err
+	}
+
+	project, found, err := l.projects.load(ctx, string(args.Id))
+	if err != nil || !found {
+		return nil, failure(err)
+	}
+
+	return &projectResolver{project: project}, nil
+}
+
+func (r *resolver) Story(ctx context.Context, args struct{ Id graphqlgo.ID }) (*storyResolver, error) {
+	l := loadersFrom(ctx)
+	if _, err := authorize(l, auth.PermissionStoryRead); err != nil {
+		return nil, err
+	}
+
+	story, found, err := l.stories.load(ctx, string(args.Id))
+	if err != nil || !found {
+		return nil, failure(err)
+	}
+
+	return &storyResolver{story: story}, nil
+}
+
+func (r *resolver) Task(ctx context.Context, args struct{ Id graphqlgo.ID }) (*taskResolver, error) {
+	user, err := authorize(loadersFrom(ctx), auth.PermissionTaskRead)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := r.services.Tasks.GetTaskById(string(args.Id), user)
+	if errors.Is(err, ehand.ErrorTaskNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return &taskResolver{task: task}, nil
+}
+
+// Mutations; each forgets what was loaded before it, such that its result is fetched afresh
+
+type newProjectInput struct {
+	Name        string
+	Description string
+	Restricted  *bool
+}
+
+func (r *resolver) CreateProject(ctx context.Context, args struct{ Input newProjectInput }) (*projectResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	projectDto := dto.NewProjectDto{
+		Name:        args.Input.Name,
+		Description: args.Input.Description,
+		Restricted:  args.Input.Restricted != nil && *args.Input.Restricted,
+	}
+	if err := validate.Struct(projectDto); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := r.services.Projects.CreateProject(&entities.Project{
+		Name:        projectDto.Name,
+		Description: projectDto.Description,
+		Restricted:  projectDto.Restricted,
+		Creator:     user,
+	})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &projectResolver{project: created}, nil
+}
+
+type projectChangesInput struct {
+	Name        *string
+	Description *string
+	Status      *string
+}
+
+func (r *resolver) UpdateProject(ctx context.Context, args struct {
+	Id    graphqlgo.ID
+	Input projectChangesInput
+}) (*projectResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := r.services.Projects.GetProjectById(string(args.Id), user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	// The changes are validated as a patch of the project would be
+	projectDto := dto.ProjectPatchDto{
+		Name:        valueOr(args.Input.Name, project.Name),
+		Description: valueOr(args.Input.Description, project.Description),
+		Status:      project.Status,
+	}
+	if args.Input.Status != nil {
+		projectDto.Status = enumOf(projectStatuses, *args.Input.Status)
+	}
+
+	if err := validate.Struct(projectDto); err != nil {
+		return nil, failure(err)
+	}
+
+	patched := *project
+	patched.Name = projectDto.Name
+	patched.Description = projectDto.Description
+	patched.Status = projectDto.Status
+
+	updated, err := r.services.Projects.PatchProject(project.ID, user, &patched)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &projectResolver{project: updated}, nil
+}
+
+func (r *resolver) DeleteProject(ctx context.Context, args struct{ Id graphqlgo.ID }) (graphqlgo.ID, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionProjectDelete)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.services.Projects.DeleteProject(string(args.Id), user); err != nil {
+		return "", failure(err)
+	}
+
+	l.clear()
+	return args.Id, nil
+}
+
+type newStoryInput struct {
+	ProjectId   graphqlgo.ID
+	Name        string
+	Description *string
+}
+
+func (r *resolver) CreateStory(ctx context.Context, args struct{ Input newStoryInput }) (*storyResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionStoryWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	storyDto := dto.NewStoryDto{
+		Name:        args.Input.Name,
+		Description: valueOr(args.Input.Description, ""),
+		ProjectId:   string(args.Input.ProjectId),
+	}
+	if err := validate.Struct(storyDto); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := r.services.Stories.CreateStory(user, &entities.Story{
+		Name:        storyDto.Name,
+		Description: storyDto.Description,
+		ProjectId:   storyDto.ProjectId,
+		Creator:     user,
+	})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &storyResolver{story: created}, nil
+}
+
+type storyChangesInput struct {
+	Name        *string
+	Description *string
+	ProjectId   *graphqlgo.ID
+}
+
+func (r *resolver) UpdateStory(ctx context.Context, args struct {
+	Id    graphqlgo.ID
+	Input storyChangesInput
+}) (*storyResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionStoryWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := r.services.Stories.GetStoryById(user, string(args.Id))
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	storyDto := dto.StoryPatchDto{
+		Name:        valueOr(args.Input.Name, story.Name),
+		Description: valueOr(args.Input.Description, story.Description),
+		ProjectId:   story.ProjectId,
+	}
+	if args.Input.ProjectId != nil {
+		storyDto.ProjectId = string(*args.Input.ProjectId)
+	}
+
+	if err := validate.Struct(storyDto); err != nil {
+		return nil, failure(err)
+	}
+
+	patched := *story
+	patched.Name = storyDto.Name
+	patched.Description = storyDto.Description
+
+	// The service verifies that the story may be moved to the project
+	patched.ProjectId = storyDto.ProjectId
+
+	updated, err := r.services.Stories.PatchStory(user, &patched)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &storyResolver{story: updated}, nil
+}
+
+func (r *resolver) DeleteStory(ctx context.Context, args struct{ Id graphqlgo.ID }) (graphqlgo.ID, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionStoryWrite)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.services.Stories.DeleteStory(user, string(args.Id)); err != nil {
+		return "", failure(err)
+	}
+
+	l.clear()
+	return args.Id, nil
+}
+
+type newTaskInput struct {
+	StoryId     graphqlgo.ID
+	Name        string
+	Description *string
+	Type        *string
+	Status      *string
+	AssigneeId  *graphqlgo.ID
+}
+
+func (r *resolver) CreateTask(ctx context.Context, args struct{ Input newTaskInput }) (*taskResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionTaskWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	assigneeId, err := parseUserId(args.Input.AssigneeId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	taskDto := dto.NewTaskDto{
+		Name:        args.Input.Name,
+		Description: valueOr(args.Input.Description, ""),
+		StoryId:     string(args.Input.StoryId),
+		AssigneeId:  assigneeId,
+	}
+	if args.Input.Type != nil {
+		taskDto.Type = enumOf(taskTypes, *args.Input.Type)
+	}
+
+	if args.Input.Status != nil {
+		taskDto.Status = enumOf(progressStatuses, *args.Input.Status)
+	}
+
+	if err := validate.Struct(taskDto); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := r.services.Tasks.CreateTask(user, entities.Task{
+		Name:        taskDto.Name,
+		Description: taskDto.Description,
+		Type:        taskDto.Type,
+		Status:      taskDto.Status,
+		StoryId:     taskDto.StoryId,
+		Creator:     user,
+		AssigneeId:  taskDto.AssigneeId,
+	})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &taskResolver{task: created}, nil
+}
+
+type taskChangesInput struct {
+	Name        *string
+	Description *string
+	Type        *string
+	Status      *string
+	StoryId     *graphqlgo.ID
+	AssigneeId  *graphqlgo.ID
+}
+
+func (r *resolver) UpdateTask(ctx context.Context, args struct {
+	Id    graphqlgo.ID
+	Input taskChangesInput
+}) (*taskResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionTaskWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := r.services.Tasks.GetTaskById(string(args.Id), user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	taskDto := dto.TaskPatchDto{
+		Name:        valueOr(args.Input.Name, task.Name),
+		Description: valueOr(args.Input.Description, task.Description),
+		Type:        task.Type,
+		Status:      task.Status,
+		StoryId:     task.StoryId,
+		AssigneeId:  task.AssigneeId,
+	}
+	if args.Input.Type != nil {
+		taskDto.Type = enumOf(taskTypes, *args.Input.Type)
+	}
+
+	if args.Input.Status != nil {
+		taskDto.Status = enumOf(progressStatuses, *args.Input.Status)
+	}
+
+	if args.Input.StoryId != nil {
+		taskDto.StoryId = string(*args.Input.StoryId)
+	}
+
+	if args.Input.AssigneeId != nil {
+		if taskDto.AssigneeId, err = parseUserId(args.Input.AssigneeId); err != nil {
+			return nil, failure(err)
+		}
+	}
+
+	if err := validate.Struct(taskDto); err != nil {
+		return nil, failure(err)
+	}
+
+	patched := *task
+	patched.Name = taskDto.Name
+	patched.Description = taskDto.Description
+	patched.Type = taskDto.Type
+	patched.Status = taskDto.Status
+	patched.StoryId = taskDto.StoryId
+	patched.AssigneeId = taskDto.AssigneeId
+
+	updated, err := r.services.Tasks.PatchTask(user, &patched)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &taskResolver{task: updated}, nil
+}
+
+func (r *resolver) CreateTag(ctx context.Context, args struct {
+	ProjectId graphqlgo.ID
+	Name      string
+}) (*tagResolver, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	tagDto := dto.NewTagDto{Name: args.Name}
+	if err := validate.Struct(tagDto); err != nil {
+		return nil, failure(err)
+	}
+
+	projectId := string(args.ProjectId)
+	if err := r.services.Projects.Authorize(projectId, user, enums.Contributor); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := r.services.Tags.CreateTag(entities.Tag{Name: tagDto.Name, ProjectId: projectId})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	l.clear()
+	return &tagResolver{tag: *created}, nil
+}
+
+func (r *resolver) DeleteTag(ctx context.Context, args struct {
+	ProjectId graphqlgo.ID
+	Id        graphqlgo.ID
+}) (graphqlgo.ID, error) {
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionProjectWrite)
+	if err != nil {
+		return "", err
+	}
+
+	tagId, err := parseTagId(args.Id)
+	if err != nil {
+		return "", failure(err)
+	}
+
+	projectId := string(args.ProjectId)
+	if err := r.services.Projects.Authorize(projectId, user, enums.Contributor); err != nil {
+		return "", failure(err)
+	}
+
+	if _, err := r.services.Tags.DeleteTag(tagId, projectId); err != nil {
+		return "", failure(err)
+	}
+
+	l.clear()
+	return args.Id, nil
+}
+
+func (r *resolver) AddTagToTask(ctx context.Context, args struct {
+	TaskId graphqlgo.ID
+	TagId  graphqlgo.ID
+}) (*taskResolver, error) {
+	return r.changeTaskTag(ctx, args.TaskId, args.TagId, func(tagId uint, taskId string) error {
+		return r.services.Tags.AddToTask(tagId, taskId)
+	})
+}
+
+func (r *resolver) RemoveTagFromTask(ctx context.Context, args struct {
+	TaskId graphqlgo.ID
+	TagId  graphqlgo.ID
+}) (*taskResolver, error) {
+	return r.changeTaskTag(ctx, args.TaskId, args.TagId, func(tagId uint, taskId string) error {
+		return r.services.Tags.RemoveFromTask(taskId, tagId)
+	})
+}
+
+// changeTaskTag adds or removes one of the tags of the task's project, returning the task
+func (r *resolver) changeTaskTag(
+	ctx context.Context,
+	taskIdValue, tagIdValue graphqlgo.ID,
+	change func(tagId uint, taskId string) error) (*taskResolver, error) {
+
+	l := loadersFrom(ctx)
+	user, err := authorize(l, auth.PermissionTaskWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	tagId, err := parseTagId(tagIdValue)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	task, err := r.services.Tasks.GetTaskById(string(taskIdValue), user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	if err := r.services.Tasks.Authorize(task.ID, user, enums.Contributor); err != nil {
+		return nil, failure(err)
+	}
+
+	// Only the tags of the task's own project may be added to it
+	story, err := r.services.Stories.GetStoryById(user, task.StoryId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	if _, err := r.services.Tags.GetTagById(tagId, story.ProjectId); err != nil {
+		return nil, failure(err)
+	}
+
+	if err := change(tagId, task.ID); err != nil {
+		return nil, failure(err)
+	}
+
+	// The task's tags are loaded afresh
+	l.clear()
+	return &taskResolver{task: task}, nil
+}
+
+func valueOr[T any](value *T, otherwise T) T {
+	if value == nil {
+		return otherwise
+	}
+
+	return *value
+}
+
+func parseTagId(id graphqlgo.ID) (uint, error) {
+	tagId, err := strconv.ParseUint(string(id), 10, 32)
+	if err != nil {
+		return 0, ehand.ErrorTagMalformedId
+	}
+
+	return uint(tagId), nil
+}
+
+// parseUserId parses the id of a user, if given; ids which are not numbers are of no user
+func parseUserId(id *graphqlgo.ID) (*uint, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	userId, err := strconv.ParseUint(string(*id), 10, 32)
+	if err != nil {
+		return nil, ehand.ErrorUserNotFound
+	}
+
+	value := uint(userId)
+	return &value, nil
+}
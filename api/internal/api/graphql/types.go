@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"context"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/repository/entities"
+	"strconv"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+)
+
+// The resolvers of the schema's object types; their relations are fetched by the request's loaders
+
+type projectResolver struct {
+	project *entities.Project
+}
+
+func (r *projectResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(r.project.ID)
+}
+
+func (r *projectResolver) Name() string {
+	return r.project.Name
+}
+
+func (r *projectResolver) Description() string {
+	return r.project.Description
+}
+
+func (r *projectResolver) Status() string {
+	return projectStatuses[r.project.Status]
+}
+
+func (r *projectResolver) Restricted() bool {
+	return r.project.Restricted
+}
+
+func (r *projectResolver) TeamId() *graphqlgo.ID {
+	if r.project.TeamId == nil {
+		return nil
+	}
+
+	id := graphqlgo.ID(*r.project.TeamId)
+	return &id
+}
+
+func (r *projectResolver) Creator(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, &r.project.CreatorId)
+}
+
+func (r *projectResolver) Stories(ctx context.Context) ([]*storyResolver, error) {
+	l := loadersFrom(ctx)
+
+	stories, _, err := l.projectStories.load(ctx, r.project.ID)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	resolvers := make([]*storyResolver, 0, len(stories))
+	for _, story := range stories {
+		l.stories.prime(story.ID, story)
+		resolvers = append(resolvers, &storyResolver{story: story})
+	}
+
+	return resolvers, nil
+}
+
+func (r *projectResolver) Tags(ctx context.Context) ([]*tagResolver, error) {
+	tags, _, err := loadersFrom(ctx).projectTags.load(ctx, r.project.ID)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return tagResolvers(tags), nil
+}
+
+type storyResolver struct {
+	story *entities.Story
+}
+
+func (r *storyResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(r.story.ID)
+}
+
+func (r *storyResolver) Name() string {
+	return r.story.Name
+}
+
+func (r *storyResolver) Description() string {
+	return r.story.Description
+}
+
+func (r *storyResolver) Status() string {
+	return progressStatuses[r.story.Status]
+}
+
+func (r *storyResolver) Project(ctx context.Context) (*projectResolver, error) {
+	project, found, err := loadersFrom(ctx).projects.load(ctx, r.story.ProjectId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	if !found {
+		return nil, failure(ehand.ErrorProjectNotFound)
+	}
+
+	return &projectResolver{project: project}, nil
+}
+
+func (r *storyResolver) Creator(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, &r.story.CreatorId)
+}
+
+func (r *storyResolver) Tasks(ctx context.Context) ([]*taskResolver, error) {
+	tasks, _, err := loadersFrom(ctx).storyTasks.load(ctx, r.story.ID)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	resolvers := make([]*taskResolver, 0, len(tasks))
+	for _, task := range tasks {
+		resolvers = append(resolvers, &taskResolver{task: task})
+	}
+
+	return resolvers, nil
+}
+
+type taskResolver struct {
+	task *entities.Task
+}
+
+func (r *taskResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(r.task.ID)
+}
+
+func (r *taskResolver) Name() string {
+	return r.task.Name
+}
+
+func (r *taskResolver) Description() string {
+	return r.task.Description
+}
+
+func (r *taskResolver) Type() string {
+	return taskTypes[r.task.Type]
+}
+
+func (r *taskResolver) Status() string {
+	return progressStatuses[r.task.Status]
+}
+
+func (r *taskResolver) Story(ctx context.Context) (*storyResolver, error) {
+	story, found, err := loadersFrom(ctx).stories.load(ctx, r.task.StoryId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	if !found {
+		return nil, failure(ehand.ErrorStoryNotFound)
+	}
+
+	return &storyResolver{story: story}, nil
+}
+
+func (r *taskResolver) Creator(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, &r.task.CreatorId)
+}
+
+func (r *taskResolver) Assignee(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, r.task.AssigneeId)
+}
+
+func (r *taskResolver) Tags(ctx context.Context) ([]*tagResolver, error) {
+	tags, _, err := loadersFrom(ctx).taskTags.load(ctx, r.task.ID)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return tagResolvers(tags), nil
+}
+
+type tagResolver struct {
+	tag entities.Tag
+}
+
+func tagResolvers(tags entities.TagList) []*tagResolver {
+	resolvers := make([]*tagResolver, 0, len(tags))
+	for _, tag := range tags {
+		resolvers = append(resolvers, &tagResolver{tag: tag})
+	}
+
+	return resolvers
+}
+
+func (r *tagResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(strconv.FormatUint(uint64(r.tag.ID), 10))
+}
+
+func (r *tagResolver) Name() string {
+	return r.tag.Name
+}
+
+type userResolver struct {
+	user *entities.User
+}
+
+// loadUser resolves the user, or null if there is none or they are no longer a member of the account
+func loadUser(ctx context.Context, userId *uint) (*userResolver, error) {
+	if userId == nil || *userId == 0 {
+		return nil, nil
+	}
+
+	user, found, err := loadersFrom(ctx).users.load(ctx, *userId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return &userResolver{user: user}, nil
+}
+
+func (r *userResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(strconv.FormatUint(uint64(r.user.ID), 10))
+}
+
+func (r *userResolver) Name() string {
+	return r.user.Name
+}
+
+func (r *userResolver) Handle() string {
+	return r.user.Handle
+}
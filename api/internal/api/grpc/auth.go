@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"strings"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// The metadata carrying the bearer token, as the Authorization header does for REST
+const authorizationKey = "authorization"
+
+// The prefix of the reflection services, which describe the API to anyone who may connect
+const reflectionPrefix = "/grpc.reflection."
+
+// authInterceptor authenticates every call, adding the user to its context as the REST API's
+// AuthenticateRequestMiddleware and RequireTwoFactorEnrollmentMiddleware do
+type authInterceptor struct {
+	log           ilog.StdLogger
+	authenticator Authenticator
+}
+
+func (i *authInterceptor) unary(
+	ctx context.Context,
+	req interface{},
+	info *grpcgo.UnaryServerInfo,
+	handler grpcgo.UnaryHandler) (interface{}, error) {
+
+	ctx, err := i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (i *authInterceptor) stream(
+	srv interface{},
+	ss grpcgo.ServerStream,
+	info *grpcgo.StreamServerInfo,
+	handler grpcgo.StreamHandler) error {
+
+	ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (i *authInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, reflectionPrefix) {
+		return ctx, nil
+	}
+
+	tokenValue := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationKey); len(values) > 0 {
+			tokenValue = values[0]
+		}
+	}
+
+	user, err := i.authenticator.Authenticate(tokenValue)
+	if err != nil {
+		return ctx, failure(err)
+	}
+
+	if err := i.authenticator.RequireTwoFactorEnrollment(*user); err != nil {
+		return ctx, failure(err)
+	}
+
+	return context.WithValue(ctx, entities.UserKey{}, *user), nil
+}
+
+// authenticatedStream a stream whose context carries the authenticated user
+type authenticatedStream struct {
+	grpcgo.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize returns the user of the call if their role grants the permission, as does the REST route
+// of the same operation
+func authorize(ctx context.Context, permission auth.Permission) (entities.User, error) {
+	user := ctx.Value(entities.UserKey{}).(entities.User)
+
+	if !auth.HasPermission(user.Role, permission) {
+		return user, failure(ehand.ErrorPermissionDenied)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"math"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The messages of the entities; the values of the enums are those of the REST API plus one, zero
+// being unspecified as protobuf requires
+
+func toProject(project *entities.Project) *godov1.Project {
+	return &godov1.Project{
+		Id:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Status:      godov1.ProjectStatus(project.Status + 1),
+		Restricted:  project.Restricted,
+		TeamId:      project.TeamId,
+		CreatorId:   uint32(project.CreatorId),
+		CreatedAt:   timestamppb.New(project.CreatedAt),
+		UpdatedAt:   timestamppb.New(project.UpdatedAt),
+	}
+}
+
+func toStory(story *entities.Story) *godov1.Story {
+	return &godov1.Story{
+		Id:          story.ID,
+		ProjectId:   story.ProjectId,
+		Name:        story.Name,
+		Description: story.Description,
+		Status:      godov1.ProgressStatus(story.Status + 1),
+		CreatorId:   uint32(story.CreatorId),
+		CreatedAt:   timestamppb.New(story.CreatedAt),
+		UpdatedAt:   timestamppb.New(story.UpdatedAt),
+	}
+}
+
+func toTask(task *entities.Task, tags entities.TagList) *godov1.Task {
+	message := &godov1.Task{
+		Id:          task.ID,
+		StoryId:     task.StoryId,
+		Name:        task.Name,
+		Description: task.Description,
+		Type:        godov1.TaskType(task.Type + 1),
+		Status:      godov1.ProgressStatus(task.Status + 1),
+		CreatorId:   uint32(task.CreatorId),
+		Tags:        toTags(tags),
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		UpdatedAt:   timestamppb.New(task.UpdatedAt),
+	}
+
+	if task.AssigneeId != nil {
+		message.AssigneeId = proto.Uint32(uint32(*task.AssigneeId))
+	}
+
+	return message
+}
+
+func toTag(tag entities.Tag) *godov1.Tag {
+	return &godov1.Tag{
+		Id:        uint32(tag.ID),
+		ProjectId: tag.ProjectId,
+		Name:      tag.Name,
+	}
+}
+
+func toTags(tags entities.TagList) []*godov1.Tag {
+	messages := make([]*godov1.Tag, 0, len(tags))
+	for _, tag := range tags {
+		messages = append(messages, toTag(tag))
+	}
+
+	return messages
+}
+
+// The enums of the requests; unspecified values, and those unknown to the server, are given as
+// values which fail validation
+
+func fromProjectStatus(status godov1.ProjectStatus) enums.ProjectStatus {
+	return fromEnum[enums.ProjectStatus](status)
+}
+
+func fromProgressStatus(status godov1.ProgressStatus) enums.ProgressStatus {
+	return fromEnum[enums.ProgressStatus](status)
+}
+
+func fromTaskType(taskType godov1.TaskType) enums.TaskType {
+	return fromEnum[enums.TaskType](taskType)
+}
+
+func fromEnum[T ~uint8, E ~int32](value E) T {
+	if value < 1 || value > math.MaxUint8 {
+		return math.MaxUint8
+	}
+
+	return T(value - 1)
+}
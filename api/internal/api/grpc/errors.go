@@ -0,0 +1,104 @@
+package grpc
+
+import (
+	"context"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/helper/ilog"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The domain of the ErrorInfo of every error, whose reason is the code of the REST API's problem
+const errorDomain = "godo"
+
+// The code of the status of each status of the REST API's problems
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusMethodNotAllowed:    codes.Unimplemented,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// failure returns the status of the error, described as it is by the REST API's problems
+//
+//	code = NotFound desc = the requested project could not be found
+//	details = [ErrorInfo{reason: "project_not_found", domain: "godo"}]
+func failure(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	problem := ehand.New().Problem(err)
+
+	code, ok := statusCodes[problem.Status]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, fieldErr := range problem.Errors {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Message,
+		})
+	}
+
+	s := status.New(code, problem.Detail)
+	info := &errdetails.ErrorInfo{Reason: problem.Code, Domain: errorDomain}
+
+	withDetails, detailsErr := s.WithDetails(info)
+	if len(violations) > 0 {
+		withDetails, detailsErr = s.WithDetails(info, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	if detailsErr != nil {
+		return s.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// recoverUnary and recoverStream log a panic within a call, responding only that it failed
+
+func recoverUnary(logger ilog.StdLogger) grpcgo.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpcgo.UnaryServerInfo,
+		handler grpcgo.UnaryHandler) (resp interface{}, err error) {
+
+		defer func() {
+			if value := recover(); value != nil {
+				logger.Errorf("Panic while serving %s: %v", info.FullMethod, value)
+				err = failure(ehand.ErrorInternal)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func recoverStream(logger ilog.StdLogger) grpcgo.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpcgo.ServerStream,
+		info *grpcgo.StreamServerInfo,
+		handler grpcgo.StreamHandler) (err error) {
+
+		defer func() {
+			if value := recover(); value != nil {
+				logger.Errorf("Panic while serving %s: %v", info.FullMethod, value)
+				err = failure(ehand.ErrorInternal)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,163 @@
+package grpc
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/helper/validate"
+	"godo/internal/repository/entities"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// projectServer serves the ProjectService. Each call checks the permission of the REST route of the
+// same operation, the services then scoping and authorizing it as they do for REST.
+type projectServer struct {
+	godov1.UnimplementedProjectServiceServer
+
+	log           ilog.StdLogger
+	services      Services
+	auth          *authInterceptor
+	watchInterval time.Duration
+}
+
+func (s *projectServer) ListProjects(ctx context.Context, req *godov1.ListProjectsRequest) (*godov1.ListProjectsResponse, error) {
+	user, err := authorize(ctx, auth.PermissionProjectRead)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.services.Projects.GetProjects(user, req.GetTeamId())
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	ids := make([]string, 0, len(info))
+	for _, project := range info {
+		ids = append(ids, project.ID)
+	}
+
+	projects, err := s.services.Projects.GetProjectsByIds(user, ids)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	byId := make(map[string]*entities.Project, len(projects))
+	for _, project := range projects {
+		byId[project.ID] = project
+	}
+
+	// In the order of GetProjects, most recently created first
+	res := &godov1.ListProjectsResponse{Projects: make([]*godov1.Project, 0, len(projects))}
+	for _, id := range ids {
+		if project, ok := byId[id]; ok {
+			res.Projects = append(res.Projects, toProject(project))
+		}
+	}
+
+	return res, nil
+}
+
+func (s *projectServer) GetProject(ctx context.Context, req *godov1.GetProjectRequest) (*godov1.Project, error) {
+	user, err := authorize(ctx, auth.PermissionProjectRead)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.services.Projects.GetProjectById(req.Id, user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toProject(project), nil
+}
+
+func (s *projectServer) CreateProject(ctx context.Context, req *godov1.CreateProjectRequest) (*godov1.Project, error) {
+	user, err := authorize(ctx, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	projectDto := dto.NewProjectDto{
+		Name:        req.Name,
+		Description: req.Description,
+		Restricted:  req.Restricted,
+	}
+	if err := validate.Struct(projectDto); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := s.services.Projects.CreateProject(&entities.Project{
+		Name:        projectDto.Name,
+		Description: projectDto.Description,
+		Restricted:  projectDto.Restricted,
+		Creator:     user,
+	})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toProject(created), nil
+}
+
+func (s *projectServer) UpdateProject(ctx context.Context, req *godov1.UpdateProjectRequest) (*godov1.Project, error) {
+	user, err := authorize(ctx, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.services.Projects.GetProjectById(req.Id, user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	// The changes are validated as a patch of the project would be
+	projectDto := dto.ProjectPatchDto{
+		Name:        valueOr(req.Name, project.Name),
+		Description: valueOr(req.Description, project.Description),
+		Status:      project.Status,
+	}
+	if req.Status != nil {
+		projectDto.Status = fromProjectStatus(*req.Status)
+	}
+
+	if err := validate.Struct(projectDto); err != nil {
+		return nil, failure(err)
+	}
+
+	patched := *project
+	patched.Name = projectDto.Name
+	patched.Description = projectDto.Description
+	patched.Status = projectDto.Status
+
+	updated, err := s.services.Projects.PatchProject(project.ID, user, &patched)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toProject(updated), nil
+}
+
+func (s *projectServer) DeleteProject(ctx context.Context, req *godov1.DeleteProjectRequest) (*emptypb.Empty, error) {
+	user, err := authorize(ctx, auth.PermissionProjectDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.Projects.DeleteProject(req.Id, user); err != nil {
+		return nil, failure(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func valueOr[T any](value *T, otherwise T) T {
+	if value == nil {
+		return otherwise
+	}
+
+	return *value
+}
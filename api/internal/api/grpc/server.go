@@ -0,0 +1,64 @@
+// Package grpc serves the projects, stories, tasks and tags visible to the user over gRPC, as described
+// by proto/godo/v1/godo.proto. It is served by the same services as the REST API, and so with the
+// same account scoping and authorization; calls are authenticated with the same JWT, given in the
+// authorization metadata.
+package grpc
+
+import (
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"time"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Services the services serving the API
+type Services struct {
+	Projects services.ProjectService
+	Stories  services.StoryService
+	Tasks    services.TaskService
+	Tags     services.TagService
+}
+
+// Authenticator authenticates the caller as the REST API does; see middleware.AuthMiddleware
+type Authenticator interface {
+	Authenticate(tokenValue string) (*entities.User, error)
+	RequireTwoFactorEnrollment(user entities.User) error
+}
+
+type Options struct {
+	// WatchInterval how often WatchProject looks for changes to the project
+	WatchInterval time.Duration
+
+	// Reflection whether the reflection service is served, describing the API to tools such as grpcurl
+	Reflection bool
+}
+
+// New returns the server of the API, to be served with Serve
+func New(svc Services, authenticator Authenticator, options Options, logger ilog.StdLogger) *grpcgo.Server {
+	interceptor := &authInterceptor{log: logger, authenticator: authenticator}
+
+	server := grpcgo.NewServer(
+		grpcgo.ChainUnaryInterceptor(recoverUnary(logger), interceptor.unary),
+		grpcgo.ChainStreamInterceptor(recoverStream(logger), interceptor.stream),
+	)
+
+	godov1.RegisterProjectServiceServer(server, &projectServer{
+		log:           logger,
+		services:      svc,
+		auth:          interceptor,
+		watchInterval: options.WatchInterval,
+	})
+	godov1.RegisterStoryServiceServer(server, &storyServer{log: logger, services: svc})
+	godov1.RegisterTaskServiceServer(server, &taskServer{log: logger, services: svc})
+	godov1.RegisterTagServiceServer(server, &tagServer{log: logger, services: svc})
+
+	if options.Reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/helper/validate"
+	"godo/internal/repository/entities"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// storyServer serves the StoryService, authorized as is the projectServer
+type storyServer struct {
+	godov1.UnimplementedStoryServiceServer
+
+	log      ilog.StdLogger
+	services Services
+}
+
+func (s *storyServer) ListStories(ctx context.Context, req *godov1.ListStoriesRequest) (*godov1.ListStoriesResponse, error) {
+	user, err := authorize(ctx, auth.PermissionStoryRead)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fails if the project is not visible to the user, rather than it having no stories
+	if _, err := s.services.Projects.GetProjectById(req.ProjectId, user); err != nil {
+		return nil, failure(err)
+	}
+
+	stories, err := s.services.Stories.GetStoriesByProjectIds(user, []string{req.ProjectId})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	res := &godov1.ListStoriesResponse{Stories: make([]*godov1.Story, 0, len(stories))}
+	for _, story := range stories {
+		res.Stories = append(res.Stories, toStory(story))
+	}
+
+	return res, nil
+}
+
+func (s *storyServer) GetStory(ctx context.Context, req *godov1.GetStoryRequest) (*godov1.Story, error) {
+	user, err := authorize(ctx, auth.PermissionStoryRead)
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := s.services.Stories.GetStoryById(user, req.Id)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toStory(story), nil
+}
+
+func (s *storyServer) CreateStory(ctx context.Context, req *godov1.CreateStoryRequest) (*godov1.Story, error) {
+	user, err := authorize(ctx, auth.PermissionStoryWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	storyDto := dto.NewStoryDto{
+		Name:        req.Name,
+		Description: req.Description,
+		ProjectId:   req.ProjectId,
+	}
+	if err := validate.Struct(storyDto); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := s.services.Stories.CreateStory(user, &entities.Story{
+		Name:        storyDto.Name,
+		Description: storyDto.Description,
+		ProjectId:   storyDto.ProjectId,
+		Creator:     user,
+	})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toStory(created), nil
+}
+
+func (s *storyServer) UpdateStory(ctx context.Context, req *godov1.UpdateStoryRequest) (*godov1.Story, error) {
+	user, err := authorize(ctx, auth.PermissionStoryWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := s.services.Stories.GetStoryById(user, req.Id)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	storyDto := dto.StoryPatchDto{
+		Name:        valueOr(req.Name, story.Name),
+		Description: valueOr(req.Description, story.Description),
+		ProjectId:   valueOr(req.ProjectId, story.ProjectId),
+	}
+	if err := validate.Struct(storyDto); err != nil {
+		return nil, failure(err)
+	}
+
+	patched := *story
+	patched.Name = storyDto.Name
+	patched.Description = storyDto.Description
+
+	// The service verifies that the story may be moved to the project
+	patched.ProjectId = storyDto.ProjectId
+
+	updated, err := s.services.Stories.PatchStory(user, &patched)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toStory(updated), nil
+}
+
+func (s *storyServer) DeleteStory(ctx context.Context, req *godov1.DeleteStoryRequest) (*emptypb.Empty, error) {
+	user, err := authorize(ctx, auth.PermissionStoryWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.Stories.DeleteStory(user, req.Id); err != nil {
+		return nil, failure(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/helper/validate"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// tagServer serves the TagService, authorized as is the projectServer
+type tagServer struct {
+	godov1.UnimplementedTagServiceServer
+
+	log      ilog.StdLogger
+	services Services
+}
+
+func (s *tagServer) ListTags(ctx context.Context, req *godov1.ListTagsRequest) (*godov1.ListTagsResponse, error) {
+	user, err := authorize(ctx, auth.PermissionProjectRead)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.services.Projects.GetProjectById(req.ProjectId, user); err != nil {
+		return nil, failure(err)
+	}
+
+	tags, err := s.services.Tags.GetTagsByProjectIds([]string{req.ProjectId})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return &godov1.ListTagsResponse{Tags: toTags(tags)}, nil
+}
+
+func (s *tagServer) CreateTag(ctx context.Context, req *godov1.CreateTagRequest) (*godov1.Tag, error) {
+	user, err := authorize(ctx, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	tagDto := dto.NewTagDto{Name: req.Name}
+	if err := validate.Struct(tagDto); err != nil {
+		return nil, failure(err)
+	}
+
+	if err := s.services.Projects.Authorize(req.ProjectId, user, enums.Contributor); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := s.services.Tags.CreateTag(entities.Tag{Name: tagDto.Name, ProjectId: req.ProjectId})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toTag(*created), nil
+}
+
+func (s *tagServer) DeleteTag(ctx context.Context, req *godov1.DeleteTagRequest) (*emptypb.Empty, error) {
+	user, err := authorize(ctx, auth.PermissionProjectWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.Projects.Authorize(req.ProjectId, user, enums.Contributor); err != nil {
+		return nil, failure(err)
+	}
+
+	if _, err := s.services.Tags.DeleteTag(uint(req.Id), req.ProjectId); err != nil {
+		return nil, failure(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tagServer) AddTagToTask(ctx context.Context, req *godov1.TaskTagRequest) (*godov1.Task, error) {
+	return s.changeTaskTag(ctx, req, func(tagId uint, taskId string) error {
+		return s.services.Tags.AddToTask(tagId, taskId)
+	})
+}
+
+func (s *tagServer) RemoveTagFromTask(ctx context.Context, req *godov1.TaskTagRequest) (*godov1.Task, error) {
+	return s.changeTaskTag(ctx, req, func(tagId uint, taskId string) error {
+		return s.services.Tags.RemoveFromTask(taskId, tagId)
+	})
+}
+
+// changeTaskTag adds or removes one of the tags of the task's project, returning the task
+func (s *tagServer) changeTaskTag(
+	ctx context.Context,
+	req *godov1.TaskTagRequest,
+	change func(tagId uint, taskId string) error) (*godov1.Task, error) {
+
+	user, err := authorize(ctx, auth.PermissionTaskWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.services.Tasks.GetTaskById(req.TaskId, user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	if err := s.services.Tasks.Authorize(task.ID, user, enums.Contributor); err != nil {
+		return nil, failure(err)
+	}
+
+	// Only the tags of the task's own project may be added to it
+	story, err := s.services.Stories.GetStoryById(user, task.StoryId)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	tagId := uint(req.TagId)
+	if _, err := s.services.Tags.GetTagById(tagId, story.ProjectId); err != nil {
+		return nil, failure(err)
+	}
+
+	if err := change(tagId, task.ID); err != nil {
+		return nil, failure(err)
+	}
+
+	message, err := taskMessage(s.services, task)
+	return message, failure(err)
+}
@@ -0,0 +1,189 @@
+package grpc
+
+import (
+	"context"
+	"godo/internal/api/dto"
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/helper/validate"
+	"godo/internal/repository/entities"
+)
+
+// taskServer serves the TaskService, authorized as is the projectServer
+type taskServer struct {
+	godov1.UnimplementedTaskServiceServer
+
+	log      ilog.StdLogger
+	services Services
+}
+
+func (s *taskServer) ListTasks(ctx context.Context, req *godov1.ListTasksRequest) (*godov1.ListTasksResponse, error) {
+	user, err := authorize(ctx, auth.PermissionTaskRead)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fails if the story is not visible to the user, rather than it having no tasks
+	if _, err := s.services.Stories.GetStoryById(user, req.StoryId); err != nil {
+		return nil, failure(err)
+	}
+
+	tasks, err := s.services.Tasks.GetTasksByStoryIds(user, []string{req.StoryId})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	messages, err := taskMessages(s.services, tasks)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return &godov1.ListTasksResponse{Tasks: messages}, nil
+}
+
+func (s *taskServer) GetTask(ctx context.Context, req *godov1.GetTaskRequest) (*godov1.Task, error) {
+	user, err := authorize(ctx, auth.PermissionTaskRead)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.services.Tasks.GetTaskById(req.Id, user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	message, err := taskMessage(s.services, task)
+	return message, failure(err)
+}
+
+func (s *taskServer) CreateTask(ctx context.Context, req *godov1.CreateTaskRequest) (*godov1.Task, error) {
+	user, err := authorize(ctx, auth.PermissionTaskWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	taskDto := dto.NewTaskDto{
+		Name:        req.Name,
+		Description: req.Description,
+		StoryId:     req.StoryId,
+		AssigneeId:  assigneeId(req.AssigneeId),
+	}
+	if req.Type != godov1.TaskType_TASK_TYPE_UNSPECIFIED {
+		taskDto.Type = fromTaskType(req.Type)
+	}
+
+	if req.Status != godov1.ProgressStatus_PROGRESS_STATUS_UNSPECIFIED {
+		taskDto.Status = fromProgressStatus(req.Status)
+	}
+
+	if err := validate.Struct(taskDto); err != nil {
+		return nil, failure(err)
+	}
+
+	created, err := s.services.Tasks.CreateTask(user, entities.Task{
+		Name:        taskDto.Name,
+		Description: taskDto.Description,
+		Type:        taskDto.Type,
+		Status:      taskDto.Status,
+		StoryId:     taskDto.StoryId,
+		Creator:     user,
+		AssigneeId:  taskDto.AssigneeId,
+	})
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	return toTask(created, nil), nil
+}
+
+func (s *taskServer) UpdateTask(ctx context.Context, req *godov1.UpdateTaskRequest) (*godov1.Task, error) {
+	user, err := authorize(ctx, auth.PermissionTaskWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.services.Tasks.GetTaskById(req.Id, user)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	taskDto := dto.TaskPatchDto{
+		Name:        valueOr(req.Name, task.Name),
+		Description: valueOr(req.Description, task.Description),
+		Type:        task.Type,
+		Status:      task.Status,
+		StoryId:     valueOr(req.StoryId, task.StoryId),
+		AssigneeId:  task.AssigneeId,
+	}
+	if req.Type != nil {
+		taskDto.Type = fromTaskType(*req.Type)
+	}
+
+	if req.Status != nil {
+		taskDto.Status = fromProgressStatus(*req.Status)
+	}
+
+	if req.AssigneeId != nil {
+		taskDto.AssigneeId = assigneeId(req.AssigneeId)
+	}
+
+	if err := validate.Struct(taskDto); err != nil {
+		return nil, failure(err)
+	}
+
+	patched := *task
+	patched.Name = taskDto.Name
+	patched.Description = taskDto.Description
+	patched.Type = taskDto.Type
+	patched.Status = taskDto.Status
+	patched.StoryId = taskDto.StoryId
+	patched.AssigneeId = taskDto.AssigneeId
+
+	updated, err := s.services.Tasks.PatchTask(user, &patched)
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	message, err := taskMessage(s.services, updated)
+	return message, failure(err)
+}
+
+// assigneeId the user the task is assigned to, if any; 0 is no one
+func assigneeId(id *uint32) *uint {
+	if id == nil || *id == 0 {
+		return nil
+	}
+
+	value := uint(*id)
+	return &value
+}
+
+// taskMessage returns the message of the task with its tags, which are not loaded with it
+func taskMessage(svc Services, task *entities.Task) (*godov1.Task, error) {
+	tags, err := svc.Tags.GetTagsByTaskIds([]string{task.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return toTask(task, tags[task.ID]), nil
+}
+
+func taskMessages(svc Services, tasks entities.TaskList) ([]*godov1.Task, error) {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	tags, err := svc.Tags.GetTagsByTaskIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*godov1.Task, 0, len(tasks))
+	for _, task := range tasks {
+		messages = append(messages, toTask(task, tags[task.ID]))
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/grpc/godov1"
+	"godo/internal/auth"
+	"godo/internal/repository/entities"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// WatchProject looks for changes to the project every watchInterval, streaming an event for each
+// entity which was created, updated or deleted since it last looked. Changes are found by comparing
+// what the user sees of the project, so are seen however they were made, by any instance of the API.
+// The caller is authenticated afresh each time, such that the stream ends once their token expires or
+// their session is revoked.
+func (s *projectServer) WatchProject(req *godov1.WatchProjectRequest, stream godov1.ProjectService_WatchProjectServer) error {
+	user, err := authorize(stream.Context(), auth.PermissionProjectRead)
+	if err != nil {
+		return err
+	}
+
+	seen, err := s.snapshot(user, req.ProjectId)
+	if err != nil {
+		return failure(err)
+	}
+
+	s.log.Infof("User{id=%d} is watching Project{id=%s}", user.ID, req.ProjectId)
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		ctx, err := s.auth.authenticate(stream.Context(), "")
+		if err != nil {
+			return err
+		}
+
+		if user, err = authorize(ctx, auth.PermissionProjectRead); err != nil {
+			return err
+		}
+
+		current, err := s.snapshot(user, req.ProjectId)
+
+		// The project was deleted, or is no longer visible to the user
+		if errors.Is(err, ehand.ErrorProjectNotFound) {
+			return stream.Send(seen.event(projectKey, godov1.ProjectEvent_TYPE_DELETED))
+		}
+
+		if err != nil {
+			return failure(err)
+		}
+
+		for _, event := range seen.changes(current) {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+
+		seen = current
+	}
+}
+
+// The key of the project itself within its snapshot
+const projectKey = "project"
+
+// projectSnapshot what the user sees of a project, being the event which would carry each of its
+// entities, keyed by their type and id
+type projectSnapshot struct {
+	keys   []string // In the order their events are sent; the project, stories, tasks, then tags
+	events map[string]*godov1.ProjectEvent
+}
+
+func (s *projectServer) snapshot(user entities.User, projectId string) (*projectSnapshot, error) {
+	project, err := s.services.Projects.GetProjectById(projectId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	stories, err := s.services.Stories.GetStoriesByProjectIds(user, []string{project.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	storyIds := make([]string, 0, len(stories))
+	for _, story := range stories {
+		storyIds = append(storyIds, story.ID)
+	}
+
+	tasks, err := s.services.Tasks.GetTasksByStoryIds(user, storyIds)
+	if err != nil {
+		return nil, err
+	}
+
+	taskList, err := taskMessages(s.services, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.services.Tags.GetTagsByProjectIds([]string{project.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &projectSnapshot{events: make(map[string]*godov1.ProjectEvent)}
+	snapshot.add(projectKey, &godov1.ProjectEvent{Entity: &godov1.ProjectEvent_Project{Project: toProject(project)}})
+
+	for _, story := range stories {
+		snapshot.add("story:"+story.ID, &godov1.ProjectEvent{Entity: &godov1.ProjectEvent_Story{Story: toStory(story)}})
+	}
+
+	for _, task := range taskList {
+		snapshot.add("task:"+task.Id, &godov1.ProjectEvent{Entity: &godov1.ProjectEvent_Task{Task: task}})
+	}
+
+	for _, tag := range tags {
+		snapshot.add(fmt.Sprintf("tag:%d", tag.ID), &godov1.ProjectEvent{Entity: &godov1.ProjectEvent_Tag{Tag: toTag(tag)}})
+	}
+
+	return snapshot, nil
+}
+
+func (p *projectSnapshot) add(key string, event *godov1.ProjectEvent) {
+	p.keys = append(p.keys, key)
+	p.events[key] = event
+}
+
+// event returns the event of the entity with the given type
+func (p *projectSnapshot) event(key string, eventType godov1.ProjectEvent_Type) *godov1.ProjectEvent {
+	event := proto.Clone(p.events[key]).(*godov1.ProjectEvent)
+	event.Type = eventType
+
+	return event
+}
+
+// changes returns the events which change the snapshot into the next
+func (p *projectSnapshot) changes(next *projectSnapshot) []*godov1.ProjectEvent {
+	var events []*godov1.ProjectEvent
+
+	for _, key := range next.keys {
+		previous, ok := p.events[key]
+		if !ok {
+			events = append(events, next.event(key, godov1.ProjectEvent_TYPE_CREATED))
+		} else if !proto.Equal(previous, next.events[key]) {
+			events = append(events, next.event(key, godov1.ProjectEvent_TYPE_UPDATED))
+		}
+	}
+
+	for _, key := range p.keys {
+		if _, ok := next.events[key]; !ok {
+			events = append(events, p.event(key, godov1.ProjectEvent_TYPE_DELETED))
+		}
+	}
+
+	return events
+}
@@ -45,52 +45,174 @@ func (a *Accounts) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	accountExists, err := a.accountService.AccountWithEmailAddressExists(accountDto.UserEmail)
 	if err != nil {
 		a.log.Error("Issue checking if account exists: ", err)
-		api.ReturnError(ehand.ErrorAccountNotCreated, http.StatusInternalServerError, w)
+		a.eh.HandleApiError(w, ehand.ErrorAccountNotCreated)
 		return
 	}
 
 	userExists, err := a.userService.UserWithEmailAddressExists(accountDto.UserEmail)
 	if err != nil {
 		a.log.Error("Issue checking if user exists: ", err)
-		api.ReturnError(ehand.ErrorAccountNotCreated, http.StatusInternalServerError, w)
+		a.eh.HandleApiError(w, ehand.ErrorAccountNotCreated)
 		return
 	}
 
 	if accountExists {
-		api.ReturnError(ehand.ErrorAccountAlreadyExists, http.StatusBadRequest, w)
+		a.eh.HandleApiError(w, ehand.ErrorAccountAlreadyExists)
 		return
 	}
 
 	if userExists {
-		api.ReturnError(ehand.ErrorUserAlreadyExists, http.StatusBadRequest, w)
+		a.eh.HandleApiError(w, ehand.ErrorUserAlreadyExists)
 		return
 	}
 
-	// Create the account
+	// Create the account along with the user owning it
 	newAccount := entities.Account{
 		Name:  accountDto.Name,
 		Email: accountDto.UserEmail,
 	}
 
-	createdAccount, err := a.accountService.CreateAccount(&newAccount)
+	newUser := entities.User{
+		Name:     accountDto.UserName,
+		Email:    accountDto.UserEmail,
+		Username: accountDto.UserUsername,
+		Password: accountDto.Password,
+	}
+
+	_, err = a.userService.CreateAccountOwner(&newAccount, newUser)
 	if status := a.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	// Create the user
-	newUser := entities.User{
-		Name:      accountDto.UserName,
-		Email:     accountDto.UserEmail,
-		Username:  accountDto.UserUsername,
-		Password:  accountDto.Password,
-		AccountId: createdAccount.ID,
+	api.LinkAccount(r.Context(), &newAccount)
+	api.NewResponse(w).Created(newAccount.Links.Self).Send(&newAccount)
+}
+
+// swagger:route PUT /account/2fa Accounts updateAccountTwoFactor
+//
+// Sets whether every user in the authenticated account must use two-factor authentication
+// responses:
+//  204: noContent
+//  400: errorResponse
+//  403: errorResponse
+//  500: errorResponse
+func (a *Accounts) UpdateTwoFactorRequirement(w http.ResponseWriter, r *http.Request) {
+	requirementDto, err := getDtoFromBody[dto.TwoFactorRequirementDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	err = a.accountService.SetTwoFactorRequired(user.AccountId, requirementDto.Required)
+	if status := a.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
 	}
 
-	_, err = a.userService.CreateUser(newUser)
+	api.NoContent(w)
+}
+
+// swagger:route GET /account Accounts getAccount
+//
+// Returns the authenticated account
+//
+// responses:
+//  200: accountResponse
+//  304: notModified
+//  404: errorResponse
+func (a *Accounts) GetAccount(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	account, err := a.accountService.GetAccountById(user.AccountId)
 	if status := a.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	// TODO: Create a RespondWithPointer method to point to the newly created resource
-	api.Respond(createdAccount, http.StatusCreated, w)
+	api.LinkAccount(r.Context(), account)
+	api.Respond(account, http.StatusOK, w)
+}
+
+// swagger:route PATCH /account Accounts updateAccount
+//
+// Updates the name, contact email and settings of the authenticated account
+//
+// responses:
+//  200: accountResponse
+//  400: errorResponse
+//  403: errorResponse
+//  500: errorResponse
+func (a *Accounts) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	accountDto, err := getDtoFromBody[dto.UpdateAccountDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	account, err := a.accountService.UpdateAccount(user.AccountId, accountDto.Name, accountDto.Email, accountDto.TimeZone)
+	if status := a.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkAccount(r.Context(), account)
+	api.Respond(account, http.StatusOK, w)
+}
+
+// swagger:route POST /account/deletion Accounts scheduleAccountDeletion
+//
+// Schedules the authenticated account, and every project, story, task, tag and user in it,
+// to be erased once the grace period has passed
+//
+// responses:
+//  200: accountResponse
+//  400: errorResponse
+//  403: errorResponse
+//  500: errorResponse
+func (a *Accounts) ScheduleDeletion(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	account, err := a.accountService.ScheduleDeletion(user.AccountId)
+	if status := a.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkAccount(r.Context(), account)
+	api.Respond(account, http.StatusOK, w)
+}
+
+// swagger:route DELETE /account/deletion Accounts cancelAccountDeletion
+//
+// Cancels the scheduled deletion of the authenticated account
+//
+// responses:
+//  200: accountResponse
+//  400: errorResponse
+//  403: errorResponse
+//  500: errorResponse
+func (a *Accounts) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	account, err := a.accountService.CancelDeletion(user.AccountId)
+	if status := a.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkAccount(r.Context(), account)
+	api.Respond(account, http.StatusOK, w)
+}
+
+// swagger:parameters updateAccount
+type UpdateAccountParameter struct {
+	// The fields of the account to be updated
+	// in: body
+	// required: true
+	Body dto.UpdateAccountDto
+}
+
+// swagger:parameters updateAccountTwoFactor
+type TwoFactorRequirementParameter struct {
+	// Whether two-factor authentication is required
+	// in: body
+	// required: true
+	Body dto.TwoFactorRequirementDto
 }
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/graphql"
+	"godo/internal/helper/ilog"
+	"net/http"
+)
+
+type GraphQL struct {
+	log ilog.StdLogger
+	api *graphql.API
+	eh  ehand.ErrorHandler
+}
+
+func NewGraphQLHandler(logger ilog.StdLogger, graphqlApi *graphql.API) GraphQL {
+	return GraphQL{
+		log: logger,
+		api: graphqlApi,
+		eh:  ehand.New(),
+	}
+}
+
+// Query executes the GraphQL query posted as {"query": ..., "operationName": ..., "variables": ...}.
+// As is usual for GraphQL, errors in resolving the query are described by the errors of a 200 response;
+// only a malformed request body is responded to with a problem.
+func (g *GraphQL) Query(w http.ResponseWriter, r *http.Request) {
+	req, err := getDtoFromBody[graphql.Request](w, r)
+	if err != nil {
+		return
+	}
+
+	response := g.api.Exec(r.Context(), *req)
+	api.Respond(response, http.StatusOK, w)
+}
@@ -8,25 +8,33 @@
 //
 //     Schemes: http
 //     Host: localhost
-//     BasePath: /api
+//     BasePath: /api/v1
 //     Version: 1.0.0
 //     Contact: Mike Murphy<michael.w.murphy@outlook.com> https://github.com/thisisthemurph
 //
 //     Consumes:
 //     - application/json
+//     - application/yaml
+//     - application/msgpack
 //
 //     Produces:
 //     - application/json
+//     - application/problem+json
+//     - application/yaml
+//     - application/msgpack
+//     - text/csv
 //
 // swagger:meta
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
 	"godo/internal/helper/validate"
 	"godo/internal/repository/entities"
 	"io"
@@ -35,10 +43,16 @@ import (
 	"strconv"
 	"strings"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/golang/gddo/httputil/header"
 	"github.com/gorilla/mux"
 )
 
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
 // Fetches the given parameter from the HTTP request object
 // Returns a string value and a success boolean
 func getParamFomRequest(r *http.Request, param string) (string, bool) {
@@ -75,29 +89,21 @@ func getUserFromContext(ctx context.Context) entities.User {
 	return getStructFromContext[entities.User](ctx, entities.UserKey{})
 }
 
-type malformedRequest struct {
-	status int
-	err    error
-}
-
-func (mr *malformedRequest) Error() string {
-	return mr.err.Error()
-}
-
-// Attempts to decode the JSON body, returning a custom malformed request error type.
+// Attempts to decode the body, in any of the formats of its Content-Type which are transcoded to JSON (see
+// api.Format), returning a request error describing why the body is malformed.
 // https://www.alexedwards.net/blog/how-to-properly-parse-a-json-request-body
-func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
-	if r.Header.Get("Content-Type") != "" {
-		value, _ := header.ParseValueAndParams(r.Header, "Content-Type")
-		if value != "application/json" {
-			msg := "Content-Type header is not application/json"
-			return &malformedRequest{status: http.StatusUnsupportedMediaType, err: errors.New(msg)}
-		}
+func decodeBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	body, err := api.ReadBody(w, r)
+	if err != nil {
+		return err
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, 1048576)
+	return decodeJSON(bytes.NewReader(body), dst)
+}
 
-	dec := json.NewDecoder(r.Body)
+// Decodes the single JSON object read from the body, returning a request error describing why it is malformed
+func decodeJSON(body io.Reader, dst interface{}) error {
+	dec := json.NewDecoder(body)
 	dec.DisallowUnknownFields()
 
 	err := dec.Decode(&dst)
@@ -108,28 +114,28 @@ func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 		switch {
 		case errors.As(err, &syntaxError):
 			errMsg := fmt.Errorf("request body contains badly-formed JSON (at position %d)", syntaxError.Offset)
-			return &malformedRequest{status: http.StatusBadRequest, err: errMsg}
+			return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, errMsg)
 
 		case errors.Is(err, io.ErrUnexpectedEOF):
 			errMsg := fmt.Errorf("request body contains badly-formed JSON")
-			return &malformedRequest{status: http.StatusBadRequest, err: errMsg}
+			return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, errMsg)
 
 		case errors.As(err, &unmarshalTypeError):
 			errMsg := fmt.Errorf("request body contains an invalid value for the %q field (at position %d)", unmarshalTypeError.Field, unmarshalTypeError.Offset)
-			return &malformedRequest{status: http.StatusBadRequest, err: errMsg}
+			return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeInvalidFieldType, errMsg)
 
 		case strings.HasPrefix(err.Error(), "json: unknown field "):
 			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
 			errMsg := fmt.Errorf("request body contains unknown field %s", fieldName)
-			return &malformedRequest{status: http.StatusBadRequest, err: errMsg}
+			return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeUnknownField, errMsg)
 
 		case errors.Is(err, io.EOF):
 			errMsg := errors.New("request body must not be empty")
-			return &malformedRequest{status: http.StatusBadRequest, err: errMsg}
+			return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeEmptyBody, errMsg)
 
 		case err.Error() == "http: request body too large":
 			errMsg := errors.New("request body must not be larger than 1MB")
-			return &malformedRequest{status: http.StatusRequestEntityTooLarge, err: errMsg}
+			return ehand.NewRequestError(http.StatusRequestEntityTooLarge, ehand.CodeBodyTooLarge, errMsg)
 
 		default:
 			return err
@@ -139,41 +145,99 @@ func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 	err = dec.Decode(&struct{}{})
 	if err != io.EOF {
 		msg := "request body must only contain a single JSON object"
-		return &malformedRequest{status: http.StatusBadRequest, err: errors.New(msg)}
+		return ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, errors.New(msg))
 	}
 
 	return nil
 }
 
-func handleMalformedJSONError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
+func getDtoFromBody[T any](w http.ResponseWriter, r *http.Request) (*T, error) {
+	var obj T
+	err := decodeBody(w, r, &obj)
+	if err != nil {
+		ehand.New().HandleApiError(w, err)
+		return nil, err
 	}
 
-	var mr *malformedRequest
-	if errors.As(err, &mr) {
-		api.ReturnError(mr.err, mr.status, w)
-	} else {
-		api.ReturnError(errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError, w)
+	err = validate.Struct(obj)
+	if err != nil {
+		log.Println("Body validation error: ", err.Error())
+		ehand.New().HandleApiError(w, err)
+		return nil, err
 	}
+
+	return &obj, nil
 }
 
-func getDtoFromJSONBody[T any](w http.ResponseWriter, r *http.Request) (*T, error) {
-	var obj T
-	err := decodeJSONBody(w, r, &obj)
+// Applies the merge patch (RFC 7396) or JSON patch (RFC 6902) in the request body to the given document,
+// chosen by the Content-Type header, returning the patched and validated document
+func getPatchedFromBody[T any](w http.ResponseWriter, r *http.Request, doc T) (*T, error) {
+	patched, err := applyPatch(w, r, doc)
 	if err != nil {
-		handleMalformedJSONError(w, err)
+		ehand.New().HandleApiError(w, err)
 		return nil, err
 	}
 
-	err = validate.Struct(obj)
+	err = validate.Struct(patched)
 	if err != nil {
-		log.Println("JSON Body validation error: ", err.Error())
-		api.ReturnError(err, http.StatusBadRequest, w)
+		log.Println("Patched document validation error: ", err.Error())
+		ehand.New().HandleApiError(w, err)
 		return nil, err
 	}
 
-	return &obj, nil
+	return patched, nil
+}
+
+func applyPatch[T any](w http.ResponseWriter, r *http.Request, doc T) (*T, error) {
+	contentType, _ := header.ParseValueAndParams(r.Header, "Content-Type")
+	if contentType != mergePatchContentType && contentType != jsonPatchContentType {
+		msg := fmt.Sprintf("Content-Type header must be %s or %s", mergePatchContentType, jsonPatchContentType)
+		w.Header().Set("Accept-Patch", mergePatchContentType+", "+jsonPatchContentType)
+		return nil, ehand.NewRequestError(http.StatusUnsupportedMediaType, ehand.CodeUnsupportedMediaType, errors.New(msg))
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1048576))
+	if err != nil {
+		errMsg := errors.New("request body must not be larger than 1MB")
+		return nil, ehand.NewRequestError(http.StatusRequestEntityTooLarge, ehand.CodeBodyTooLarge, errMsg)
+	}
+
+	if len(bytes.TrimSpace(patch)) == 0 {
+		errMsg := errors.New("request body must not be empty")
+		return nil, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeEmptyBody, errMsg)
+	}
+
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	if contentType == mergePatchContentType {
+		result, err = jsonpatch.MergePatch(original, patch)
+	} else {
+		var ops jsonpatch.Patch
+		if ops, err = jsonpatch.DecodePatch(patch); err == nil {
+			result, err = ops.Apply(original)
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			errMsg := errors.New("a test operation of the patch failed")
+			return nil, ehand.NewRequestError(http.StatusConflict, ehand.CodePatchTestFailed, errMsg)
+		}
+
+		errMsg := fmt.Errorf("the patch could not be applied: %w", err)
+		return nil, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeInvalidPatch, errMsg)
+	}
+
+	var patched T
+	if err := decodeJSON(bytes.NewReader(result), &patched); err != nil {
+		return nil, err
+	}
+
+	return &patched, nil
 }
 
 // Generic Swagger documentation
@@ -181,3 +245,14 @@ func getDtoFromJSONBody[T any](w http.ResponseWriter, r *http.Request) (*T, erro
 // NoContentResponse a response containing no content
 // swagger:response noContent
 type NoContentResponse struct{}
+
+// NotModifiedResponse the response to a conditional request when the client has the current representation
+// swagger:response notModified
+type NotModifiedResponse struct {
+	// The entity tag of the current representation, to be sent in If-None-Match
+	ETag string
+
+	// When the current representation was last modified, to be sent in If-Modified-Since; only given by
+	// the project, story and task endpoints
+	LastModified string `json:"Last-Modified"`
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"godo/internal/api"
+	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"net/http"
+)
+
+type Invitations struct {
+	log               ilog.StdLogger
+	invitationService services.InvitationService
+	eh                ehand.ErrorHandler
+}
+
+func NewInvitationsHandler(logger ilog.StdLogger, invitationService services.InvitationService) Invitations {
+	return Invitations{
+		log:               logger,
+		invitationService: invitationService,
+		eh:                ehand.New(),
+	}
+}
+
+// swagger:route POST /account/invite Invitations createInvitation
+//
+// # Invites the owner of the given email address to join the authenticated account
+//
+// responses:
+//
+//	201: invitationResponse
+//	400: errorResponse
+//	403: errorResponse
+//	500: errorResponse
+func (i *Invitations) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	invitationDto, err := getDtoFromBody[dto.NewInvitationDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	created, err := i.invitationService.CreateInvitation(user, invitationDto.Email, invitationDto.Role)
+	if status := i.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(created, http.StatusCreated, w)
+}
+
+// swagger:route GET /account/invite Invitations listInvitations
+//
+// # Returns the invitations made for the authenticated account
+//
+// responses:
+//
+//	200: invitationListResponse
+//	304: notModified
+//	403: errorResponse
+//	500: errorResponse
+func (i *Invitations) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	invitations, err := i.invitationService.GetInvitations(user.AccountId)
+	if err != nil {
+		i.eh.HandleApiError(w, err)
+		return
+	}
+
+	api.Respond(invitations, http.StatusOK, w)
+}
+
+// swagger:route POST /account/invite/{invitationId}/resend Invitations resendInvitation
+//
+// # Sends the invitation again with a new token and expiry, invalidating the previous token
+//
+// responses:
+//
+//	200: invitationResponse
+//	400: errorResponse
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (i *Invitations) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	invitationId, _ := getParamFomRequest(r, "id")
+
+	invitation, err := i.invitationService.ResendInvitation(invitationId, user.AccountId)
+	if status := i.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(invitation, http.StatusOK, w)
+}
+
+// swagger:route DELETE /account/invite/{invitationId} Invitations revokeInvitation
+//
+// # Revokes the pending invitation so that it can no longer be accepted
+//
+// responses:
+//
+//	204: noContent
+//	400: errorResponse
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (i *Invitations) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	invitationId, _ := getParamFomRequest(r, "id")
+
+	err := i.invitationService.RevokeInvitation(invitationId, user.AccountId)
+	if status := i.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.NoContent(w)
+}
+
+// Generic Swagger documentation
+
+// swagger:parameters resendInvitation revokeInvitation
+type InvitationUUIDParameter struct {
+	// The ID of the specified Invitation
+	// in: path
+	// required: true
+	// pattern: ^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$
+	// example: f9d633f8-c684-4dc3-b410-d36df912c4c1
+	ID string `json:"invitationId"`
+}
+
+// swagger:parameters createInvitation
+type NewInvitationParameter struct {
+	// The email address and role of the user to be invited
+	// in: body
+	// required: true
+	Body dto.NewInvitationDto
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"net/http"
+)
+
+type Keys struct {
+	log               ilog.StdLogger
+	signingKeyService services.SigningKeyService
+	eh                ehand.ErrorHandler
+}
+
+func NewKeysHandler(logger ilog.StdLogger, signingKeyService services.SigningKeyService) Keys {
+	return Keys{
+		log:               logger,
+		signingKeyService: signingKeyService,
+		eh:                ehand.New(),
+	}
+}
+
+// GetJWKS publishes the public keys used to verify our JWTs at /.well-known/jwks.json
+func (k *Keys) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := k.signingKeyService.JWKS()
+	if err != nil {
+		k.log.Error("Could not load the JWKS: ", err)
+		k.eh.HandleApiError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	api.Respond(jwks, http.StatusOK, w)
+}
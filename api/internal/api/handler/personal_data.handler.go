@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"godo/internal/api"
+	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"net/http"
+)
+
+type PersonalData struct {
+	log                 ilog.StdLogger
+	personalDataService services.PersonalDataService
+	eh                  ehand.ErrorHandler
+}
+
+func NewPersonalDataHandler(logger ilog.StdLogger, personalDataService services.PersonalDataService) PersonalData {
+	return PersonalData{
+		log:                 logger,
+		personalDataService: personalDataService,
+		eh:                  ehand.New(),
+	}
+}
+
+// swagger:route GET /me/export Users exportMe
+//
+// Returns a downloadable archive of everything stored about the authenticated user: their profile,
+// memberships, the projects, stories and tasks they created or were assigned, invitations and recovery codes
+//
+// produces:
+// - application/json
+//
+// responses:
+//
+//	200: personalDataResponse
+//	304: notModified
+//	500: errorResponse
+func (p *PersonalData) ExportMe(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	data, err := p.personalDataService.ExportPersonalData(user)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	filename := fmt.Sprintf("godo-personal-data-%d.json", user.ID)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	api.Respond(data, http.StatusOK, w)
+}
+
+// swagger:route POST /me/erasure Users eraseMe
+//
+// Permanently erases the personal details of the authenticated user, signing out every session.
+// The work they created is kept, attributed to a tombstone identity.
+//
+// responses:
+//
+//	204: noContent
+//	400: errorResponse
+//	500: errorResponse
+func (p *PersonalData) EraseMe(w http.ResponseWriter, r *http.Request) {
+	erasureDto, err := getDtoFromBody[dto.EraseMeDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	err = p.personalDataService.EraseMe(user, erasureDto.Password)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.NoContent(w)
+}
+
+// swagger:parameters eraseMe
+type EraseMeParameter struct {
+	// The password of the authenticated user, confirming the erasure
+	// in: body
+	// required: true
+	Body dto.EraseMeDto
+}
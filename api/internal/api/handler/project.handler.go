@@ -8,6 +8,7 @@ import (
 	"godo/internal/api/services"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 	"net/http"
 	"strconv"
 )
@@ -34,30 +35,41 @@ func NewProjectsHandler(
 
 // swagger:route GET /project Projects listProjectInfo
 //
-// Returns a list of projects associated with the authenticated account
+// Returns a list of projects associated with the authenticated account, optionally only those owned by a team
 //
 // responses:
 //  200: projectInfoResponse
+//  304: notModified
+//  400: errorResponse
 //  500: errorResponse
 func (p *Projects) GetAllProjects(w http.ResponseWriter, r *http.Request) {
 	user := entities.User{}
 	user = r.Context().Value(entities.UserKey{}).(entities.User)
 
-	projects, err := p.projectService.GetProjects(user.AccountId)
+	teamId := r.URL.Query().Get("team")
+
+	expansion, err := api.ParseExpansion(r.URL.Query(), api.ProjectInfoResource)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	projects, err := p.projectService.GetProjects(user, teamId)
 	if err != nil {
-		api.ReturnError(err, http.StatusInternalServerError, w)
+		p.eh.HandleApiError(w, err)
 		return
 	}
 
-	api.Respond(projects, http.StatusOK, w)
+	api.LinkProjectInfo(r.Context(), projects)
+	api.NewResponse(w).Expand(expansion).Send(projects)
 }
 
 // swagger:route GET /project/{projectId} Projects getProject
 //
-// Returns the specified project
+// Returns the specified project, with its creator, stories and tags unless others are included
 //
 // responses:
 //  200: projectResponse
+//  304: notModified
 //  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
@@ -66,16 +78,22 @@ func (p *Projects) GetProjectById(w http.ResponseWriter, r *http.Request) {
 
 	projectId, paramIdExists := getParamFomRequest(r, "id")
 	if !paramIdExists {
-		http.Error(w, "The ID of the Project must be specified", http.StatusBadRequest)
+		p.eh.HandleApiError(w, ehand.ErrorProjectNotFound)
 		return
 	}
 
-	project, err := p.projectService.GetProjectById(projectId, user.AccountId)
+	expansion, err := api.ParseExpansion(r.URL.Query(), api.ProjectResource)
 	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	api.Respond(project, http.StatusOK, w)
+	project, err := p.projectService.GetProjectIncluding(projectId, user, expansion.Preload())
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkProject(r.Context(), project)
+	api.NewResponse(w).Validators(api.ProjectValidators(project)).Expand(expansion).Send(project)
 }
 
 // swagger:route POST /project Projects createProject
@@ -87,7 +105,7 @@ func (p *Projects) GetProjectById(w http.ResponseWriter, r *http.Request) {
 //  400: errorResponse
 //  500: errorResponse
 func (p *Projects) CreateProject(w http.ResponseWriter, r *http.Request) {
-	projectDto, err := getDtoFromJSONBody[dto.NewProjectDto](w, r)
+	projectDto, err := getDtoFromBody[dto.NewProjectDto](w, r)
 	if err != nil {
 		return
 	}
@@ -98,6 +116,7 @@ func (p *Projects) CreateProject(w http.ResponseWriter, r *http.Request) {
 	newProject := entities.Project{
 		Name:        projectDto.Name,
 		Description: projectDto.Description,
+		Restricted:  projectDto.Restricted,
 		Creator:     user,
 	}
 
@@ -106,7 +125,8 @@ func (p *Projects) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.Respond(createdProject, http.StatusCreated, w)
+	api.LinkProject(r.Context(), createdProject)
+	api.NewResponse(w).Created(createdProject.Links.Self).Send(createdProject)
 }
 
 // swagger:route PUT /project/{projectId} Projects updateProject
@@ -118,18 +138,25 @@ func (p *Projects) CreateProject(w http.ResponseWriter, r *http.Request) {
 //  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
-// UpdateProject TODO: Improve to incorporate a DTO
 func (p *Projects) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	projectId, _ := getParamFomRequest(r, "id")
 
 	// Gat the new project data from the body
-	newProjectData, err := getDtoFromJSONBody[entities.Project](w, r)
+	projectDto, err := getDtoFromBody[dto.UpdateProjectDto](w, r)
 	if err != nil {
 		return
 	}
 
+	user := getUserFromContext(r.Context())
+
 	// Update the project
-	err = p.projectService.UpdateProject(projectId, newProjectData)
+	newProjectData := &entities.Project{
+		Name:        projectDto.Name,
+		Description: projectDto.Description,
+		Status:      projectDto.Status,
+	}
+
+	err = p.projectService.UpdateProject(projectId, user, newProjectData)
 	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -137,6 +164,54 @@ func (p *Projects) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	api.Respond("", http.StatusNoContent, w)
 }
 
+// swagger:route PATCH /project/{projectId} Projects patchProject
+//
+// Applies a merge patch or JSON patch to the specified project, persisting only the changed fields
+//
+// Consumes:
+// - application/merge-patch+json
+// - application/json-patch+json
+//
+// responses:
+//  200: projectResponse
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  409: errorResponse
+//  415: errorResponse
+//  500: errorResponse
+func (p *Projects) PatchProject(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	projectId, _ := getParamFomRequest(r, "id")
+
+	project, err := p.projectService.GetProjectById(projectId, user)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	projectDto, err := getPatchedFromBody(w, r, dto.ProjectPatchDto{
+		Name:        project.Name,
+		Description: project.Description,
+		Status:      project.Status,
+	})
+	if err != nil {
+		return
+	}
+
+	patched := *project
+	patched.Name = projectDto.Name
+	patched.Description = projectDto.Description
+	patched.Status = projectDto.Status
+
+	updated, err := p.projectService.PatchProject(projectId, user, &patched)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkProject(r.Context(), updated)
+	api.Respond(updated, http.StatusOK, w)
+}
+
 // swagger:route POST /project/{projectId}/tag Projects createTag
 //
 // Created and associated the tag with the specified project
@@ -149,11 +224,17 @@ func (p *Projects) UpdateProject(w http.ResponseWriter, r *http.Request) {
 // AddTagToProject TODO: Ensure project doesn't already have a tag with the same name
 func (p *Projects) AddTagToProject(w http.ResponseWriter, r *http.Request) {
 	projId, _ := getParamFomRequest(r, "id")
-	tagDto, err := getDtoFromJSONBody[dto.NewTagDto](w, r)
+	tagDto, err := getDtoFromBody[dto.NewTagDto](w, r)
 	if err != nil {
 		return
 	}
 
+	user := getUserFromContext(r.Context())
+	err = p.projectService.Authorize(projId, user, enums.Contributor)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
 	// Create the new tag
 	var tag entities.Tag
 	tag.Name = tagDto.Name
@@ -174,31 +255,37 @@ func (p *Projects) UpdateProjectTag(w http.ResponseWriter, r *http.Request) {
 	tagId, err := strconv.ParseUint(tagIdValue, 10, 32)
 	if err != nil {
 		p.log.Error(err)
-		api.ReturnError(ehand.ErrorTagMalformedId, http.StatusBadRequest, w)
+		p.eh.HandleApiError(w, ehand.ErrorTagMalformedId)
 		return
 	}
 
-	tagDto, err := getDtoFromJSONBody[dto.NewTagDto](w, r)
+	tagDto, err := getDtoFromBody[dto.NewTagDto](w, r)
 	if err != nil {
 		return
 	}
 
+	user := getUserFromContext(r.Context())
+	err = p.projectService.Authorize(projId, user, enums.Contributor)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
 	// Get the tag from the database
 	tag, err := p.tagService.GetTagById(uint(tagId), projId)
 	tag.Name = tagDto.Name
 
 	_, err = p.tagService.UpdateTag(*tag)
 	if err != nil {
-		api.ReturnError(ehand.ErrorTagNotUpdated, http.StatusInternalServerError, w)
+		p.eh.HandleApiError(w, ehand.ErrorTagNotUpdated)
 		return
 	}
 
 	api.Respond("", http.StatusNoContent, w)
 }
 
-// swagger:route DELETE /project/{projectId}/tag Projects deleteTag
+// swagger:route DELETE /project/{projectId}/tag/{tagId} Projects deleteTag
 //
-// Created and associated the tag with the specified project
+// Deletes the tag from the specified project
 //
 // responses:
 //  204: noContent
@@ -208,8 +295,14 @@ func (p *Projects) UpdateProjectTag(w http.ResponseWriter, r *http.Request) {
 func (p *Projects) DeleteProjectTag(w http.ResponseWriter, r *http.Request) {
 	projId, _ := getParamFomRequest(r, "projectId")
 	tagId, _ := getUintParamFomRequest(r, "tagId")
+	user := getUserFromContext(r.Context())
+
+	err := p.projectService.Authorize(projId, user, enums.Contributor)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
 
-	_, err := p.tagService.DeleteTag(tagId, projId)
+	_, err = p.tagService.DeleteTag(tagId, projId)
 	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -227,7 +320,7 @@ func (p *Projects) DeleteProjectTag(w http.ResponseWriter, r *http.Request) {
 //  404: errorResponse
 //  500: errorResponse
 func (p *Projects) UpdateProjectStatus(w http.ResponseWriter, r *http.Request) {
-	statusDto, err := getDtoFromJSONBody[dto.ProjectStatusUpdateDto](w, r)
+	statusDto, err := getDtoFromBody[dto.ProjectStatusUpdateDto](w, r)
 	if err != nil {
 		return
 	}
@@ -236,26 +329,25 @@ func (p *Projects) UpdateProjectStatus(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 
 	// Get the project from the database
-	project, err := p.projectService.GetProjectById(projectId, user.AccountId)
+	project, err := p.projectService.GetProjectById(projectId, user)
 	if err != nil {
 		p.log.Debugf("Could not find project with projectId %s and accountId %s", projectId, user.AccountId)
-		api.ReturnError(ehand.ErrorProjectNotFound, http.StatusNotFound, w)
+		p.eh.HandleApiError(w, ehand.ErrorProjectNotFound)
 		return
 	}
 
 	// Update the project
 	project.Status = statusDto.Status
-	err = p.projectService.UpdateProject(projectId, project)
-	if err != nil {
+	err = p.projectService.UpdateProject(projectId, user, project)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
 		p.log.Debugf("Could not update with projectId %s and accountId %s", projectId, user.AccountId)
-		api.ReturnError(ehand.ErrorProjectNotFound, http.StatusNotFound, w)
 		return
 	}
 
 	api.Respond("", http.StatusNoContent, w)
 }
 
-// swagger:route DELETE /project Projects deleteProject
+// swagger:route DELETE /project/{projectId} Projects deleteProject
 //
 // Deletes the given project resource
 //
@@ -265,9 +357,135 @@ func (p *Projects) UpdateProjectStatus(w http.ResponseWriter, r *http.Request) {
 //  500: errorResponse
 func (p *Projects) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	projectId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
 
 	// Delete the project
-	err := p.projectService.DeleteProject(projectId)
+	err := p.projectService.DeleteProject(projectId, user)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond("", http.StatusNoContent, w)
+}
+
+// swagger:route PUT /project/{projectId}/visibility Projects updateProjectVisibility
+//
+// Sets whether the specified project is restricted to its members
+//
+// responses:
+//  204: noContent
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (p *Projects) UpdateProjectVisibility(w http.ResponseWriter, r *http.Request) {
+	visibilityDto, err := getDtoFromBody[dto.ProjectVisibilityDto](w, r)
+	if err != nil {
+		return
+	}
+
+	projectId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	err = p.projectService.SetRestricted(projectId, user, visibilityDto.Restricted)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond("", http.StatusNoContent, w)
+}
+
+// swagger:route PUT /project/{projectId}/team Projects updateProjectTeam
+//
+// Sets the team owning the specified project; a null team leaves the project without a team
+//
+// responses:
+//  204: noContent
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (p *Projects) UpdateProjectTeam(w http.ResponseWriter, r *http.Request) {
+	teamDto, err := getDtoFromBody[dto.ProjectTeamDto](w, r)
+	if err != nil {
+		return
+	}
+
+	projectId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	err = p.projectService.SetTeam(projectId, user, teamDto.TeamId)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond("", http.StatusNoContent, w)
+}
+
+// swagger:route GET /project/{projectId}/member Projects listProjectMembers
+//
+// Returns the members of the specified project
+//
+// responses:
+//  200: projectMemberListResponse
+//  304: notModified
+//  404: errorResponse
+//  500: errorResponse
+func (p *Projects) GetProjectMembers(w http.ResponseWriter, r *http.Request) {
+	projectId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	members, err := p.projectService.GetMembers(projectId, user)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(members, http.StatusOK, w)
+}
+
+// swagger:route PUT /project/{projectId}/member/{userId} Projects setProjectMember
+//
+// Adds the user to the specified project with the given role, or changes their role if they are already a member
+//
+// responses:
+//  200: projectMemberResponse
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (p *Projects) SetProjectMember(w http.ResponseWriter, r *http.Request) {
+	memberDto, err := getDtoFromBody[dto.ProjectMemberDto](w, r)
+	if err != nil {
+		return
+	}
+
+	projectId, _ := getParamFomRequest(r, "id")
+	memberId, _ := getUintParamFomRequest(r, "userId")
+	user := getUserFromContext(r.Context())
+
+	member, err := p.projectService.SetMember(projectId, user, memberId, memberDto.Role)
+	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(member, http.StatusOK, w)
+}
+
+// swagger:route DELETE /project/{projectId}/member/{userId} Projects removeProjectMember
+//
+// Removes the user from the specified project
+//
+// responses:
+//  204: noContent
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (p *Projects) RemoveProjectMember(w http.ResponseWriter, r *http.Request) {
+	projectId, _ := getParamFomRequest(r, "id")
+	memberId, _ := getUintParamFomRequest(r, "userId")
+	user := getUserFromContext(r.Context())
+
+	err := p.projectService.RemoveMember(projectId, user, memberId)
 	if status := p.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -277,14 +495,33 @@ func (p *Projects) DeleteProject(w http.ResponseWriter, r *http.Request) {
 
 // Generic Swagger documentation
 
-// swagger:parameters getProject updateProject deleteProject createTag deleteTag
+// swagger:parameters getProject updateProject patchProject updateProjectStatus deleteProject createTag deleteTag updateProjectVisibility updateProjectTeam listProjectMembers setProjectMember removeProjectMember
 type ProductUUIDParameter struct {
 	// The ID of the specified Project
 	// in: path
 	// required: true
 	// pattern: ^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$
 	// example: f9d633f8-c684-4dc3-b410-d36df912c4c1
-	ID string `json:"id"`
+	ID string `json:"projectId"`
+}
+
+// swagger:parameters getProject
+type ProjectIncludeParameter struct {
+	// The relations to include, as comma separated paths of creator, stories, stories.creator, stories.tasks,
+	// stories.tasks.creator, stories.tasks.tags and tags; all are included if not given
+	// in: query
+	// required: false
+	// example: stories.tasks.tags,creator
+	Include string `json:"include"`
+}
+
+// swagger:parameters getProject listProjectInfo
+type ProjectFieldsParameter struct {
+	// The comma separated fields of the projects to respond with; the id and _links are always sent
+	// in: query
+	// required: false
+	// example: name,status
+	Fields string `json:"fields[project]"`
 }
 
 // swagger:parameters createProject
@@ -303,6 +540,22 @@ type UpdateProjectParameter struct {
 	Body dto.UpdateProjectDto
 }
 
+// swagger:parameters patchProject
+type PatchProjectParameter struct {
+	// A merge patch, or a JSON patch, of the project's editable fields
+	// in: body
+	// required: true
+	Body dto.ProjectPatchDto
+}
+
+// swagger:parameters updateProjectStatus
+type UpdateProjectStatusParameter struct {
+	// The status of the project
+	// in: body
+	// required: true
+	Body dto.ProjectStatusUpdateDto
+}
+
 // swagger:parameters createTag
 type NewTag struct {
 	// The tag to be created and associated with the given project
@@ -310,3 +563,43 @@ type NewTag struct {
 	// required: true
 	Body dto.NewTagDto
 }
+
+// swagger:parameters setProjectMember removeProjectMember
+type ProjectMemberIDParameter struct {
+	// The ID of the user
+	// in: path
+	// required: true
+	ID uint `json:"userId"`
+}
+
+// swagger:parameters setProjectMember
+type ProjectMemberParameter struct {
+	// The role of the user within the project
+	// in: body
+	// required: true
+	Body dto.ProjectMemberDto
+}
+
+// swagger:parameters updateProjectVisibility
+type ProjectVisibilityParameter struct {
+	// Whether the project is restricted to its members
+	// in: body
+	// required: true
+	Body dto.ProjectVisibilityDto
+}
+
+// swagger:parameters updateProjectTeam
+type ProjectTeamParameter struct {
+	// The team owning the project
+	// in: body
+	// required: true
+	Body dto.ProjectTeamDto
+}
+
+// swagger:parameters listProjectInfo
+type ProjectTeamFilterParameter struct {
+	// The ID of the Team owning the projects
+	// in: query
+	// required: false
+	TeamId string `json:"team"`
+}
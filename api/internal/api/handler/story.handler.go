@@ -11,22 +11,16 @@ import (
 )
 
 type Stories struct {
-	log            ilog.StdLogger
-	storyService   services.StoryService
-	projectService services.ProjectService
-	eh             ehand.ErrorHandler
+	log          ilog.StdLogger
+	storyService services.StoryService
+	eh           ehand.ErrorHandler
 }
 
-func NewStoriesHandler(
-	logger ilog.StdLogger,
-	storyService services.StoryService,
-	projectService services.ProjectService) Stories {
-
+func NewStoriesHandler(logger ilog.StdLogger, storyService services.StoryService) Stories {
 	return Stories{
-		log:            logger,
-		storyService:   storyService,
-		projectService: projectService,
-		eh:             ehand.New(),
+		log:          logger,
+		storyService: storyService,
+		eh:           ehand.New(),
 	}
 }
 
@@ -36,25 +30,34 @@ func NewStoriesHandler(
 //
 // responses:
 //  200: storyInfoResponse
+//  304: notModified
+//  400: errorResponse
 //  500: errorResponse
 func (s *Stories) GetStoriesInfo(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 
-	info, err := s.storyService.GetStoriesInfo(user.AccountId)
+	expansion, err := api.ParseExpansion(r.URL.Query(), api.StoryInfoResource)
+	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	info, err := s.storyService.GetStoriesInfo(user)
 	if err != nil {
-		api.ReturnError(err, http.StatusInternalServerError, w)
+		s.eh.HandleApiError(w, err)
 		return
 	}
 
-	api.Respond(info, http.StatusOK, w)
+	api.LinkStoryInfo(r.Context(), info)
+	api.NewResponse(w).Expand(expansion).Send(info)
 }
 
 // swagger:route GET /story/{storyId} Stories getStory
 //
-// Returns the specified Story
+// Returns the specified Story, with its creator unless others are included
 //
 // responses:
 //  200: storyResponse
+//  304: notModified
 //  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
@@ -63,16 +66,22 @@ func (s *Stories) GetStoryById(w http.ResponseWriter, r *http.Request) {
 
 	storyId, paramIdExists := getParamFomRequest(r, "id")
 	if !paramIdExists {
-		http.Error(w, "The ID of the Project must be specified", http.StatusBadRequest)
+		s.eh.HandleApiError(w, ehand.ErrorStoryNotFound)
+		return
+	}
+
+	expansion, err := api.ParseExpansion(r.URL.Query(), api.StoryResource)
+	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	story, err := s.storyService.GetStoryById(user.AccountId, storyId)
+	story, err := s.storyService.GetStoryIncluding(user, storyId, expansion.Preload())
 	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	api.Respond(story, http.StatusOK, w)
+	api.LinkStory(r.Context(), story)
+	api.NewResponse(w).Validators(api.StoryValidators(story)).Expand(expansion).Send(story)
 }
 
 // swagger:route POST /story Stories createStory
@@ -87,19 +96,11 @@ func (s *Stories) GetStoryById(w http.ResponseWriter, r *http.Request) {
 func (s *Stories) CreateStory(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 
-	storyDto, err := getDtoFromJSONBody[dto.NewStoryDto](w, r)
+	storyDto, err := getDtoFromBody[dto.NewStoryDto](w, r)
 	if err != nil {
 		return
 	}
 
-	// Ensure the project exists
-	projectExists := s.projectService.Exists(storyDto.ProjectId)
-	if !projectExists {
-		s.log.Debugf("Project with projectId %s not found", storyDto.ProjectId)
-		api.ReturnError(ehand.ErrorProjectNotFound, http.StatusNotFound, w)
-		return
-	}
-
 	// Create the newStory
 	newStory := entities.Story{
 		Name:        storyDto.Name,
@@ -108,12 +109,13 @@ func (s *Stories) CreateStory(w http.ResponseWriter, r *http.Request) {
 		Creator:     user,
 	}
 
-	created, err := s.storyService.CreateStory(&newStory)
+	created, err := s.storyService.CreateStory(user, &newStory)
 	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	api.Respond(created, http.StatusCreated, w)
+	api.LinkStory(r.Context(), created)
+	api.NewResponse(w).Created(created.Links.Self).Send(created)
 }
 
 // swagger:route PUT /story/{storyId} Stories updateStory
@@ -128,39 +130,28 @@ func (s *Stories) CreateStory(w http.ResponseWriter, r *http.Request) {
 func (s *Stories) UpdateStory(w http.ResponseWriter, r *http.Request) {
 	storyId, _ := getParamFomRequest(r, "id")
 
-	storyDto, err := getDtoFromJSONBody[dto.NewStoryDto](w, r)
+	storyDto, err := getDtoFromBody[dto.NewStoryDto](w, r)
 	if err != nil {
 		return
 	}
 
 	user := getUserFromContext(r.Context())
 
-	// Ensure the project for the story exists
-	projectExists := s.projectService.Exists(storyDto.ProjectId)
-	if !projectExists {
-		s.log.Debugf("Project with projectId %s not found", storyDto.ProjectId)
-		api.ReturnError(ehand.ErrorProjectNotFound, http.StatusNotFound, w)
-		return
-	}
-
 	// Update the story
-	ns, err := s.storyService.GetStoryById(user.AccountId, storyId)
+	ns, err := s.storyService.GetStoryById(user, storyId)
 	if err != nil {
 		s.log.Debugf("The story with storyId %s and accountId % could not be found", storyId, user.AccountId)
-		api.ReturnError(ehand.ErrorStoryNotFound, http.StatusNotFound, w)
+		s.eh.HandleApiError(w, ehand.ErrorStoryNotFound)
 		return
 	}
 
 	ns.Name = storyDto.Name
 	ns.Description = storyDto.Description
 
-	// Verify and update the projectId
-	if storyDto.ProjectId != ns.ProjectId {
-		ns.ProjectId = storyDto.ProjectId
-		// TODO: If update projectId -> Verify project is owned by account
-	}
+	// The service verifies that the story may be moved to the project
+	ns.ProjectId = storyDto.ProjectId
 
-	err = s.storyService.UpdateStory(storyId, ns)
+	err = s.storyService.UpdateStory(user, storyId, ns)
 	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -168,6 +159,55 @@ func (s *Stories) UpdateStory(w http.ResponseWriter, r *http.Request) {
 	api.Respond("", http.StatusNoContent, w)
 }
 
+// swagger:route PATCH /story/{storyId} Stories patchStory
+//
+// Applies a merge patch or JSON patch to the specified Story, persisting only the changed fields
+//
+// Consumes:
+// - application/merge-patch+json
+// - application/json-patch+json
+//
+// responses:
+//  200: storyResponse
+//  400: errorResponse
+//  404: errorResponse
+//  409: errorResponse
+//  415: errorResponse
+//  500: errorResponse
+func (s *Stories) PatchStory(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	storyId, _ := getParamFomRequest(r, "id")
+
+	story, err := s.storyService.GetStoryById(user, storyId)
+	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	storyDto, err := getPatchedFromBody(w, r, dto.StoryPatchDto{
+		Name:        story.Name,
+		Description: story.Description,
+		ProjectId:   story.ProjectId,
+	})
+	if err != nil {
+		return
+	}
+
+	patched := *story
+	patched.Name = storyDto.Name
+	patched.Description = storyDto.Description
+
+	// The service verifies that the story may be moved to the project
+	patched.ProjectId = storyDto.ProjectId
+
+	updated, err := s.storyService.PatchStory(user, &patched)
+	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkStory(r.Context(), updated)
+	api.Respond(updated, http.StatusOK, w)
+}
+
 // swagger:route DELETE /story/{storyId} Stories deleteStory
 //
 // Deletes the specified Story
@@ -179,8 +219,9 @@ func (s *Stories) UpdateStory(w http.ResponseWriter, r *http.Request) {
 //  500: errorResponse
 func (s *Stories) DeleteStory(w http.ResponseWriter, r *http.Request) {
 	storyId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
 
-	err := s.storyService.DeleteStory(storyId)
+	err := s.storyService.DeleteStory(user, storyId)
 	if status := s.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -188,7 +229,7 @@ func (s *Stories) DeleteStory(w http.ResponseWriter, r *http.Request) {
 	api.Respond("", http.StatusNoContent, w)
 }
 
-// swagger:parameters getStory updateStory deleteStory
+// swagger:parameters getStory updateStory patchStory deleteStory
 type StoryUUIDParameter struct {
 	// The ID of the specified Story
 	// in: path
@@ -198,6 +239,25 @@ type StoryUUIDParameter struct {
 	ID string `json:"storyId"`
 }
 
+// swagger:parameters getStory
+type StoryIncludeParameter struct {
+	// The relations to include, as comma separated paths of creator, tasks, tasks.creator and tasks.tags;
+	// the creator is included if not given
+	// in: query
+	// required: false
+	// example: tasks.tags,creator
+	Include string `json:"include"`
+}
+
+// swagger:parameters getProject getStory listStoryInfo
+type StoryFieldsParameter struct {
+	// The comma separated fields of the stories to respond with; the id and _links are always sent
+	// in: query
+	// required: false
+	// example: name,status
+	Fields string `json:"fields[story]"`
+}
+
 // swagger:parameters createStory
 type NewStoryParameter struct {
 	// The story to be created
@@ -213,3 +273,11 @@ type UpdateStoryParameter struct {
 	// required: true
 	Body dto.NewStoryDto
 }
+
+// swagger:parameters patchStory
+type PatchStoryParameter struct {
+	// A merge patch, or a JSON patch, of the story's editable fields
+	// in: body
+	// required: true
+	Body dto.StoryPatchDto
+}
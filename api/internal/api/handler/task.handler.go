@@ -7,6 +7,7 @@ import (
 	"godo/internal/api/services"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 	"net/http"
 )
 
@@ -32,40 +33,58 @@ func NewTasksHandler(
 
 // swagger:route GET /task Tasks listTasks
 //
-// Returns a list of Tasks associated with the authenticated account
+// Returns a list of Tasks associated with the authenticated account, optionally only those in projects owned by a team
 //
 // responses:
 //  200: taskInfoResponse
+//  304: notModified
+//  400: errorResponse
 //  500: errorResponse
 func (t *Tasks) GetAllTasks(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 
-	tasks, err := t.taskService.GetTasks(user.AccountId)
+	teamId := r.URL.Query().Get("team")
+
+	expansion, err := api.ParseExpansion(r.URL.Query(), api.TaskResource)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	api.Respond(tasks, http.StatusOK, w)
+	tasks, err := t.taskService.GetTasks(user, teamId, expansion.Preload())
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkTasks(r.Context(), tasks)
+	api.NewResponse(w).Validators(api.TaskValidators(tasks...)).Expand(expansion).Send(tasks)
 }
 
 // swagger:route GET /task/{taskId} Tasks getTask
 //
-// Returns the requested Task
+// Returns the requested Task, with its creator unless others are included
 //
 // responses:
 //  200: taskResponse
+//  304: notModified
+//  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
 func (t *Tasks) GetTaskById(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 	taskId, _ := getParamFomRequest(r, "id")
 
-	task, err := t.taskService.GetTaskById(taskId, user.AccountId)
+	expansion, err := api.ParseExpansion(r.URL.Query(), api.TaskResource)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	api.Respond(task, http.StatusOK, w)
+	task, err := t.taskService.GetTaskIncluding(taskId, user, expansion.Preload())
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkTask(r.Context(), task)
+	api.NewResponse(w).Validators(api.TaskValidators(task)).Expand(expansion).Send(task)
 }
 
 // swagger:route POST /task Tasks createTask
@@ -77,9 +96,8 @@ func (t *Tasks) GetTaskById(w http.ResponseWriter, r *http.Request) {
 //  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
-// CreateTask TODO: Validate that the story belongs to the user's account
 func (t *Tasks) CreateTask(w http.ResponseWriter, r *http.Request) {
-	taskDto, err := getDtoFromJSONBody[dto.NewTaskDto](w, r)
+	taskDto, err := getDtoFromBody[dto.NewTaskDto](w, r)
 	if err != nil {
 		return
 	}
@@ -92,14 +110,16 @@ func (t *Tasks) CreateTask(w http.ResponseWriter, r *http.Request) {
 		Description: taskDto.Description,
 		StoryId:     taskDto.StoryId,
 		Creator:     user,
+		AssigneeId:  taskDto.AssigneeId,
 	}
 
-	created, err := t.taskService.CreateTask(newTask)
+	created, err := t.taskService.CreateTask(user, newTask)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	api.Respond(created, http.StatusCreated, w)
+	api.LinkTask(r.Context(), created)
+	api.NewResponse(w).Created(created.Links.Self).Send(created)
 }
 
 // swagger:route PUT /task/{taskId} Tasks updateTask
@@ -114,7 +134,7 @@ func (t *Tasks) CreateTask(w http.ResponseWriter, r *http.Request) {
 // UpdateTask TODO: Remove deduplication of error handling in update
 // methods and other functions in the handlers
 func (t *Tasks) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	taskDto, err := getDtoFromJSONBody[dto.UpdateTaskDto](w, r)
+	taskDto, err := getDtoFromBody[dto.UpdateTaskDto](w, r)
 	if err != nil {
 		return
 	}
@@ -123,7 +143,7 @@ func (t *Tasks) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	taskId, _ := getParamFomRequest(r, "id")
 
 	// Fetch the task from the database
-	task, err := t.taskService.GetTaskById(taskId, user.AccountId)
+	task, err := t.taskService.GetTaskById(taskId, user)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -138,11 +158,69 @@ func (t *Tasks) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		task.StoryId = taskDto.StoryId
 	}
 
-	updated, err := t.taskService.UpdateTask(task)
+	if taskDto.AssigneeId != nil {
+		task.AssigneeId = taskDto.AssigneeId
+	}
+
+	updated, err := t.taskService.UpdateTask(user, task)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.LinkTask(r.Context(), updated)
+	api.Respond(updated, http.StatusOK, w)
+}
+
+// swagger:route PATCH /task/{taskId} Tasks patchTask
+//
+// Applies a merge patch or JSON patch to the given Task, persisting only the changed fields
+//
+// Consumes:
+// - application/merge-patch+json
+// - application/json-patch+json
+//
+// responses:
+//  200: taskResponse
+//  400: errorResponse
+//  404: errorResponse
+//  409: errorResponse
+//  415: errorResponse
+//  500: errorResponse
+func (t *Tasks) PatchTask(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	taskId, _ := getParamFomRequest(r, "id")
+
+	task, err := t.taskService.GetTaskById(taskId, user)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	taskDto, err := getPatchedFromBody(w, r, dto.TaskPatchDto{
+		Name:        task.Name,
+		Description: task.Description,
+		Type:        task.Type,
+		Status:      task.Status,
+		StoryId:     task.StoryId,
+		AssigneeId:  task.AssigneeId,
+	})
+	if err != nil {
+		return
+	}
+
+	patched := *task
+	patched.Name = taskDto.Name
+	patched.Description = taskDto.Description
+	patched.Type = taskDto.Type
+	patched.Status = taskDto.Status
+	patched.StoryId = taskDto.StoryId
+	patched.AssigneeId = taskDto.AssigneeId
+
+	updated, err := t.taskService.PatchTask(user, &patched)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
+	api.LinkTask(r.Context(), updated)
 	api.Respond(updated, http.StatusOK, w)
 }
 
@@ -159,24 +237,25 @@ func (t *Tasks) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 	taskId, _ := getParamFomRequest(r, "id")
 
-	taskDto, err := getDtoFromJSONBody[dto.UpdateTaskStatusDto](w, r)
+	taskDto, err := getDtoFromBody[dto.UpdateTaskStatusDto](w, r)
 	if err != nil {
 		return
 	}
 
 	// Get the task from the database
-	task, err := t.taskService.GetTaskById(taskId, user.AccountId)
+	task, err := t.taskService.GetTaskById(taskId, user)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
 	// Update the task
 	task.Status = taskDto.Status
-	updated, err := t.taskService.UpdateTask(task)
+	updated, err := t.taskService.UpdateTask(user, task)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
+	api.LinkTask(r.Context(), updated)
 	api.Respond(updated, http.StatusOK, w)
 }
 
@@ -193,24 +272,25 @@ func (t *Tasks) UpdateTaskType(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r.Context())
 	taskId, _ := getParamFomRequest(r, "id")
 
-	taskDto, err := getDtoFromJSONBody[dto.UpdateTaskTypeDto](w, r)
+	taskDto, err := getDtoFromBody[dto.UpdateTaskTypeDto](w, r)
 	if err != nil {
 		return
 	}
 
 	// Get the task from the database
-	task, err := t.taskService.GetTaskById(taskId, user.AccountId)
+	task, err := t.taskService.GetTaskById(taskId, user)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
 	// Update the task
 	task.Type = taskDto.Type
-	updated, err := t.taskService.UpdateTask(task)
+	updated, err := t.taskService.UpdateTask(user, task)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
+	api.LinkTask(r.Context(), updated)
 	api.Respond(updated, http.StatusOK, w)
 }
 
@@ -229,8 +309,8 @@ func (t *Tasks) AddTag(w http.ResponseWriter, r *http.Request) {
 
 	user := getUserFromContext(r.Context())
 
-	// Ensure that the task exists for the user's account
-	_, err := t.taskService.GetTaskById(taskId, user.AccountId)
+	// Ensure that the user may change the task
+	err := t.taskService.Authorize(taskId, user, enums.Contributor)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -260,8 +340,8 @@ func (t *Tasks) RemoveTag(w http.ResponseWriter, r *http.Request) {
 
 	user := getUserFromContext(r.Context())
 
-	// Ensure that the task exists for the user's account
-	_, err := t.taskService.GetTaskById(taskId, user.AccountId)
+	// Ensure that the user may change the task
+	err := t.taskService.Authorize(taskId, user, enums.Contributor)
 	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -277,7 +357,7 @@ func (t *Tasks) RemoveTag(w http.ResponseWriter, r *http.Request) {
 
 // Generic Swagger documentation
 
-// swagger:parameters getTask updateTask updateTaskStatus updateTaskType addTaskTag removeTaskTag
+// swagger:parameters getTask updateTask patchTask updateTaskStatus updateTaskType addTaskTag removeTaskTag
 type TaskUUIDParameter struct {
 	// The ID of the specified Task
 	// in: path
@@ -287,7 +367,42 @@ type TaskUUIDParameter struct {
 	ID string `json:"taskId"`
 }
 
-// swagger:parameters addTaskTag removeTaskTag
+// swagger:parameters listTasks
+type TaskTeamFilterParameter struct {
+	// The ID of the Team owning the projects of the tasks
+	// in: query
+	// required: false
+	TeamId string `json:"team"`
+}
+
+// swagger:parameters getTask listTasks
+type TaskIncludeParameter struct {
+	// The relations to include, as comma separated paths of creator and tags; the creator is included if not given
+	// in: query
+	// required: false
+	// example: tags,creator
+	Include string `json:"include"`
+}
+
+// swagger:parameters getProject getStory getTask listTasks
+type TaskFieldsParameter struct {
+	// The comma separated fields of the tasks to respond with; the id and _links are always sent
+	// in: query
+	// required: false
+	// example: name,status
+	Fields string `json:"fields[task]"`
+}
+
+// swagger:parameters getProject getTask listTasks
+type TagFieldsParameter struct {
+	// The comma separated fields of the tags to respond with; the id is always sent
+	// in: query
+	// required: false
+	// example: name
+	Fields string `json:"fields[tag]"`
+}
+
+// swagger:parameters addTaskTag removeTaskTag deleteTag
 type TagIDParameter struct {
 	// The ID of the specified Tag
 	// in: path
@@ -312,6 +427,14 @@ type UpdateTaskParameter struct {
 	Body dto.UpdateTaskDto
 }
 
+// swagger:parameters patchTask
+type PatchTaskParameter struct {
+	// A merge patch, or a JSON patch, of the task's editable fields
+	// in: body
+	// required: true
+	Body dto.TaskPatchDto
+}
+
 // swagger:parameters updateTaskStatus
 type UpdateTaskStatusParameter struct {
 	// The status of the task to be updated
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"godo/internal/api"
+	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"net/http"
+)
+
+type Teams struct {
+	log         ilog.StdLogger
+	teamService services.TeamService
+	eh          ehand.ErrorHandler
+}
+
+func NewTeamsHandler(logger ilog.StdLogger, teamService services.TeamService) Teams {
+	return Teams{
+		log:         logger,
+		teamService: teamService,
+		eh:          ehand.New(),
+	}
+}
+
+// swagger:route GET /team Teams listTeams
+//
+// # Returns the teams in the authenticated account
+//
+// responses:
+//
+//	200: teamListResponse
+//	304: notModified
+//	500: errorResponse
+func (t *Teams) GetTeams(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	teams, err := t.teamService.GetTeams(user)
+	if err != nil {
+		t.eh.HandleApiError(w, err)
+		return
+	}
+
+	api.Respond(teams, http.StatusOK, w)
+}
+
+// swagger:route GET /team/{teamId} Teams getTeam
+//
+// # Returns the specified team along with its members
+//
+// responses:
+//
+//	200: teamResponse
+//	304: notModified
+//	404: errorResponse
+func (t *Teams) GetTeamById(w http.ResponseWriter, r *http.Request) {
+	teamId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	team, err := t.teamService.GetTeamById(teamId, user)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(team, http.StatusOK, w)
+}
+
+// swagger:route POST /team Teams createTeam
+//
+// # Creates a team in the authenticated account
+//
+// responses:
+//
+//	201: teamResponse
+//	400: errorResponse
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (t *Teams) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	teamDto, err := getDtoFromBody[dto.NewTeamDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	team, err := t.teamService.CreateTeam(user, teamDto.Name, teamDto.LeadId)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(team, http.StatusCreated, w)
+}
+
+// swagger:route PATCH /team/{teamId} Teams updateTeam
+//
+// # Renames the specified team or changes its lead
+//
+// responses:
+//
+//	200: teamResponse
+//	400: errorResponse
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (t *Teams) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	teamDto, err := getDtoFromBody[dto.UpdateTeamDto](w, r)
+	if err != nil {
+		return
+	}
+
+	teamId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	team, err := t.teamService.UpdateTeam(teamId, user, teamDto.Name, teamDto.LeadId)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(team, http.StatusOK, w)
+}
+
+// swagger:route DELETE /team/{teamId} Teams deleteTeam
+//
+// Deletes the specified team; the projects it owned are left without a team
+//
+// responses:
+//
+//	204: noContent
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (t *Teams) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teamId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	err := t.teamService.DeleteTeam(teamId, user)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.NoContent(w)
+}
+
+// swagger:route PUT /team/{teamId}/member/{userId} Teams addTeamMember
+//
+// Adds a user in the authenticated account to the specified team; only the team's lead and admins may change its members
+//
+// responses:
+//
+//	200: teamResponse
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (t *Teams) AddTeamMember(w http.ResponseWriter, r *http.Request) {
+	teamId, _ := getParamFomRequest(r, "id")
+	memberId, _ := getUintParamFomRequest(r, "userId")
+	user := getUserFromContext(r.Context())
+
+	team, err := t.teamService.AddMember(teamId, user, memberId)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(team, http.StatusOK, w)
+}
+
+// swagger:route DELETE /team/{teamId}/member/{userId} Teams removeTeamMember
+//
+// Removes a user from the specified team; only the team's lead and admins may change its members
+//
+// responses:
+//
+//	204: noContent
+//	403: errorResponse
+//	404: errorResponse
+//	500: errorResponse
+func (t *Teams) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	teamId, _ := getParamFomRequest(r, "id")
+	memberId, _ := getUintParamFomRequest(r, "userId")
+	user := getUserFromContext(r.Context())
+
+	err := t.teamService.RemoveMember(teamId, user, memberId)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.NoContent(w)
+}
+
+// swagger:route GET /team/{teamId}/workload Teams getTeamWorkload
+//
+// # Returns the number of open tasks assigned to each member of the specified team, grouped by progress status
+//
+// responses:
+//
+//	200: teamWorkloadResponse
+//	304: notModified
+//	404: errorResponse
+//	500: errorResponse
+func (t *Teams) GetTeamWorkload(w http.ResponseWriter, r *http.Request) {
+	teamId, _ := getParamFomRequest(r, "id")
+	user := getUserFromContext(r.Context())
+
+	workload, err := t.teamService.GetWorkload(teamId, user)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(workload, http.StatusOK, w)
+}
+
+// Generic Swagger documentation
+
+// swagger:parameters getTeam updateTeam deleteTeam addTeamMember removeTeamMember getTeamWorkload
+type TeamUUIDParameter struct {
+	// The ID of the specified Team
+	// in: path
+	// required: true
+	// pattern: ^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$
+	// example: f9d633f8-c684-4dc3-b410-d36df912c4c1
+	ID string `json:"teamId"`
+}
+
+// swagger:parameters addTeamMember removeTeamMember
+type TeamMemberIDParameter struct {
+	// The ID of the user
+	// in: path
+	// required: true
+	ID uint `json:"userId"`
+}
+
+// swagger:parameters createTeam
+type NewTeamParameter struct {
+	// The team to be created
+	// in: body
+	// required: true
+	Body dto.NewTeamDto
+}
+
+// swagger:parameters updateTeam
+type UpdateTeamParameter struct {
+	// The fields of the team to be updated
+	// in: body
+	// required: true
+	Body dto.UpdateTeamDto
+}
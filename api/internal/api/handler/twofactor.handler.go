@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"godo/internal/api"
+	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/services"
+	"godo/internal/helper/ilog"
+	"net/http"
+)
+
+type TwoFactor struct {
+	log              ilog.StdLogger
+	twoFactorService services.TwoFactorService
+	eh               ehand.ErrorHandler
+}
+
+func NewTwoFactorHandler(logger ilog.StdLogger, twoFactorService services.TwoFactorService) TwoFactor {
+	return TwoFactor{
+		log:              logger,
+		twoFactorService: twoFactorService,
+		eh:               ehand.New(),
+	}
+}
+
+// swagger:route POST /user/2fa TwoFactor beginTwoFactorEnrollment
+//
+// # Begins two-factor enrollment for the authenticated user, returning the secret for their authenticator app
+//
+// responses:
+//
+//	200: twoFactorEnrollmentResponse
+//	400: errorResponse
+//	500: errorResponse
+func (t *TwoFactor) BeginEnrollment(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	key, err := t.twoFactorService.BeginEnrollment(&user)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	qrCode, err := key.QRCodeDataURI()
+	if err != nil {
+		t.log.Error("Could not render the provisioning QR code: ", err)
+	}
+
+	response := dto.TwoFactorEnrollmentDto{
+		Secret:          key.Secret,
+		ProvisioningURI: key.ProvisioningURI,
+		QRCode:          qrCode,
+	}
+
+	api.Respond(response, http.StatusOK, w)
+}
+
+// swagger:route POST /user/2fa/confirm TwoFactor confirmTwoFactorEnrollment
+//
+// # Confirms two-factor enrollment with a code from the authenticator app, returning the recovery codes
+//
+// responses:
+//
+//	200: recoveryCodesResponse
+//	400: errorResponse
+//	401: errorResponse
+//	500: errorResponse
+func (t *TwoFactor) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	codeDto, err := getDtoFromBody[dto.TwoFactorCodeDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	codes, err := t.twoFactorService.ConfirmEnrollment(&user, codeDto.Code)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(dto.RecoveryCodesDto{RecoveryCodes: codes}, http.StatusOK, w)
+}
+
+// swagger:route DELETE /user/2fa TwoFactor disableTwoFactor
+//
+// # Disables two-factor authentication for the authenticated user
+//
+// responses:
+//
+//	204: noContent
+//	400: errorResponse
+//	401: errorResponse
+//	500: errorResponse
+func (t *TwoFactor) Disable(w http.ResponseWriter, r *http.Request) {
+	codeDto, err := getDtoFromBody[dto.TwoFactorCodeDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	err = t.twoFactorService.Disable(&user, codeDto.Code)
+	if status := t.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.NoContent(w)
+}
+
+// Generic Swagger documentation
+
+// TwoFactorEnrollmentResponse the secret to be added to an authenticator app
+// swagger:response twoFactorEnrollmentResponse
+type TwoFactorEnrollmentResponse struct {
+	// in: body
+	Body dto.TwoFactorEnrollmentDto
+}
+
+// RecoveryCodesResponse the recovery codes, which are only ever returned once
+// swagger:response recoveryCodesResponse
+type RecoveryCodesResponse struct {
+	// in: body
+	Body dto.RecoveryCodesDto
+}
+
+// swagger:parameters confirmTwoFactorEnrollment disableTwoFactor
+type TwoFactorCodeParameter struct {
+	// The code from the authenticator app, or a recovery code when disabling
+	// in: body
+	// required: true
+	Body dto.TwoFactorCodeDto
+}
@@ -12,38 +12,45 @@ import (
 )
 
 type Users struct {
-	log            ilog.StdLogger
-	authService    services.AuthService
-	accountService services.AccountService
-	userService    services.UserService
-	eh             ehand.ErrorHandler
+	log               ilog.StdLogger
+	authService       services.AuthService
+	accountService    services.AccountService
+	userService       services.UserService
+	twoFactorService  services.TwoFactorService
+	invitationService services.InvitationService
+	eh                ehand.ErrorHandler
 }
 
 func NewUsersHandler(
 	logger ilog.StdLogger,
 	authService services.AuthService,
 	accountService services.AccountService,
-	userService services.UserService) *Users {
+	userService services.UserService,
+	twoFactorService services.TwoFactorService,
+	invitationService services.InvitationService) *Users {
 
 	return &Users{
-		log:            logger,
-		authService:    authService,
-		accountService: accountService,
-		userService:    userService,
-		eh:             ehand.New(),
+		log:               logger,
+		authService:       authService,
+		accountService:    accountService,
+		userService:       userService,
+		twoFactorService:  twoFactorService,
+		invitationService: invitationService,
+		eh:                ehand.New(),
 	}
 }
 
 // swagger:route POST /auth/login Auth login
 //
-// Logs in a user returning a JWT for authentication
+// Logs in a user returning a JWT for authentication.
+// If the user has two-factor authentication enabled, a challenge token is returned instead
 // responses:
 //	200: JWTTokenResponse
 //  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
 func (u *Users) Login(w http.ResponseWriter, r *http.Request) {
-	request, err := getDtoFromJSONBody[dto.LoginRequestDto](w, r)
+	request, err := getDtoFromBody[dto.LoginRequestDto](w, r)
 	if err != nil {
 		return
 	}
@@ -51,7 +58,7 @@ func (u *Users) Login(w http.ResponseWriter, r *http.Request) {
 	// Validate the login request
 	err = validate.Struct(request)
 	if err != nil {
-		api.ReturnError(err, http.StatusBadRequest, w)
+		u.eh.HandleApiError(w, err)
 		return
 	}
 
@@ -65,12 +72,76 @@ func (u *Users) Login(w http.ResponseWriter, r *http.Request) {
 	err = user.VerifyPassword(request.Password)
 	if err != nil {
 		u.log.Debug("Bad authentication: incorrect password")
-		api.ReturnError(ehand.ErrorUserAuthentication, http.StatusUnauthorized, w)
+		u.eh.HandleApiError(w, ehand.ErrorUserAuthentication)
+		return
+	}
+
+	// Log in to the chosen account, or the user's default account
+	user, err = u.userService.UseAccount(*user, request.AccountId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	// The second factor must be provided before a token is issued
+	if user.TwoFactorEnabled {
+		challenge, err := u.authService.GenerateChallengeToken(*user)
+		if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+			return
+		}
+
+		response := dto.LoginResponseDto{TwoFactorRequired: true, ChallengeToken: challenge}
+		api.Respond(response, http.StatusOK, w)
 		return
 	}
 
 	// Gat a token for the user
-	token, err := u.authService.GenerateJWT(user.Email, user.Username, user.AccountId)
+	token, err := u.authService.GenerateJWT(*user)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	response := dto.LoginResponseDto{Token: token}
+	api.Respond(response, http.StatusOK, w)
+}
+
+// swagger:route POST /auth/2fa Auth twoFactorLogin
+//
+// Exchanges the challenge token from a login and a two-factor code for a JWT.
+// A challenge accepts three wrong codes, and five in a row lock the user out for a while.
+// responses:
+//	200: JWTTokenResponse
+//  400: errorResponse
+//  401: errorResponse
+//  500: errorResponse
+func (u *Users) CompleteTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	request, err := getDtoFromBody[dto.TwoFactorLoginRequestDto](w, r)
+	if err != nil {
+		return
+	}
+
+	claims, err := u.authService.GetChallengeClaims(request.ChallengeToken)
+	if err != nil {
+		u.eh.HandleApiError(w, ehand.ErrorTwoFactorChallengeInvalid)
+		return
+	}
+
+	user, err := u.userService.GetUserByEmailAddress(claims.Email)
+	if err != nil {
+		u.eh.HandleApiError(w, ehand.ErrorTwoFactorChallengeInvalid)
+		return
+	}
+
+	user, err = u.userService.UseAccount(*user, claims.AccountId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	err = u.twoFactorService.VerifyChallenge(user, request.Code, claims.TwoFactorFailures)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	token, err := u.authService.GenerateJWT(*user)
 	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -81,14 +152,14 @@ func (u *Users) Login(w http.ResponseWriter, r *http.Request) {
 
 // swagger:route POST /auth/register Auth registration
 //
-// Registers a user in the system
+// Registers a user in the system using the token from an invitation
 // responses:
 //	200: accountResponse
 //  400: errorResponse
 //  404: errorResponse
 //  500: errorResponse
 func (u *Users) Register(w http.ResponseWriter, r *http.Request) {
-	request, err := getDtoFromJSONBody[dto.RegistrationRequestDto](w, r)
+	request, err := getDtoFromBody[dto.RegistrationRequestDto](w, r)
 	if err != nil {
 		return
 	}
@@ -96,32 +167,35 @@ func (u *Users) Register(w http.ResponseWriter, r *http.Request) {
 	// Validate the registration body data
 	err = validate.Struct(request)
 	if err != nil {
-		api.ReturnError(err, http.StatusBadRequest, w)
+		u.eh.HandleApiError(w, err)
 		return
 	}
 
-	// Ensure the account exists
-	accountExists, err := u.accountService.AccountExists(request.AccountId)
+	// Use up the invitation, so that the token cannot be used by anyone else
+	invitation, err := u.invitationService.ClaimInvitation(request.InvitationToken)
 	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
 
-	if !accountExists {
-		api.ReturnError(ehand.ErrorAccountNotFound, http.StatusNotFound, w)
-		return
-	}
-
 	// Create the newUser
 	newUser := entities.User{
 		Name:      request.Name,
-		Email:     request.Email,
+		Email:     invitation.Email,
 		Username:  request.Username,
 		Password:  request.Password,
-		AccountId: request.AccountId,
+		AccountId: invitation.AccountId,
+		Role:      invitation.Role,
 	}
 
 	var createdUser *entities.User
 	createdUser, err = u.userService.CreateUser(newUser)
+	if err != nil {
+		// Allow the invitee to try again
+		if releaseErr := u.invitationService.ReleaseInvitation(invitation); releaseErr != nil {
+			u.log.Errorf("Could not release Invitation{id=%s}: %s", invitation.ID, releaseErr)
+		}
+	}
+
 	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
 		return
 	}
@@ -129,6 +203,302 @@ func (u *Users) Register(w http.ResponseWriter, r *http.Request) {
 	api.Respond(createdUser, http.StatusOK, w)
 }
 
+// swagger:route PUT /account/user/{userId}/role Users changeUserRole
+//
+// Changes the role of a user in the authenticated account; only owners may grant or revoke the owner role
+//
+// responses:
+//  200: userResponse
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (u *Users) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	roleDto, err := getDtoFromBody[dto.RoleUpdateDto](w, r)
+	if err != nil {
+		return
+	}
+
+	userId, _ := getUintParamFomRequest(r, "id")
+	actor := getUserFromContext(r.Context())
+
+	user, err := u.userService.ChangeRole(actor, userId, roleDto.Role)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(user, http.StatusOK, w)
+}
+
+// swagger:route POST /account/user/{userId}/deactivation Users deactivateUser
+//
+// Deactivates a user in the authenticated account, preventing them from logging in and ending their sessions.
+// The user remains the creator of their work; only owners may deactivate an owner
+//
+// responses:
+//  200: userResponse
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (u *Users) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	userId, _ := getUintParamFomRequest(r, "id")
+	actor := getUserFromContext(r.Context())
+
+	user, err := u.userService.DeactivateUser(actor, userId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(user, http.StatusOK, w)
+}
+
+// swagger:route DELETE /account/user/{userId}/deactivation Users reactivateUser
+//
+// Reactivates a deactivated user in the authenticated account, allowing them to log in again
+//
+// responses:
+//  200: userResponse
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (u *Users) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	userId, _ := getUintParamFomRequest(r, "id")
+	actor := getUserFromContext(r.Context())
+
+	user, err := u.userService.ReactivateUser(actor, userId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(user, http.StatusOK, w)
+}
+
+// swagger:route POST /account/user/{userId}/offboard Users offboardUser
+//
+// Deactivates a user who is leaving the account, assigning their open tasks to the successor and
+// handing the successor their project memberships. The user remains the creator of their work
+//
+// responses:
+//  200: offboardingResponse
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (u *Users) OffboardUser(w http.ResponseWriter, r *http.Request) {
+	offboardDto, err := getDtoFromBody[dto.OffboardUserDto](w, r)
+	if err != nil {
+		return
+	}
+
+	userId, _ := getUintParamFomRequest(r, "id")
+	actor := getUserFromContext(r.Context())
+
+	offboarding, err := u.userService.OffboardUser(actor, userId, offboardDto.SuccessorId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(offboarding, http.StatusOK, w)
+}
+
+// swagger:route POST /auth/switch-account Auth switchAccount
+//
+// Issues a token for acting within another account the authenticated user is a member of
+// responses:
+//	200: JWTTokenResponse
+//  400: errorResponse
+//  403: errorResponse
+//  500: errorResponse
+func (u *Users) SwitchAccount(w http.ResponseWriter, r *http.Request) {
+	switchDto, err := getDtoFromBody[dto.SwitchAccountDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	switched, err := u.userService.UseAccount(user, switchDto.AccountId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	token, err := u.authService.GenerateJWT(*switched)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(dto.LoginResponseDto{Token: token}, http.StatusOK, w)
+}
+
+// swagger:route GET /me/accounts Users listMyAccounts
+//
+// Returns every account the authenticated user is a member of, along with their role in each
+//
+// responses:
+//  200: accountMemberListResponse
+//  304: notModified
+//  500: errorResponse
+func (u *Users) GetMyAccounts(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	members, err := u.userService.GetMemberships(user)
+	if err != nil {
+		u.eh.HandleApiError(w, err)
+		return
+	}
+
+	api.Respond(members, http.StatusOK, w)
+}
+
+// swagger:route POST /me/accounts Users joinAccount
+//
+// Accepts an invitation sent to the authenticated user, making them a member of another account
+//
+// responses:
+//  201: accountMemberResponse
+//  400: errorResponse
+//  500: errorResponse
+func (u *Users) JoinAccount(w http.ResponseWriter, r *http.Request) {
+	joinDto, err := getDtoFromBody[dto.JoinAccountDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	// Use up the invitation, so that the token cannot be used by anyone else
+	invitation, err := u.invitationService.ClaimInvitation(joinDto.InvitationToken)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	member, err := u.userService.JoinAccount(user, invitation)
+	if err != nil {
+		if releaseErr := u.invitationService.ReleaseInvitation(invitation); releaseErr != nil {
+			u.log.Errorf("Could not release Invitation{id=%s}: %s", invitation.ID, releaseErr)
+		}
+	}
+
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(member, http.StatusCreated, w)
+}
+
+// swagger:route GET /me Users getMe
+//
+// Returns the authenticated user
+//
+// responses:
+//  200: userResponse
+//  304: notModified
+//  401: errorResponse
+func (u *Users) GetMe(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	api.Respond(user, http.StatusOK, w)
+}
+
+// swagger:route PATCH /me Users updateMe
+//
+// Updates the name and username of the authenticated user; changing the username issues a new discriminator
+//
+// responses:
+//  200: userResponse
+//  400: errorResponse
+//  409: errorResponse
+//  500: errorResponse
+func (u *Users) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	profileDto, err := getDtoFromBody[dto.UpdateProfileDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	updated, err := u.userService.UpdateProfile(user, profileDto.Name, profileDto.Username)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(updated, http.StatusOK, w)
+}
+
+// swagger:route POST /me/password Users changePassword
+//
+// Changes the password of the authenticated user, signing out every other session.
+// A new token is returned for the current session.
+//
+// responses:
+//  200: JWTTokenResponse
+//  400: errorResponse
+//  500: errorResponse
+func (u *Users) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	passwordDto, err := getDtoFromBody[dto.ChangePasswordDto](w, r)
+	if err != nil {
+		return
+	}
+
+	user := getUserFromContext(r.Context())
+
+	updated, err := u.userService.ChangePassword(user, passwordDto.CurrentPassword, passwordDto.NewPassword)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	token, err := u.authService.GenerateJWT(*updated)
+	if err != nil {
+		u.log.Error("Could not generate a token after changing the password: ", err)
+		u.eh.HandleApiError(w, err)
+		return
+	}
+
+	api.Respond(dto.LoginResponseDto{Token: token}, http.StatusOK, w)
+}
+
+// swagger:route GET /account/users Users listAccountUsers
+//
+// Returns every user in the authenticated account, identified by their username#discriminator handle
+//
+// responses:
+//  200: userListResponse
+//  304: notModified
+//  500: errorResponse
+func (u *Users) GetAccountUsers(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+
+	users, err := u.userService.GetAccountUsers(user.AccountId)
+	if err != nil {
+		u.eh.HandleApiError(w, err)
+		return
+	}
+
+	api.Respond(users, http.StatusOK, w)
+}
+
+// swagger:route GET /user/lookup Users lookupUser
+//
+// Finds the user in the authenticated account with the given username#discriminator handle
+//
+// responses:
+//  200: userResponse
+//  304: notModified
+//  400: errorResponse
+//  404: errorResponse
+func (u *Users) LookupUser(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	handle := r.URL.Query().Get("handle")
+
+	found, err := u.userService.GetUserByHandle(handle, user.AccountId)
+	if status := u.eh.HandleApiError(w, err); status != http.StatusOK {
+		return
+	}
+
+	api.Respond(found, http.StatusOK, w)
+}
+
 // Generic Swagger documentation
 
 // swagger:parameters login
@@ -140,11 +510,110 @@ type LoginRequestParameter struct {
 	Body dto.LoginRequestDto
 }
 
+// swagger:parameters twoFactorLogin
+type TwoFactorLoginRequestParameter struct {
+	// The challenge token and second factor
+	//
+	// in: body
+	// required: true
+	Body dto.TwoFactorLoginRequestDto
+}
+
 // swagger:parameters registration
 type RegistrationRequestParameter struct {
-	// The user to be registered to the specified account
+	// The user to be registered to the account they have been invited to
 	//
 	// in: body
 	// required: true
 	Body dto.RegistrationRequestDto
 }
+
+// swagger:parameters changeUserRole
+type RoleUpdateParameter struct {
+	// The ID of the user whose role is to be changed
+	// in: path
+	// required: true
+	ID uint `json:"userId"`
+
+	// The new role of the user
+	//
+	// in: body
+	// required: true
+	Body dto.RoleUpdateDto
+}
+
+// swagger:parameters deactivateUser reactivateUser
+type UserIDParameter struct {
+	// The ID of the specified user
+	// in: path
+	// required: true
+	ID uint `json:"userId"`
+}
+
+// swagger:parameters offboardUser
+type OffboardUserParameter struct {
+	// The ID of the user who is leaving
+	// in: path
+	// required: true
+	ID uint `json:"userId"`
+
+	// The user taking over their work
+	//
+	// in: body
+	// required: true
+	Body dto.OffboardUserDto
+}
+
+// swagger:parameters switchAccount
+type SwitchAccountParameter struct {
+	// The account to act within
+	//
+	// in: body
+	// required: true
+	Body dto.SwitchAccountDto
+}
+
+// swagger:parameters joinAccount
+type JoinAccountParameter struct {
+	// The invitation being accepted
+	//
+	// in: body
+	// required: true
+	Body dto.JoinAccountDto
+}
+
+// swagger:parameters updateMe
+type UpdateProfileParameter struct {
+	// The fields of the profile to be updated
+	//
+	// in: body
+	// required: true
+	Body dto.UpdateProfileDto
+}
+
+// swagger:parameters changePassword
+type ChangePasswordParameter struct {
+	// The current and new passwords
+	//
+	// in: body
+	// required: true
+	Body dto.ChangePasswordDto
+}
+
+// swagger:parameters getProject getStory getTask listTasks
+type CreatorFieldsParameter struct {
+	// The comma separated fields of the creators to respond with; the id is always sent
+	// in: query
+	// required: false
+	// example: name,handle
+	Fields string `json:"fields[user]"`
+}
+
+// swagger:parameters lookupUser
+type UserHandleParameter struct {
+	// The handle of the user, with the # URL encoded
+	// in: query
+	// required: true
+	// example: mike%231
+	Handle string `json:"handle"`
+}
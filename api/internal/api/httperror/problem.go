@@ -0,0 +1,95 @@
+package httperror
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// ContentType the media type of problem responses
+	ContentType = "application/problem+json"
+
+	// RequestIdHeader the response header carrying the ID assigned to the request
+	RequestIdHeader = "X-Request-Id"
+
+	// The prefix of the URI identifying each type of problem, followed by the problem's code
+	typePrefix = "urn:godo:problem:"
+)
+
+// Problem an error response in the problem details format described by RFC 7807
+type Problem struct {
+	// A URI identifying the type of problem
+	Type string `json:"type"`
+
+	// A short summary of the problem
+	Title string `json:"title"`
+
+	// The HTTP status code
+	Status int `json:"status"`
+
+	// An explanation of this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+
+	// Identifies this occurrence of the problem by the ID assigned to the request
+	Instance string `json:"instance"`
+
+	// A stable, machine-readable identifier of the problem, such as project_not_found
+	Code string `json:"code"`
+
+	// The fields of the request which failed validation
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError a field of the request which failed validation
+type FieldError struct {
+	// The path of the field within the request body, such as name or tags[0].name
+	Field string `json:"field"`
+
+	// The rule the field failed, such as required or max
+	Rule string `json:"rule"`
+
+	// A description of why the field failed the rule, such as "name is required"
+	Message string `json:"message"`
+}
+
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   typePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write writes the problem as the response, identifying it by the request's ID
+func (p *Problem) Write(w http.ResponseWriter) {
+	requestId := w.Header().Get(RequestIdHeader)
+	if requestId == "" {
+		requestId = NewRequestId()
+		w.Header().Set(RequestIdHeader, requestId)
+	}
+
+	p.Instance = "urn:uuid:" + requestId
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Println("Could not format problem response: ", err.Error())
+	}
+}
+
+// NewRequestId returns a new ID for identifying a request
+func NewRequestId() string {
+	return uuid.NewV4().String()
+}
+
+// ProblemResponse a response detailing a user or internal server error
+// swagger:response errorResponse
+type ProblemResponse struct {
+	Body Problem
+}
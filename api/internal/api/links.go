@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"godo/internal/repository/entities"
+
+	"github.com/gorilla/mux"
+)
+
+// The names of the routes linked to by the responses, registered in each version by the router builder
+const (
+	RouteAccount  = "account"
+	RouteProjects = "projects"
+	RouteProject  = "project"
+	RouteStories  = "stories"
+	RouteStory    = "story"
+	RouteTasks    = "tasks"
+	RouteTask     = "task"
+)
+
+// RoutesKey the context key of the Routes of the version serving the request
+type RoutesKey struct{}
+
+// Routes builds links to the named routes of a version, such that responses link within the version
+// serving the request
+type Routes struct {
+	router  *mux.Router
+	version Version
+}
+
+func NewRoutes(router *mux.Router, version Version) Routes {
+	return Routes{router: router, version: version}
+}
+
+// RoutesFromContext returns the Routes of the version serving the request; without a version, links
+// are not built
+func RoutesFromContext(ctx context.Context) Routes {
+	if routes, ok := ctx.Value(RoutesKey{}).(Routes); ok {
+		return routes
+	}
+
+	return Routes{}
+}
+
+// RouteName the name of the route within the version; the versions share a router, in which each name
+// must be unique
+func (v Version) RouteName(name string) string {
+	return v.Prefix + " " + name
+}
+
+// Link returns the link to the named route with the given variables, e.g. Link(RouteProject, "id", id),
+// or nil if the version has no such route
+func (r Routes) Link(name string, pairs ...string) *entities.Link {
+	if r.router == nil {
+		return nil
+	}
+
+	route := r.router.Get(r.version.RouteName(name))
+	if route == nil {
+		return nil
+	}
+
+	url, err := route.URLPath(pairs...)
+	if err != nil {
+		return nil
+	}
+
+	return &entities.Link{Href: url.Path}
+}
+
+// LinkAccount adds the links of the account
+func LinkAccount(ctx context.Context, account *entities.Account) {
+	routes := RoutesFromContext(ctx)
+
+	account.Links = &entities.Links{
+		Self: routes.Link(RouteAccount),
+	}
+}
+
+// LinkProject adds the links of the project, and of the stories loaded with it
+func LinkProject(ctx context.Context, project *entities.Project) {
+	routes := RoutesFromContext(ctx)
+
+	project.Links = &entities.Links{
+		Self:       routes.Link(RouteProject, "id", project.ID),
+		Collection: routes.Link(RouteProjects),
+	}
+
+	for i := range project.Stories {
+		LinkStory(ctx, &project.Stories[i])
+		project.Links.Children = appendLink(project.Links.Children, project.Stories[i].Links.Self)
+	}
+}
+
+func LinkProjectInfo(ctx context.Context, projects entities.ProjectInfoList) {
+	routes := RoutesFromContext(ctx)
+
+	for _, project := range projects {
+		project.Links = &entities.Links{
+			Self:       routes.Link(RouteProject, "id", project.ID),
+			Collection: routes.Link(RouteProjects),
+		}
+	}
+}
+
+// LinkStory adds the links of the story, and of the tasks loaded with it
+func LinkStory(ctx context.Context, story *entities.Story) {
+	routes := RoutesFromContext(ctx)
+
+	story.Links = &entities.Links{
+		Self:       routes.Link(RouteStory, "id", story.ID),
+		Parent:     routes.Link(RouteProject, "id", story.ProjectId),
+		Collection: routes.Link(RouteStories),
+	}
+
+	for i := range story.Tasks {
+		LinkTask(ctx, &story.Tasks[i])
+		story.Links.Children = appendLink(story.Links.Children, story.Tasks[i].Links.Self)
+	}
+}
+
+func LinkStoryInfo(ctx context.Context, stories entities.StoryInfoList) {
+	routes := RoutesFromContext(ctx)
+
+	for _, story := range stories {
+		story.Links = &entities.Links{
+			Self:       routes.Link(RouteStory, "id", story.ID),
+			Collection: routes.Link(RouteStories),
+		}
+	}
+}
+
+// LinkTask adds the links of the task
+func LinkTask(ctx context.Context, task *entities.Task) {
+	routes := RoutesFromContext(ctx)
+
+	task.Links = &entities.Links{
+		Self:       routes.Link(RouteTask, "id", task.ID),
+		Parent:     routes.Link(RouteStory, "id", task.StoryId),
+		Collection: routes.Link(RouteTasks),
+	}
+}
+
+func LinkTasks(ctx context.Context, tasks entities.TaskList) {
+	for _, task := range tasks {
+		LinkTask(ctx, task)
+	}
+}
+
+func appendLink(links []entities.Link, link *entities.Link) []entities.Link {
+	if link == nil {
+		return links
+	}
+
+	return append(links, *link)
+}
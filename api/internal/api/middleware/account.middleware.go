@@ -3,10 +3,9 @@ package middleware
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"godo/internal/api"
 	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
 	"net/http"
@@ -14,34 +13,40 @@ import (
 
 type AccountMiddleware struct {
 	log ilog.StdLogger
+	eh  ehand.ErrorHandler
 }
 
 func NewAccountMiddleware(logger ilog.StdLogger) AccountMiddleware {
-	return AccountMiddleware{log: logger}
+	return AccountMiddleware{log: logger, eh: ehand.New()}
 }
 
 func (m *AccountMiddleware) ValidateNewAccountDtoMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var accountDto dto.NewAccountDto
 
-		err := json.NewDecoder(r.Body).Decode(&accountDto)
+		body, err := api.ReadBody(w, r)
+		if err != nil {
+			m.log.Error("The Account data could not be read: ", err)
+			m.eh.HandleApiError(w, err)
+			return
+		}
+
+		err = json.Unmarshal(body, &accountDto)
 		if err != nil {
 			m.log.Error("The Account data was not in the expected JSON format")
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			m.eh.HandleApiError(w, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, err))
 			return
 		}
 
 		err = accountDto.Validate()
 		if err != nil {
 			m.log.Errorf("The Account failed validation: %s", err)
-
-			validationErr := errors.New(fmt.Sprintf("Error validating Account: %s", err.Error()))
-			api.ReturnError(validationErr, http.StatusBadRequest, w)
+			m.eh.HandleApiError(w, err)
 			return
 		}
 
 		// Add the Account dto to the context
-		m.log.Info("Adding NewAccountDto to context ", accountDto)
+		m.log.Infof("Adding NewAccountDto{name=%s} to context", accountDto.Name)
 		ctx := context.WithValue(r.Context(), entities.AccountKey{}, accountDto)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
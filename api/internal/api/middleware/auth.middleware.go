@@ -2,8 +2,7 @@ package middleware
 
 import (
 	"context"
-	"fmt"
-	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
 	"godo/internal/api/services"
 	"godo/internal/auth"
 	"godo/internal/helper/ilog"
@@ -12,19 +11,24 @@ import (
 )
 
 type AuthMiddleware struct {
-	log         ilog.StdLogger
-	authService services.AuthService
-	userService services.UserService
+	log            ilog.StdLogger
+	authService    services.AuthService
+	userService    services.UserService
+	accountService services.AccountService
+	eh             ehand.ErrorHandler
 }
 
 func NewAuthMiddleware(
 	logger ilog.StdLogger,
 	authService services.AuthService,
-	userService services.UserService) AuthMiddleware {
+	userService services.UserService,
+	accountService services.AccountService) AuthMiddleware {
 	return AuthMiddleware{
-		log:         logger,
-		authService: authService,
-		userService: userService,
+		log:            logger,
+		authService:    authService,
+		userService:    userService,
+		accountService: accountService,
+		eh:             ehand.New(),
 	}
 }
 
@@ -37,7 +41,7 @@ func (m *AuthMiddleware) ValidateTokenRequestMiddleware(next http.Handler) http.
 		err := tr.FromJSON(r.Body)
 		if err != nil {
 			m.log.Error("The TokenRequest data was not in the expected JSON format")
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			m.eh.HandleApiError(w, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, err))
 			return
 		}
 
@@ -45,9 +49,7 @@ func (m *AuthMiddleware) ValidateTokenRequestMiddleware(next http.Handler) http.
 		err = tr.Validate()
 		if err != nil {
 			m.log.Errorf("The TokenRequest failed validation: %s", err)
-
-			e := fmt.Errorf("error validating TokenRequest: %s", err)
-			api.ReturnError(e, http.StatusBadRequest, w)
+			m.eh.HandleApiError(w, err)
 			return
 		}
 
@@ -63,51 +65,122 @@ func (m *AuthMiddleware) ValidateTokenRequestMiddleware(next http.Handler) http.
 // AuthenticateRequestMiddleware Used to authenticate a given JWT
 func (m *AuthMiddleware) AuthenticateRequestMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenValue := r.Header.Get("Authorization")
-
-		// Convert the token from `Bearer abc123` to `abc123`
-		// This also validates the given token string value
-		token, err := m.authService.BearerTokenToToken(tokenValue)
+		user, err := m.Authenticate(r.Header.Get("Authorization"))
 		if err != nil {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			m.eh.HandleApiError(w, err)
 			return
 		}
 
-		// Validate the auth token
-		err = m.authService.ValidateTokenClaims(token)
-		if err != nil {
-			m.log.Info("The token is not valid")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+		// Attach the user to the context
+		m.log.Info("Adding user to context ", user)
+		ctx := context.WithValue(r.Context(), entities.UserKey{}, *user)
+		r = r.WithContext(ctx)
 
-		// Fetch the appropriate user
-		claims, err := m.authService.GetClaims(token)
-		if err != nil {
-			m.log.Error("Could not get claims from the token")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		user, err := m.userService.GetUserByEmailAddress(claims.Email)
-		if err != nil {
-			m.log.Error("Could not determine user with email address %s from signed token", claims.Email)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+// Authenticate returns the user acting with the bearer token, such as `Bearer abc123`, within the
+// account it was issued for, or ehand.ErrorUnauthorized
+func (m *AuthMiddleware) Authenticate(tokenValue string) (*entities.User, error) {
+	// Convert the token from `Bearer abc123` to `abc123`
+	// This also validates the given token string value
+	token, err := m.authService.BearerTokenToToken(tokenValue)
+	if err != nil {
+		return nil, ehand.ErrorUnauthorized
+	}
 
-		// Verify the user's JWT account_id is the same as in the database
-		if user.AccountId != claims.AccountId {
-			m.log.Warnf("The user account in the token claims is incorrect. Expected %s but got %s", user.AccountId, claims.AccountId)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	// Validate the auth token
+	err = m.authService.ValidateTokenClaims(token)
+	if err != nil {
+		m.log.Info("The token is not valid")
+		return nil, ehand.ErrorUnauthorized
+	}
+
+	// Fetch the appropriate user
+	claims, err := m.authService.GetClaims(token)
+	if err != nil {
+		m.log.Error("Could not get claims from the token")
+		return nil, ehand.ErrorUnauthorized
+	}
+
+	user, err := m.userService.GetUserByEmailAddress(claims.Email)
+	if err != nil {
+		m.log.Error("Could not determine user with email address %s from signed token", claims.Email)
+		return nil, ehand.ErrorUnauthorized
+	}
+
+	// The user must still be an active member of the account the token was issued for
+	if claims.AccountId == "" {
+		m.log.Warnf("The token for User{id=%d} does not name an account", user.ID)
+		return nil, ehand.ErrorUnauthorized
+	}
+
+	user, err = m.userService.UseAccount(*user, claims.AccountId)
+	if err != nil {
+		m.log.Infof("The token is for an account the user can no longer act within: %s", err)
+		return nil, ehand.ErrorUnauthorized
+	}
+
+	// Tokens issued before the user's sessions were revoked are no longer accepted
+	if user.SessionVersion != claims.SessionVersion {
+		m.log.Infof("The token for User{id=%d} belongs to a revoked session", user.ID)
+		return nil, ehand.ErrorUnauthorized
+	}
+
+	return user, nil
+}
+
+// RequireTwoFactorEnrollmentMiddleware Used to prevent users who have not enabled two-factor
+// authentication from using the API when their account requires it.
+// Must be used after AuthenticateRequestMiddleware.
+func (m *AuthMiddleware) RequireTwoFactorEnrollmentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(entities.UserKey{}).(entities.User)
+
+		if err := m.RequireTwoFactorEnrollment(user); err != nil {
+			m.eh.HandleApiError(w, err)
 			return
 		}
 
-		// Attach the user to the context
-		m.log.Info("Adding user to context ", user)
-		ctx := context.WithValue(r.Context(), entities.UserKey{}, *user)
-		r = r.WithContext(ctx)
-
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireTwoFactorEnrollment returns ehand.ErrorTwoFactorEnrollmentRequired if the user has not
+// enabled two-factor authentication and their account requires it
+func (m *AuthMiddleware) RequireTwoFactorEnrollment(user entities.User) error {
+	if user.TwoFactorEnabled {
+		return nil
+	}
+
+	account, err := m.accountService.GetAccountById(user.AccountId)
+	if err != nil {
+		m.log.Errorf("Could not fetch Account{id=%s} for the authenticated user", user.AccountId)
+		return ehand.ErrorUnauthorized
+	}
+
+	if account.RequireTwoFactor {
+		return ehand.ErrorTwoFactorEnrollmentRequired
+	}
+
+	return nil
+}
+
+// RequirePermission Used to reject requests from users whose role does not grant the permission.
+// Must be used after AuthenticateRequestMiddleware.
+func (m *AuthMiddleware) RequirePermission(permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := r.Context().Value(entities.UserKey{}).(entities.User)
+
+			if !auth.HasPermission(user.Role, permission) {
+				m.log.Infof("User{id=%d} with role %s does not have the %s permission", user.ID, user.Role, permission)
+				m.eh.HandleApiError(w, ehand.ErrorPermissionDenied)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,16 +1,24 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/httperror"
 	"godo/internal/helper/ilog"
 	"net/http"
+
+	"github.com/gorilla/mux"
 )
 
 type GenericMiddleware struct {
 	log ilog.StdLogger
+	eh  ehand.ErrorHandler
 }
 
 func NewGenericMiddleware(logger ilog.StdLogger) GenericMiddleware {
-	return GenericMiddleware{log: logger}
+	return GenericMiddleware{log: logger, eh: ehand.New()}
 }
 
 func (m *GenericMiddleware) LoggingMiddleware(next http.Handler) http.Handler {
@@ -19,3 +27,124 @@ func (m *GenericMiddleware) LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequestIdMiddleware assigns each request an ID, returned in the X-Request-Id header
+// and used to identify the occurrence of any problem in the response
+func (m *GenericMiddleware) RequestIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(httperror.RequestIdHeader, httperror.NewRequestId())
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ConditionalGetMiddleware answers conditional GET requests with 304 Not Modified when the client has
+// the current representation, as identified by the ETag and Last-Modified headers of the response. The
+// handlers of entities set those computed from their versions; any other response is given an ETag
+// computed from its body.
+func (m *GenericMiddleware) ConditionalGetMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		if recorder.status == http.StatusOK {
+			if w.Header().Get("ETag") == "" {
+				w.Header().Set("ETag", api.BodyETag(recorder.body.Bytes()))
+			}
+
+			if api.NotModified(r, w.Header()) {
+				w.Header().Del("Content-Type")
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		if err := recorder.flush(); err != nil {
+			m.log.Error("Could not write the response: ", err)
+		}
+	})
+}
+
+// CompressionMiddleware compresses large responses with gzip or brotli, as accepted by the client, giving
+// them an entity tag of their own; being within ConditionalGetMiddleware, a conditional request is
+// evaluated against the tag of the compressed response the client has
+func (m *GenericMiddleware) CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if w.Header().Get("Content-Encoding") == "" {
+			coding, body, err := api.Compress(r, recorder.body.Bytes())
+			if err != nil {
+				m.log.Error("Could not compress the response: ", err)
+			} else if coding != "" {
+				recorder.body.Reset()
+				recorder.body.Write(body)
+
+				w.Header().Set("Content-Encoding", coding)
+				w.Header().Del("Content-Length")
+
+				if etag := w.Header().Get("ETag"); etag != "" {
+					w.Header().Set("ETag", api.EncodedETag(etag, coding))
+				}
+			}
+		}
+
+		if err := recorder.flush(); err != nil {
+			m.log.Error("Could not write the response: ", err)
+		}
+	})
+}
+
+// NegotiationMiddleware responds 406 Not Acceptable to requests which accept none of the formats their
+// response may be sent in, and otherwise gives the handlers the media ranges accepted by the client, from
+// which the format of the response is chosen; only the responses to GET requests may be lists, such as
+// may be sent as CSV
+func (m *GenericMiddleware) NegotiationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := api.ParseAccept(r)
+		list := r.Method == http.MethodGet
+
+		if !accept.Acceptable(list) {
+			m.log.Infof("%s %s accepts none of the formats of the API: %s", r.Method, r.URL.Path, r.Header.Get("Accept"))
+			w.Header().Add("Vary", "Accept")
+			m.eh.HandleApiError(w, accept.NotAcceptable(list))
+			return
+		}
+
+		next.ServeHTTP(&api.NegotiatedWriter{ResponseWriter: w, Accept: accept}, r)
+	})
+}
+
+// VersionMiddleware makes the API version, and the routes it links to, available to the handlers and,
+// when the version is deprecated, warns clients with the Deprecation, Sunset and successor Link headers
+func (m *GenericMiddleware) VersionMiddleware(version api.Version, router *mux.Router) mux.MiddlewareFunc {
+	routes := api.NewRoutes(router, version)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if version.Deprecated {
+				w.Header().Set("Deprecation", "true")
+
+				if version.Sunset != nil {
+					w.Header().Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+				}
+
+				if version.Successor != "" {
+					w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", version.Successor))
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), api.VersionKey{}, version)
+			ctx = context.WithValue(ctx, api.RoutesKey{}, routes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/httperror"
+	"godo/internal/api/openapi"
+	"godo/internal/helper/ilog"
+	"net/http"
+	"strings"
+)
+
+// The modes of OPENAPI_VALIDATION
+const (
+	OpenApiValidationOff       = "off"
+	OpenApiValidationRequests  = "requests"
+	OpenApiValidationResponses = "responses"
+)
+
+type OpenApiMiddleware struct {
+	log               ilog.StdLogger
+	docs              map[int]*openapi.Document
+	validateResponses bool
+}
+
+// NewOpenApiMiddleware validates requests against the document of their version; in responses mode,
+// meant for development, the responses are validated too and any mismatch logged
+func NewOpenApiMiddleware(logger ilog.StdLogger, docs map[int]*openapi.Document, mode string) OpenApiMiddleware {
+	return OpenApiMiddleware{log: logger, docs: docs, validateResponses: mode == OpenApiValidationResponses}
+}
+
+// ValidateMiddleware rejects requests which do not match their documented operation, such as a
+// body missing a required property; requests to undocumented routes are passed through
+func (m *OpenApiMiddleware) ValidateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := api.VersionFromContext(r.Context())
+
+		doc, ok := m.docs[version.Number]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, version.Prefix)
+
+		input, found, err := doc.ValidateRequest(r, path)
+		if !found {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err != nil {
+			m.log.Infof("%s %s does not match the API document: %s", r.Method, path, err)
+
+			problem := httperror.New(http.StatusBadRequest, ehand.CodeRequestNotValid, "the request does not match the API document")
+			problem.Errors = openapi.FieldErrors(err)
+			problem.Write(w)
+			return
+		}
+
+		if !m.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		if err := doc.ValidateResponse(input, recorder.status, w.Header(), recorder.body.Bytes()); err != nil {
+			m.log.Warnf("The response to %s %s does not match the API document: %s", r.Method, path, err)
+		}
+
+		if err := recorder.flush(); err != nil {
+			m.log.Error("Could not write the response: ", err)
+		}
+	})
+}
+
+// responseRecorder holds back the response such that it can be validated before being written
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	return rr.body.Write(data)
+}
+
+// Unwrap gives the writer the response is written to once flushed
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// flush writes the held back response
+func (rr *responseRecorder) flush() error {
+	rr.ResponseWriter.WriteHeader(rr.status)
+	_, err := rr.ResponseWriter.Write(rr.body.Bytes())
+	return err
+}
@@ -2,9 +2,8 @@ package middleware
 
 import (
 	"encoding/json"
-	"fmt"
-	"godo/internal/api"
 	"godo/internal/api/dto"
+	ehand "godo/internal/api/errorhandler"
 	"godo/internal/helper/ilog"
 	"godo/internal/helper/validate"
 	"net/http"
@@ -12,10 +11,11 @@ import (
 
 type ProjectMiddleware struct {
 	log ilog.StdLogger
+	eh  ehand.ErrorHandler
 }
 
 func NewProjectMiddleware(logger ilog.StdLogger) ProjectMiddleware {
-	return ProjectMiddleware{log: logger}
+	return ProjectMiddleware{log: logger, eh: ehand.New()}
 }
 
 func (m *ProjectMiddleware) ValidateNewProjectDtoMiddleware(next http.Handler) http.Handler {
@@ -25,16 +25,14 @@ func (m *ProjectMiddleware) ValidateNewProjectDtoMiddleware(next http.Handler) h
 		err := json.NewDecoder(r.Body).Decode(&projectDto)
 		if err != nil {
 			m.log.Error("The Project data was not in the expected JSON format")
-			api.ReturnError(err, http.StatusBadRequest, w)
+			m.eh.HandleApiError(w, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, err))
 			return
 		}
 
 		err = validate.Struct(projectDto)
 		if err != nil {
 			m.log.Errorf("The Project failed validation: %s", err)
-
-			e := fmt.Errorf("error validating project: %s", err)
-			api.ReturnError(e, http.StatusBadRequest, w)
+			m.eh.HandleApiError(w, err)
 			return
 		}
 
@@ -2,8 +2,8 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
 	"godo/internal/helper/ilog"
 	"godo/internal/helper/validate"
 	"godo/internal/repository/entities"
@@ -12,10 +12,11 @@ import (
 
 type UserMiddleware struct {
 	log ilog.StdLogger
+	eh  ehand.ErrorHandler
 }
 
 func NewUserMiddleware(logger ilog.StdLogger) ProjectMiddleware {
-	return ProjectMiddleware{log: logger}
+	return ProjectMiddleware{log: logger, eh: ehand.New()}
 }
 
 func (m *UserMiddleware) ValidateUserMiddleware(next http.Handler) http.Handler {
@@ -25,7 +26,7 @@ func (m *UserMiddleware) ValidateUserMiddleware(next http.Handler) http.Handler
 		err := api.FromJSON(user, r.Body)
 		if err != nil {
 			m.log.Error("The User data was not in the expected JSON format")
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			m.eh.HandleApiError(w, ehand.NewRequestError(http.StatusBadRequest, ehand.CodeMalformedJSON, err))
 			return
 		}
 
@@ -33,9 +34,7 @@ func (m *UserMiddleware) ValidateUserMiddleware(next http.Handler) http.Handler
 		err = validate.Struct(user)
 		if err != nil {
 			m.log.Errorf("The User failed validation: %s", err)
-
-			e := fmt.Errorf("error validating user: %s", err)
-			api.ReturnError(e, http.StatusBadRequest, w)
+			m.eh.HandleApiError(w, err)
 			return
 		}
 
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"fmt"
+	ehand "godo/internal/api/errorhandler"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/golang/gddo/httputil/header"
+)
+
+// Format a media type responses may be encoded in and request bodies decoded from, chosen by the Accept
+// and Content-Type headers respectively
+type Format struct {
+	MediaType string
+
+	// Other names of the media type, such as application/x-yaml, accepted as the same format
+	Aliases []string
+
+	// Encode writes the body of a response
+	Encode func(i interface{}, w io.Writer) error
+
+	// ListsOnly whether only lists may be encoded, such as by CSV
+	ListsOnly bool
+
+	// Decode transcodes a request body to JSON, from which it is decoded; nil when request bodies cannot
+	// be sent in the format
+	Decode func(body []byte) ([]byte, error)
+}
+
+// The registered formats, by order of preference when the client prefers none of those it accepts
+var formats []*Format
+
+// RegisterFormat adds the format to those negotiated, replacing any of the same media type
+func RegisterFormat(format Format) {
+	for i, f := range formats {
+		if f.MediaType == format.MediaType {
+			formats[i] = &format
+			return
+		}
+	}
+
+	formats = append(formats, &format)
+}
+
+// FormatOf the format of the media type, such as that of a Content-Type header, or nil if not registered
+func FormatOf(mediaType string) *Format {
+	for _, f := range formats {
+		if f.is(mediaType) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// DecodableMediaTypes the media types request bodies may be sent in
+func DecodableMediaTypes() []string {
+	var mediaTypes []string
+	for _, f := range formats {
+		if f.Decode != nil {
+			mediaTypes = append(mediaTypes, f.MediaType)
+		}
+	}
+
+	return mediaTypes
+}
+
+func (f *Format) is(mediaType string) bool {
+	if strings.EqualFold(f.MediaType, mediaType) {
+		return true
+	}
+
+	for _, alias := range f.Aliases {
+		if strings.EqualFold(alias, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quality the quality the client gives the format, the q of the most specific media range matching it
+func (f *Format) quality(accept []header.AcceptSpec) (q float64, specificity int) {
+	specificity = -1
+	for _, spec := range accept {
+		s := f.matches(spec.Value)
+		if s > specificity {
+			q, specificity = spec.Q, s
+		}
+	}
+
+	return q, specificity
+}
+
+// matches how specifically the media range matches the format; 2 for the media type itself, 1 for its
+// type with any subtype, 0 for any media type and -1 when it does not match
+func (f *Format) matches(mediaRange string) int {
+	if mediaRange == "*/*" {
+		return 0
+	}
+
+	if strings.HasSuffix(mediaRange, "/*") {
+		prefix := strings.TrimSuffix(mediaRange, "*")
+		if strings.HasPrefix(strings.ToLower(f.MediaType), strings.ToLower(prefix)) {
+			return 1
+		}
+
+		return -1
+	}
+
+	if f.is(mediaRange) {
+		return 2
+	}
+
+	return -1
+}
+
+// Accept the media ranges a client accepts responses in, by the Accept header of its request
+type Accept []header.AcceptSpec
+
+// ParseAccept reads the Accept header of the request; without it, any format is accepted
+func ParseAccept(r *http.Request) Accept {
+	accept := Accept(header.ParseAccept(r.Header, "Accept"))
+	if len(accept) == 0 {
+		return Accept{{Value: "*/*", Q: 1}}
+	}
+
+	return accept
+}
+
+// Choose the format the client most prefers of those which may encode the value, or nil if it accepts
+// none of them
+func (a Accept) Choose(i interface{}) *Format {
+	return a.choose(isList(i))
+}
+
+// Acceptable whether the client accepts any of the formats of a response, which may be a list
+func (a Accept) Acceptable(list bool) bool {
+	return a.choose(list) != nil
+}
+
+func (a Accept) choose(list bool) *Format {
+	var chosen *Format
+	var chosenQ float64
+	chosenSpecificity := -1
+
+	for _, f := range formats {
+		if f.ListsOnly && !list {
+			continue
+		}
+
+		q, specificity := f.quality(a)
+		if specificity < 0 || q <= 0 {
+			continue
+		}
+
+		if q > chosenQ || (q == chosenQ && specificity > chosenSpecificity) {
+			chosen, chosenQ, chosenSpecificity = f, q, specificity
+		}
+	}
+
+	return chosen
+}
+
+// NotAcceptable the error responded with when the client accepts none of the formats of a response, which
+// may be a list
+func (a Accept) NotAcceptable(list bool) error {
+	var mediaTypes []string
+	for _, f := range formats {
+		if !f.ListsOnly || list {
+			mediaTypes = append(mediaTypes, f.MediaType)
+		}
+	}
+
+	err := fmt.Errorf("the response can only be sent as one of %s", strings.Join(mediaTypes, ", "))
+	return ehand.NewRequestError(http.StatusNotAcceptable, ehand.CodeNotAcceptable, err)
+}
+
+// isList whether the value is a slice or array, once dereferenced
+func isList(i interface{}) bool {
+	v := reflect.ValueOf(i)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+
+		v = v.Elem()
+	}
+
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// NegotiatedWriter a response writer carrying the media ranges the client accepts, with which the
+// negotiation middleware wraps that of each request, such that Send encodes the response as the client
+// prefers
+type NegotiatedWriter struct {
+	http.ResponseWriter
+	Accept Accept
+}
+
+// acceptOf the media ranges accepted by the client the response is written to, found by unwrapping the
+// writers wrapping its NegotiatedWriter; any format is accepted by those not negotiated
+func acceptOf(w http.ResponseWriter) Accept {
+	for {
+		if negotiated, ok := w.(*NegotiatedWriter); ok {
+			return negotiated.Accept
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return Accept{{Value: "*/*", Q: 1}}
+		}
+
+		w = unwrapper.Unwrap()
+	}
+}
@@ -0,0 +1,281 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"godo/internal/api"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/ghodss/yaml"
+)
+
+// Matches the parameters of a path template, such as {projectId} or, in a mux route, {id:[0-9]+}
+var pathParameter = regexp.MustCompile(`\{([^{}:]+)(?::[^{}]*(?:\{[^{}]*\}[^{}]*)*)?\}`)
+
+func init() {
+	// go-swagger formats integers by their Go type, such as uint8, which OpenAPI does not define
+	openapi3.SchemaFormatValidationDisabled = true
+
+	// Bodies in the other formats of the API are validated by their JSON, as they are decoded by the handlers
+	for _, mediaType := range api.DecodableMediaTypes() {
+		if mediaType != api.JSONFormat.MediaType {
+			openapi3filter.RegisterBodyDecoder(mediaType, transcodedBodyDecoder)
+		}
+	}
+}
+
+// transcodedBodyDecoder decodes a body by its JSON, transcoded from the format of its Content-Type
+func transcodedBodyDecoder(body io.Reader, header http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	format := api.FormatOf(mediaType)
+	if format == nil || format.Decode == nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindUnsupportedFormat}
+	}
+
+	data, err := io.ReadAll(body)
+	if err == nil {
+		data, err = format.Decode(data)
+	}
+
+	var value interface{}
+	if err == nil {
+		err = json.Unmarshal(data, &value)
+	}
+
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+
+	return value, nil
+}
+
+// Document the OpenAPI document describing a version of the API, against which requests and
+// responses are validated
+type Document struct {
+	spec       *openapi3.T
+	operations []operation
+}
+
+// An operation of the document, matched to requests by its method and path
+type operation struct {
+	route      *routers.Route
+	pattern    *regexp.Regexp
+	parameters []string
+}
+
+// Load reads the swagger 2.0 document in file, e.g. ./swagger/v1.yaml
+func Load(file string) (*Document, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var swagger openapi2.T
+	if err := yaml.Unmarshal(data, &swagger); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", file, err)
+	}
+
+	spec, err := openapi2conv.ToV3(&swagger)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert %s to OpenAPI 3: %w", file, err)
+	}
+
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("%s is not valid: %w", file, err)
+	}
+
+	addProducedMediaTypes(spec, swagger.Produces)
+
+	doc := &Document{spec: spec}
+	for path, item := range spec.Paths {
+		for method, op := range item.Operations() {
+			doc.operations = append(doc.operations, operation{
+				route: &routers.Route{
+					Spec:      spec,
+					Path:      path,
+					PathItem:  item,
+					Method:    method,
+					Operation: op,
+				},
+				pattern:    templatePattern(path),
+				parameters: templateParameters(path),
+			})
+		}
+	}
+
+	return doc, nil
+}
+
+// Operations the method and normalised path of every operation in the document, such as
+// "GET /project/{}", sorted
+func (d *Document) Operations() []string {
+	operations := make([]string, 0, len(d.operations))
+	for _, op := range d.operations {
+		operations = append(operations, OperationKey(op.route.Method, op.route.Path))
+	}
+
+	sort.Strings(operations)
+	return operations
+}
+
+// OperationKey identifies an operation by its method and path, with the names and patterns of the
+// path's parameters removed such that documented and routed paths can be compared
+func OperationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + pathParameter.ReplaceAllString(path, "{}")
+}
+
+// ValidateRequest validates the request to path, relative to the version's prefix, against its
+// documented operation; found is false when the operation is not documented
+func (d *Document) ValidateRequest(r *http.Request, path string) (input *openapi3filter.RequestValidationInput, found bool, err error) {
+	route, parameters, found := d.find(r.Method, path)
+	if !found {
+		return nil, false, nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+
+	input = &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: parameters,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			// Patch documents are validated by the handler once applied
+			ExcludeRequestBody: strings.HasSuffix(contentType, "patch+json"),
+			MultiError:         true,
+			// Authentication is the concern of the auth middleware
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+
+	// Like the handlers, assume JSON when the client does not say, and take the other names of a format,
+	// such as application/x-yaml, to be the media type documented
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if contentType == "" && r.ContentLength != 0 {
+		r.Header.Set("Content-Type", "application/json")
+		defer r.Header.Del("Content-Type")
+	} else if format := api.FormatOf(mediaType); format != nil && format.MediaType != mediaType {
+		r.Header.Set("Content-Type", format.MediaType)
+		defer r.Header.Set("Content-Type", contentType)
+	}
+
+	return input, true, openapi3filter.ValidateRequest(r.Context(), input)
+}
+
+// ValidateResponse validates the response to a request validated by ValidateRequest against the
+// responses documented for its operation; the bodies of formats which cannot be decoded, such as CSV,
+// are not validated
+func (d *Document) ValidateResponse(input *openapi3filter.RequestValidationInput, status int, header http.Header, body []byte) error {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	format := api.FormatOf(mediaType)
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 header,
+		Options: &openapi3filter.Options{
+			ExcludeResponseBody:   format != nil && format.Decode == nil,
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	responseInput.SetBodyBytes(body)
+
+	return openapi3filter.ValidateResponse(input.Request.Context(), responseInput)
+}
+
+func (d *Document) find(method, path string) (*routers.Route, map[string]string, bool) {
+	for _, op := range d.operations {
+		if op.route.Method != method {
+			continue
+		}
+
+		values := op.pattern.FindStringSubmatch(path)
+		if values == nil {
+			continue
+		}
+
+		parameters := make(map[string]string, len(op.parameters))
+		for i, name := range op.parameters {
+			parameters[name] = values[i+1]
+		}
+
+		return op.route, parameters, true
+	}
+
+	return nil, nil, false
+}
+
+// templatePattern matches the paths of a template, such as /project/{projectId}
+func templatePattern(path string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, match := range pathParameter.FindAllStringIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:match[0]]))
+		pattern.WriteString("([^/]+)")
+		last = match[1]
+	}
+
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+	pattern.WriteString("$")
+
+	return regexp.MustCompile(pattern.String())
+}
+
+// templateParameters the names of the parameters of a template, in order
+func templateParameters(path string) []string {
+	var names []string
+	for _, match := range pathParameter.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+
+	return names
+}
+
+// addProducedMediaTypes documents the responses in every media type the API produces, such as
+// application/problem+json, as swagger 2.0 declares them once for the whole document
+func addProducedMediaTypes(spec *openapi3.T, produces []string) {
+	for _, item := range spec.Paths {
+		for _, op := range item.Operations() {
+			for _, response := range op.Responses {
+				if response.Value == nil {
+					continue
+				}
+
+				addMediaTypes(response.Value, produces)
+			}
+		}
+	}
+
+	for _, response := range spec.Components.Responses {
+		if response.Value != nil {
+			addMediaTypes(response.Value, produces)
+		}
+	}
+}
+
+func addMediaTypes(response *openapi3.Response, produces []string) {
+	mediaType := response.Content.Get("application/json")
+	if mediaType == nil {
+		return
+	}
+
+	for _, contentType := range produces {
+		if response.Content.Get(contentType) == nil {
+			response.Content[contentType] = mediaType
+		}
+	}
+}
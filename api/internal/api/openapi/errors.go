@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"godo/internal/api/httperror"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// FieldErrors describes the parts of the request which failed validation against the document
+func FieldErrors(err error) []httperror.FieldError {
+	var fieldErrors []httperror.FieldError
+
+	// Matched by type rather than errors.As, which finds the errors nested within a MultiError
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range err {
+			fieldErrors = append(fieldErrors, FieldErrors(err)...)
+		}
+	case *openapi3filter.RequestError:
+		fieldErrors = append(fieldErrors, requestFieldErrors(err)...)
+	default:
+		fieldErrors = append(fieldErrors, httperror.FieldError{Message: err.Error()})
+	}
+
+	return fieldErrors
+}
+
+func requestFieldErrors(err *openapi3filter.RequestError) []httperror.FieldError {
+	field := "body"
+	if err.Parameter != nil {
+		field = err.Parameter.Name
+	}
+
+	switch cause := err.Err.(type) {
+	case openapi3.MultiError:
+		var fieldErrors []httperror.FieldError
+		for _, err := range cause {
+			if schemaErr, ok := err.(*openapi3.SchemaError); ok {
+				fieldErrors = append(fieldErrors, schemaFieldError(field, schemaErr))
+			}
+		}
+
+		return fieldErrors
+	case *openapi3.SchemaError:
+		return []httperror.FieldError{schemaFieldError(field, cause)}
+	}
+
+	message := err.Reason
+	if message == "" && err.Err != nil {
+		message = err.Err.Error()
+	}
+
+	return []httperror.FieldError{{Field: field, Message: message}}
+}
+
+// schemaFieldError names the field by its path within the body, such as tags.0.name
+func schemaFieldError(field string, err *openapi3.SchemaError) httperror.FieldError {
+	if path := err.JSONPointer(); field == "body" && len(path) > 0 {
+		field = strings.Join(path, ".")
+	}
+
+	return httperror.FieldError{
+		Field:   field,
+		Rule:    err.SchemaField,
+		Message: err.Reason,
+	}
+}
@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The sections of a swagger 2.0 document holding the objects operations refer to with $ref
+var referencedSections = []string{"definitions", "parameters", "responses"}
+
+// Prune reduces the swagger 2.0 document scanned from the handlers, which describes every route of every
+// version, to the document of a version: only the operations routed by it, keyed as by OperationKey, and
+// the definitions, parameters and responses they refer to, served under its base path. The order of the
+// document is kept, such that it is written as go-swagger writes it.
+func Prune(scanned []byte, basePath string, routed map[string]bool) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(scanned, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse the scanned document: %w", err)
+	}
+
+	set(&doc, "basePath", basePath)
+
+	if paths, ok := get(doc, "paths").(yaml.MapSlice); ok {
+		set(&doc, "paths", prunePaths(paths, routed))
+	}
+
+	// The objects referred to by the operations, and by those objects in turn
+	referenced := make(map[string]bool)
+	pending := refs(get(doc, "paths"))
+	for len(pending) > 0 {
+		ref := pending[0]
+		pending = pending[1:]
+
+		if referenced[ref] {
+			continue
+		}
+
+		referenced[ref] = true
+		pending = append(pending, refs(resolve(doc, ref))...)
+	}
+
+	for _, section := range referencedSections {
+		objects, ok := get(doc, section).(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		kept := yaml.MapSlice{}
+		for _, object := range objects {
+			if referenced[fmt.Sprintf("#/%s/%s", section, object.Key)] {
+				kept = append(kept, object)
+			}
+		}
+
+		set(&doc, section, kept)
+	}
+
+	// Long descriptions are not wrapped, as go-swagger does not wrap them
+	yaml.FutureLineWrap()
+	return yaml.Marshal(doc)
+}
+
+// prunePaths the paths with only their routed operations, and without the paths left with none
+func prunePaths(paths yaml.MapSlice, routed map[string]bool) yaml.MapSlice {
+	kept := yaml.MapSlice{}
+	for _, path := range paths {
+		item, ok := path.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		operations := yaml.MapSlice{}
+		hasOperation := false
+		for _, field := range item {
+			key := fmt.Sprint(field.Key)
+			if isMethod(key) {
+				if !routed[OperationKey(key, fmt.Sprint(path.Key))] {
+					continue
+				}
+
+				hasOperation = true
+			}
+
+			operations = append(operations, field)
+		}
+
+		if hasOperation {
+			kept = append(kept, yaml.MapItem{Key: path.Key, Value: operations})
+		}
+	}
+
+	return kept
+}
+
+func isMethod(key string) bool {
+	switch strings.ToLower(key) {
+	case "get", "put", "post", "delete", "options", "head", "patch":
+		return true
+	}
+
+	return false
+}
+
+// refs every $ref within the value
+func refs(value interface{}) []string {
+	var found []string
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			if ref, ok := item.Value.(string); ok && item.Key == "$ref" {
+				found = append(found, ref)
+			} else {
+				found = append(found, refs(item.Value)...)
+			}
+		}
+
+	case []interface{}:
+		for _, item := range v {
+			found = append(found, refs(item)...)
+		}
+	}
+
+	return found
+}
+
+// resolve the object the local $ref, such as #/definitions/Project, refers to; nil if there is none
+func resolve(doc yaml.MapSlice, ref string) interface{} {
+	var value interface{} = doc
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := value.(yaml.MapSlice)
+		if !ok {
+			return nil
+		}
+
+		value = get(object, key)
+	}
+
+	return value
+}
+
+func get(object yaml.MapSlice, key string) interface{} {
+	for _, item := range object {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+
+	return nil
+}
+
+// set replaces the value of the key, or adds the key when the object has none
+func set(object *yaml.MapSlice, key string, value interface{}) {
+	for i, item := range *object {
+		if item.Key == key {
+			(*object)[i].Value = value
+			return
+		}
+	}
+
+	*object = append(*object, yaml.MapItem{Key: key, Value: value})
+}
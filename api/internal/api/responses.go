@@ -1,22 +1,114 @@
 package api
 
 import (
-	"godo/internal/api/httperror"
+	"bytes"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/repository/entities"
 	"log"
 	"net/http"
 )
 
-func Respond(i interface{}, status int, w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+// Response a response being built, written by Send; e.g. that of a created project
+//
+//	api.NewResponse(w).Created(project.Links.Self).Send(project)
+type Response struct {
+	w          http.ResponseWriter
+	status     int
+	expansion  *Expansion
+	validators *Validators
+}
+
+func NewResponse(w http.ResponseWriter) *Response {
+	return &Response{w: w, status: http.StatusOK}
+}
+
+// Status sets the status of the response, 200 OK unless set
+func (r *Response) Status(status int) *Response {
+	r.status = status
+	return r
+}
+
+// Header sets the header of the response
+func (r *Response) Header(key, value string) *Response {
+	r.w.Header().Set(key, value)
+	return r
+}
+
+// Created responds 201 Created, pointing to the created resource with the Location header
+func (r *Response) Created(location *entities.Link) *Response {
+	if location != nil {
+		r.Header("Location", location.Href)
+	}
+
+	return r.Status(http.StatusCreated)
+}
+
+// Validators sets the ETag and Last-Modified headers, against which conditional requests for the
+// response are evaluated; see middleware.ConditionalGetMiddleware
+func (r *Response) Validators(v *Validators) *Response {
+	r.validators = v
+	return r
+}
+
+// Expand sends only the relations and fields of the body asked for by the request
+func (r *Response) Expand(expansion *Expansion) *Response {
+	r.expansion = expansion
+	return r
+}
 
-	err := ToJSON(i, w)
-	if err != nil {
-		log.Println("Could not format JSON response: ", err.Error())
+// Send writes the response, with the body encoded in the format the client most prefers of those it
+// accepts; 406 Not Acceptable is responded with when it accepts none of them. Responses which have no
+// body, 204 No Content and 304 Not Modified, are written without one.
+func (r *Response) Send(i interface{}) {
+	if r.status == http.StatusNoContent || r.status == http.StatusNotModified {
+		r.w.WriteHeader(r.status)
+		return
 	}
+
+	if selected, err := r.expansion.Select(i); err != nil {
+		log.Println("Could not select the fields of the response: ", err.Error())
+	} else {
+		i = selected
+	}
+
+	accept := acceptOf(r.w)
+	r.w.Header().Add("Vary", "Accept")
+
+	format := accept.Choose(i)
+	if format == nil {
+		ehand.New().HandleApiError(r.w, accept.NotAcceptable(isList(i)))
+		return
+	}
+
+	// Encoded before the status is written, such that a body which cannot be encoded is an error
+	var body bytes.Buffer
+	if err := format.Encode(i, &body); err != nil {
+		log.Printf("Could not format %s response: %s", format.MediaType, err.Error())
+		ehand.New().HandleApiError(r.w, err)
+		return
+	}
+
+	if r.validators != nil {
+		r.Header("ETag", r.validators.ETag(format.MediaType))
+
+		if lastModified := r.validators.LastModified(); !lastModified.IsZero() {
+			r.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	r.w.Header().Set("Content-Type", format.MediaType)
+	r.w.WriteHeader(r.status)
+
+	if _, err := r.w.Write(body.Bytes()); err != nil {
+		log.Printf("Could not write %s response: %s", format.MediaType, err.Error())
+	}
+}
+
+func Respond(i interface{}, status int, w http.ResponseWriter) {
+	NewResponse(w).Status(status).Send(i)
 }
 
-func ReturnError(message error, status int, w http.ResponseWriter) {
-	httpErr := httperror.New(status, message.Error())
-	Respond(httpErr, status, w)
+// NoContent responds 204 No Content, such as once a resource has been deleted
+func NoContent(w http.ResponseWriter) {
+	NewResponse(w).Status(http.StatusNoContent).Send(nil)
 }
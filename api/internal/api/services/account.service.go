@@ -5,51 +5,187 @@ import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
 	"godo/internal/repository/entities"
+	"time"
 )
 
 type AccountService interface {
-	CreateAccount(newAccount *entities.Account) (*entities.Account, error)
 	AccountExists(accountId string) (bool, error)
 	AccountWithEmailAddressExists(email string) (bool, error)
+	GetAccountById(accountId string) (*entities.Account, error)
+	SetTwoFactorRequired(accountId string, required bool) error
+	UpdateAccount(accountId string, name, email, timeZone *string) (*entities.Account, error)
+	ScheduleDeletion(accountId string) (*entities.Account, error)
+	CancelDeletion(accountId string) (*entities.Account, error)
+	PurgeDueAccounts() (int, error)
+	BackfillOwners() (int64, error)
 }
 
 type accountService struct {
-	log   ilog.StdLogger
-	query repository.AccountQuery
+	log                 ilog.StdLogger
+	query               repository.AccountQuery
+	deletionGracePeriod time.Duration
 }
 
-func NewAccountService(accountQuery repository.AccountQuery, logger ilog.StdLogger) AccountService {
+// NewAccountService creates the service managing accounts.
+// Accounts scheduled for deletion are erased once the deletionGracePeriod has passed.
+func NewAccountService(
+	accountQuery repository.AccountQuery,
+	deletionGracePeriod time.Duration,
+	logger ilog.StdLogger) AccountService {
+
 	return &accountService{
-		query: accountQuery,
-		log:   logger,
+		query:               accountQuery,
+		deletionGracePeriod: deletionGracePeriod,
+		log:                 logger,
+	}
+}
+
+func (a *accountService) AccountExists(accountId string) (bool, error) {
+	return a.query.AccountExists(accountId)
+}
+
+func (a *accountService) AccountWithEmailAddressExists(email string) (bool, error) {
+	return a.query.AccountWithEmailAddressExists(email)
+}
+
+func (a *accountService) GetAccountById(accountId string) (*entities.Account, error) {
+	account, err := a.query.GetAccountById(accountId)
+	if err != nil {
+		a.log.Debugf("Account with accountId %s not found", accountId)
+		return nil, ehand.ErrorAccountNotFound
+	}
+
+	return account, nil
+}
+
+func (a *accountService) SetTwoFactorRequired(accountId string, required bool) error {
+	account, err := a.GetAccountById(accountId)
+	if err != nil {
+		return err
+	}
+
+	account.RequireTwoFactor = required
+	return a.query.UpdateAccount(account)
+}
+
+// UpdateAccount updates the fields of the account which are given
+func (a *accountService) UpdateAccount(accountId string, name, email, timeZone *string) (*entities.Account, error) {
+	account, err := a.GetAccountById(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil {
+		account.Name = *name
+	}
+
+	if email != nil && *email != account.Email {
+		exists, err := a.AccountWithEmailAddressExists(*email)
+		if err != nil {
+			return nil, ehand.ErrorAccountNotUpdated
+		}
+
+		if exists {
+			return nil, ehand.ErrorAccountAlreadyExists
+		}
+
+		account.Email = *email
 	}
+
+	if timeZone != nil {
+		if _, err := time.LoadLocation(*timeZone); err != nil || *timeZone == "" || *timeZone == "Local" {
+			return nil, ehand.ErrorTimeZoneNotValid
+		}
+
+		account.TimeZone = *timeZone
+	}
+
+	if err := a.query.UpdateAccount(account); err != nil {
+		a.log.Errorf("Could not update Account{id=%s}: %s", accountId, err)
+		return nil, ehand.ErrorAccountNotUpdated
+	}
+
+	return account, nil
 }
 
-func (a *accountService) CreateAccount(newAccount *entities.Account) (*entities.Account, error) {
-	// Check if the account already exists
-	exists, err := a.AccountWithEmailAddressExists(newAccount.Email)
+// ScheduleDeletion schedules the account and everything in it to be erased once the grace period has passed
+func (a *accountService) ScheduleDeletion(accountId string) (*entities.Account, error) {
+	account, err := a.GetAccountById(accountId)
 	if err != nil {
 		return nil, err
 	}
 
-	if exists {
-		return nil, ehand.ErrorAccountAlreadyExists
+	if account.DeletionScheduledAt != nil {
+		return nil, ehand.ErrorAccountDeletionScheduled
+	}
+
+	deleteAt := time.Now().Add(a.deletionGracePeriod)
+	account.DeletionScheduledAt = &deleteAt
+
+	if err := a.query.UpdateAccount(account); err != nil {
+		a.log.Errorf("Could not schedule the deletion of Account{id=%s}: %s", accountId, err)
+		return nil, ehand.ErrorAccountNotUpdated
 	}
 
-	account, err := a.query.CreateAccount(newAccount)
+	a.log.Infof("Account{id=%s} is scheduled for deletion at %s", accountId, deleteAt)
+	return account, nil
+}
 
+func (a *accountService) CancelDeletion(accountId string) (*entities.Account, error) {
+	account, err := a.GetAccountById(accountId)
 	if err != nil {
-		a.log.Error("Issue creating Account: ", err)
-		return nil, ehand.ErrorAccountNotCreated
+		return nil, err
+	}
+
+	if account.DeletionScheduledAt == nil {
+		return nil, ehand.ErrorAccountDeletionNotScheduled
 	}
 
+	account.DeletionScheduledAt = nil
+
+	if err := a.query.UpdateAccount(account); err != nil {
+		a.log.Errorf("Could not cancel the deletion of Account{id=%s}: %s", accountId, err)
+		return nil, ehand.ErrorAccountNotUpdated
+	}
+
+	a.log.Infof("The deletion of Account{id=%s} has been cancelled", accountId)
 	return account, nil
 }
 
-func (a *accountService) AccountExists(accountId string) (bool, error) {
-	return a.query.AccountExists(accountId)
+// PurgeDueAccounts erases every account whose deletion grace period has passed, returning the number erased;
+// those whose deletion is cancelled meanwhile are kept
+func (a *accountService) PurgeDueAccounts() (int, error) {
+	now := time.Now()
+	accounts, err := a.query.GetAccountsDueForDeletion(now)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, account := range accounts {
+		erased, err := a.query.PurgeAccount(account.ID, now)
+		if err != nil {
+			a.log.Errorf("Could not purge Account{id=%s}: %s", account.ID, err)
+			continue
+		}
+
+		if erased {
+			purged++
+		}
+	}
+
+	return purged, nil
 }
 
-func (a *accountService) AccountWithEmailAddressExists(email string) (bool, error) {
-	return a.query.AccountWithEmailAddressExists(email)
+// BackfillOwners gives every account without an owner one, its earliest user, returning the number of
+// accounts given one; accounts created before account roles were added have none, every user being a
+// member
+func (a *accountService) BackfillOwners() (int64, error) {
+	promoted, err := a.query.PromoteFirstMembersToOwner()
+	if err != nil {
+		a.log.Error("Could not promote the owners of the accounts without one: ", err)
+		return 0, err
+	}
+
+	return promoted, nil
 }
@@ -2,57 +2,125 @@ package services
 
 import (
 	"errors"
+	"godo/internal/auth"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
+	"godo/internal/repository/entities"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 type AuthService interface {
-	GenerateJWT(email string, username string, accountId string) (string, error)
+	GenerateJWT(user entities.User) (string, error)
+	GenerateChallengeToken(user entities.User) (string, error)
+	GetChallengeClaims(signedToken string) (*JWTClaim, error)
 	ValidateTokenClaims(signedToken string) (err error)
 	GetClaims(signedToken string) (*JWTClaim, error)
 	BearerTokenToToken(token string) (string, error)
 }
 
 type authService struct {
-	jwtSecret []byte
-	log       ilog.StdLogger
-	query     repository.ApiUserQuery
+	keys  SigningKeyService
+	log   ilog.StdLogger
+	query repository.ApiUserQuery
 }
 
-func NewAuthService(apiUserQuery repository.ApiUserQuery, jwtSecret []byte, logger ilog.StdLogger) AuthService {
+func NewAuthService(apiUserQuery repository.ApiUserQuery, keys SigningKeyService, logger ilog.StdLogger) AuthService {
 	return &authService{
-		query:     apiUserQuery,
-		jwtSecret: jwtSecret,
-		log:       logger,
+		query: apiUserQuery,
+		keys:  keys,
+		log:   logger,
 	}
 }
 
+// Purposes of tokens which may not be used to authenticate API requests
+const challengeTokenPurpose = "2fa_challenge"
+
 type JWTClaim struct {
 	Username  string `json:"username"`
 	Email     string `json:"email"`
 	AccountId string `json:"account_id"`
-	jwt.StandardClaims
+	Role      string `json:"role,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+
+	// SessionVersion must match the user's session version for the token to be accepted
+	SessionVersion uint `json:"session_version,omitempty"`
+
+	// TwoFactorFailures the consecutive two-factor codes the user had failed when a challenge token was
+	// issued, from which the codes failed with the challenge are counted
+	TwoFactorFailures uint `json:"two_factor_failures,omitempty"`
+	jwt.RegisteredClaims
 }
 
-func (s *authService) GenerateJWT(email string, username string, accountId string) (token string, err error) {
+func (s *authService) GenerateJWT(user entities.User) (token string, err error) {
 	expirationTime := time.Now().Add(6 * time.Hour)
 
 	claims := JWTClaim{
-		Email:     email,
-		Username:  username,
-		AccountId: accountId,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
+		Email:     user.Email,
+		Username:  user.Username,
+		AccountId: user.AccountId,
+		Role:      user.Role.String(),
+
+		SessionVersion: user.SessionVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
+	}
+
+	return s.sign(claims)
+}
+
+// GenerateChallengeToken creates a short-lived token proving that the user has passed the
+// password step of logging in to the account they are acting within; it must be exchanged
+// along with a second factor for a JWT
+func (s *authService) GenerateChallengeToken(user entities.User) (token string, err error) {
+	expirationTime := time.Now().Add(5 * time.Minute)
+
+	claims := JWTClaim{
+		Email:     user.Email,
+		AccountId: user.AccountId,
+		Purpose:   challengeTokenPurpose,
+
+		TwoFactorFailures: user.TwoFactorFailures,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	token, err = t.SignedString(s.jwtSecret)
-	return
+	return s.sign(claims)
+}
+
+func (s *authService) sign(claims JWTClaim) (string, error) {
+	key, err := s.keys.SigningKey()
+	if err != nil {
+		s.log.Error("Could not obtain a signing key: ", err)
+		return "", err
+	}
+
+	return key.Sign(claims)
+}
+
+func (s *authService) GetChallengeClaims(signedToken string) (*JWTClaim, error) {
+	claims, err := s.GetClaims(signedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != challengeTokenPurpose {
+		s.log.Info("The token is not a two-factor challenge token")
+		return nil, errors.New("the token is not a two-factor challenge token")
+	}
+
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Before(time.Now()) {
+		s.log.Info("The challenge token has expired")
+		return nil, errors.New("challenge token has expired")
+	}
+
+	return claims, nil
 }
 
 func (s *authService) ValidateTokenClaims(signedToken string) (err error) {
@@ -61,11 +129,17 @@ func (s *authService) ValidateTokenClaims(signedToken string) (err error) {
 		return err
 	}
 
-	if claims.ExpiresAt < time.Now().Local().Unix() {
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Before(time.Now()) {
 		s.log.Info("The token has expired")
 		return errors.New("token has expired")
 	}
 
+	// Challenge tokens must not be usable as session tokens
+	if claims.Purpose != "" {
+		s.log.Infof("A token with the purpose %s cannot be used to authenticate", claims.Purpose)
+		return errors.New("the token cannot be used to authenticate")
+	}
+
 	return nil
 }
 
@@ -73,9 +147,8 @@ func (s *authService) GetClaims(signedToken string) (*JWTClaim, error) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&JWTClaim{},
-		func(token *jwt.Token) (interface{}, error) {
-			return s.jwtSecret, nil
-		},
+		s.verificationKey,
+		jwt.WithValidMethods([]string{auth.AlgorithmRS256, auth.AlgorithmEdDSA, auth.AlgorithmHS512}),
 	)
 
 	if err != nil {
@@ -93,6 +166,23 @@ func (s *authService) GetClaims(signedToken string) (*JWTClaim, error) {
 	return claims, nil
 }
 
+// verificationKey selects the key matching the kid in the token header,
+// ensuring the token is signed with the algorithm that key belongs to
+func (s *authService) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := s.keys.VerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("the token algorithm does not match the signing key")
+	}
+
+	return key.VerificationKey()
+}
+
 func (s *authService) BearerTokenToToken(token string) (string, error) {
 	err := s.validateTokenString(token)
 	if err != nil {
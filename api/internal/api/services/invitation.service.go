@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/mail"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const invitationLifetime = 7 * 24 * time.Hour
+
+type InvitationService interface {
+	CreateInvitation(inviter entities.User, email string, role enums.AccountRole) (*entities.Invitation, error)
+	GetInvitations(accountId string) (entities.InvitationList, error)
+	ResendInvitation(invitationId, accountId string) (*entities.Invitation, error)
+	RevokeInvitation(invitationId, accountId string) error
+	ClaimInvitation(token string) (*entities.Invitation, error)
+	ReleaseInvitation(invitation *entities.Invitation) error
+}
+
+type invitationService struct {
+	log          ilog.StdLogger
+	query        repository.InvitationQuery
+	userQuery    repository.ApiUserQuery
+	accountQuery repository.AccountQuery
+	mailer       mail.Mailer
+	webUIURL     string
+}
+
+func NewInvitationService(
+	invitationQuery repository.InvitationQuery,
+	userQuery repository.ApiUserQuery,
+	accountQuery repository.AccountQuery,
+	mailer mail.Mailer,
+	webUIURL string,
+	logger ilog.StdLogger) InvitationService {
+
+	return &invitationService{
+		log:          logger,
+		query:        invitationQuery,
+		userQuery:    userQuery,
+		accountQuery: accountQuery,
+		mailer:       mailer,
+		webUIURL:     strings.TrimSuffix(webUIURL, "/"),
+	}
+}
+
+func (s *invitationService) CreateInvitation(
+	inviter entities.User,
+	email string,
+	role enums.AccountRole) (*entities.Invitation, error) {
+
+	// Ownership can only be given by changing the role of an existing user
+	if !role.IsValid() || role == enums.Owner {
+		return nil, ehand.ErrorInvitationRoleNotAllowed
+	}
+
+	userExists, err := s.userQuery.UserWithEmailAddressExists(email)
+	if err != nil {
+		return nil, ehand.ErrorInvitationNotCreated
+	}
+
+	// Existing users may be invited to join further accounts
+	if userExists {
+		user, err := s.userQuery.GetUserByEmailAddress(email)
+		if err != nil {
+			return nil, ehand.ErrorInvitationNotCreated
+		}
+
+		if _, err := s.userQuery.GetUserById(user.ID, inviter.AccountId); err == nil {
+			return nil, ehand.ErrorAccountMemberAlreadyExists
+		}
+	}
+
+	pending, err := s.query.PendingInvitationExists(email, inviter.AccountId)
+	if err != nil {
+		return nil, ehand.ErrorInvitationNotCreated
+	}
+
+	if pending {
+		return nil, ehand.ErrorInvitationAlreadyExists
+	}
+
+	invitation := &entities.Invitation{
+		AccountId: inviter.AccountId,
+		Email:     email,
+		Role:      role,
+		InviterId: inviter.ID,
+		Inviter:   inviter,
+	}
+
+	token, err := s.resetToken(invitation)
+	if err != nil {
+		return nil, ehand.ErrorInvitationNotCreated
+	}
+
+	created, err := s.query.CreateInvitation(invitation)
+	if err != nil {
+		s.log.Error("Could not create Invitation: ", err)
+		return nil, ehand.ErrorInvitationNotCreated
+	}
+
+	s.send(created, token)
+	return created, nil
+}
+
+func (s *invitationService) GetInvitations(accountId string) (entities.InvitationList, error) {
+	invitations, err := s.query.GetInvitations(accountId)
+	if err != nil {
+		s.log.Error("Error fetching invitations from the database: ", err)
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// ResendInvitation issues a new token and expiry, invalidating the link in any previous email
+func (s *invitationService) ResendInvitation(invitationId, accountId string) (*entities.Invitation, error) {
+	invitation, err := s.query.GetInvitationById(invitationId, accountId)
+	if err != nil {
+		return nil, ehand.ErrorInvitationNotFound
+	}
+
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ehand.ErrorInvitationNotValid
+	}
+
+	token, err := s.resetToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.query.UpdateInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	s.send(invitation, token)
+	return invitation, nil
+}
+
+func (s *invitationService) RevokeInvitation(invitationId, accountId string) error {
+	invitation, err := s.query.GetInvitationById(invitationId, accountId)
+	if err != nil {
+		return ehand.ErrorInvitationNotFound
+	}
+
+	if !invitation.IsPending() {
+		return ehand.ErrorInvitationNotValid
+	}
+
+	now := time.Now()
+	invitation.RevokedAt = &now
+	return s.query.UpdateInvitation(invitation)
+}
+
+// ClaimInvitation marks the invitation with the given token as used.
+// If the invitee can't then be registered, the claim should be released with ReleaseInvitation.
+func (s *invitationService) ClaimInvitation(token string) (*entities.Invitation, error) {
+	invitation, err := s.query.GetInvitationByTokenHash(auth.HashOpaqueToken(token))
+	if err != nil {
+		return nil, ehand.ErrorInvitationNotValid
+	}
+
+	claimed, err := s.query.ClaimInvitation(invitation.ID)
+	if err != nil || !claimed {
+		return nil, ehand.ErrorInvitationNotValid
+	}
+
+	return invitation, nil
+}
+
+func (s *invitationService) ReleaseInvitation(invitation *entities.Invitation) error {
+	return s.query.ReleaseInvitation(invitation.ID)
+}
+
+func (s *invitationService) resetToken(invitation *entities.Invitation) (string, error) {
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		s.log.Error("Could not generate an invitation token: ", err)
+		return "", err
+	}
+
+	invitation.TokenHash = auth.HashOpaqueToken(token)
+	invitation.ExpiresAt = time.Now().Add(invitationLifetime)
+	return token, nil
+}
+
+// send emails the invitation; failures are logged as the invitation can be resent
+func (s *invitationService) send(invitation *entities.Invitation, token string) {
+	accountName := "Godo"
+	if account, err := s.accountQuery.GetAccountById(invitation.AccountId); err == nil {
+		accountName = account.Name
+	}
+
+	link := fmt.Sprintf("%s/invite?token=%s", s.webUIURL, url.QueryEscape(token))
+	body := fmt.Sprintf(
+		"%s has invited you to join %s on Godo as a %s.\n\n"+
+			"Accept the invitation by following the link below before %s:\n\n%s\n",
+		invitation.Inviter.Name,
+		accountName,
+		invitation.Role.String(),
+		invitation.ExpiresAt.Format(time.RFC1123),
+		link,
+	)
+
+	err := s.mailer.Send(invitation.Email, fmt.Sprintf("You have been invited to join %s", accountName), body)
+	if err != nil {
+		s.log.Errorf("Could not send Invitation{id=%s}: %s", invitation.ID, err)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+)
+
+type PersonalDataService interface {
+	ExportPersonalData(user entities.User) (*entities.PersonalData, error)
+	EraseMe(user entities.User, password string) error
+	EraseUser(userId uint) error
+}
+
+type personalDataService struct {
+	log   ilog.StdLogger
+	query repository.PersonalDataQuery
+}
+
+func NewPersonalDataService(query repository.PersonalDataQuery, logger ilog.StdLogger) PersonalDataService {
+	return &personalDataService{
+		log:   logger,
+		query: query,
+	}
+}
+
+// ExportPersonalData returns an archive of everything stored about the user, across every account they belong to
+func (s *personalDataService) ExportPersonalData(user entities.User) (*entities.PersonalData, error) {
+	data, err := s.query.GetPersonalData(user.ID)
+	if err != nil {
+		s.log.Errorf("Could not export the personal data of User{id=%d}: %s", user.ID, err)
+		return nil, err
+	}
+
+	s.log.Infof("Exported the personal data of User{id=%d}", user.ID)
+	return data, nil
+}
+
+// EraseMe erases the user once they have confirmed their password
+func (s *personalDataService) EraseMe(user entities.User, password string) error {
+	if err := user.VerifyPassword(password); err != nil {
+		s.log.Infof("User{id=%d} gave an incorrect password when erasing their personal data", user.ID)
+		return ehand.ErrorPasswordIncorrect
+	}
+
+	return s.EraseUser(user.ID)
+}
+
+// EraseUser anonymises the user's personal details, keeping the work they created under a tombstone identity.
+// Every session of the user is revoked and they are removed from every account.
+func (s *personalDataService) EraseUser(userId uint) error {
+	err := s.query.EraseUser(userId)
+	if err == ehand.ErrorLastOwner || err == ehand.ErrorUserNotFound {
+		return err
+	}
+
+	if err != nil {
+		s.log.Errorf("Could not erase User{id=%d}: %s", userId, err)
+		return ehand.ErrorUserNotUpdated
+	}
+
+	s.log.Infof("Erased the personal data of User{id=%d}", userId)
+	return nil
+}
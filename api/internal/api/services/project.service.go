@@ -6,31 +6,59 @@ import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 )
 
 type ProjectService interface {
-	GetProjects(accountId string) ([]*entities.ProjectInfo, error)
-	GetProjectById(projectId, accountId string) (*entities.Project, error)
+	GetProjects(user entities.User, teamId string) ([]*entities.ProjectInfo, error)
+	GetProjectById(projectId string, user entities.User) (*entities.Project, error)
+	GetProjectIncluding(projectId string, user entities.User, preload repository.Preload) (*entities.Project, error)
+	GetProjectsByIds(user entities.User, projectIds []string) (entities.ProjectList, error)
 	CreateProject(newProject *entities.Project) (*entities.Project, error)
-	Exists(projectId string) bool
-	UpdateProject(projectId string, newProjectData *entities.Project) error
-	DeleteProject(projectId string) error
+	UpdateProject(projectId string, user entities.User, newProjectData *entities.Project) error
+	PatchProject(projectId string, user entities.User, patched *entities.Project) (*entities.Project, error)
+	SetRestricted(projectId string, user entities.User, restricted bool) error
+	SetTeam(projectId string, user entities.User, teamId *string) error
+	DeleteProject(projectId string, user entities.User) error
+	Authorize(projectId string, user entities.User, role enums.ProjectRole) error
+
+	GetMembers(projectId string, user entities.User) (entities.ProjectMemberList, error)
+	SetMember(projectId string, user entities.User, memberId uint, role enums.ProjectRole) (*entities.ProjectMember, error)
+	RemoveMember(projectId string, user entities.User, memberId uint) error
 }
 
 type projectService struct {
-	log   ilog.StdLogger
-	query repository.ProjectQuery
+	log         ilog.StdLogger
+	query       repository.ProjectQuery
+	memberQuery repository.ProjectMemberQuery
+	userQuery   repository.ApiUserQuery
+	teamQuery   repository.TeamQuery
+	access      projectAccess
 }
 
-func NewProjectService(projectQuery repository.ProjectQuery, logger ilog.StdLogger) ProjectService {
+func NewProjectService(
+	projectQuery repository.ProjectQuery,
+	memberQuery repository.ProjectMemberQuery,
+	userQuery repository.ApiUserQuery,
+	teamQuery repository.TeamQuery,
+	logger ilog.StdLogger) ProjectService {
+
 	return &projectService{
-		log:   logger,
-		query: projectQuery,
+		log:         logger,
+		query:       projectQuery,
+		memberQuery: memberQuery,
+		userQuery:   userQuery,
+		teamQuery:   teamQuery,
+		access:      projectAccess{projectQuery: projectQuery, memberQuery: memberQuery},
 	}
 }
 
-func (p *projectService) GetProjects(accountId string) ([]*entities.ProjectInfo, error) {
-	projects, err := p.query.GetProjectsInfo(accountId)
+// GetProjects returns the projects visible to the user; if teamId is given, only the projects owned by the team
+func (p *projectService) GetProjects(user entities.User, teamId string) ([]*entities.ProjectInfo, error) {
+	scope := projectScope(user)
+	scope.TeamId = teamId
+
+	projects, err := p.query.GetProjectsInfo(scope)
 
 	if err != nil {
 		p.log.Infof("Error fetching projects from the database: ", err.Error())
@@ -40,18 +68,37 @@ func (p *projectService) GetProjects(accountId string) ([]*entities.ProjectInfo,
 	return projects, nil
 }
 
-func (p *projectService) GetProjectById(projectId, accountId string) (*entities.Project, error) {
-	project, err := p.query.GetProjectById(projectId, accountId)
+// GetProjectById returns the whole of the project; its stories, their tasks, and its tags
+func (p *projectService) GetProjectById(projectId string, user entities.User) (*entities.Project, error) {
+	return p.GetProjectIncluding(projectId, user, repository.PreloadProjectTree)
+}
+
+// GetProjectIncluding returns the project, loading only the associations given by preload
+func (p *projectService) GetProjectIncluding(projectId string, user entities.User, preload repository.Preload) (*entities.Project, error) {
+	project, err := p.query.GetProjectById(projectId, projectScope(user), preload)
 
 	if err != nil {
-		p.log.Debugf("Project with projectId %s and accountId %s not found", projectId, accountId)
+		p.log.Debugf("Project with projectId %s and accountId %s not found", projectId, user.AccountId)
 		return nil, ehand.ErrorProjectNotFound
 	}
 
 	return project, nil
 }
 
+// GetProjectsByIds returns those of the projects which are visible to the user, without their stories and tags
+func (p *projectService) GetProjectsByIds(user entities.User, projectIds []string) (entities.ProjectList, error) {
+	projects, err := p.query.GetProjectsByIds(projectIds, projectScope(user))
+	if err != nil {
+		p.log.Error("Could not fetch Projects: ", err)
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// CreateProject creates the project within the creator's account, making the creator its maintainer
 func (p *projectService) CreateProject(newProject *entities.Project) (*entities.Project, error) {
+	newProject.AccountId = newProject.Creator.AccountId
 	createdProject, err := p.query.CreateProject(newProject)
 
 	if err != nil {
@@ -62,15 +109,10 @@ func (p *projectService) CreateProject(newProject *entities.Project) (*entities.
 	return createdProject, nil
 }
 
-func (p *projectService) Exists(projectId string) bool {
-	return p.query.Exists(projectId)
-}
-
-func (p *projectService) UpdateProject(projectId string, newProjectData *entities.Project) error {
-	projectExists := p.query.Exists(projectId)
-	if !projectExists {
-		p.log.Warnf("Project with id %s does not exist", projectId)
-		return ehand.ErrorProjectNotFound
+func (p *projectService) UpdateProject(projectId string, user entities.User, newProjectData *entities.Project) error {
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		p.log.Warnf("User{id=%d} may not update Project{id=%s}: %s", user.ID, projectId, err)
+		return err
 	}
 
 	err := p.query.UpdateProject(projectId, newProjectData)
@@ -82,11 +124,71 @@ func (p *projectService) UpdateProject(projectId string, newProjectData *entitie
 	return nil
 }
 
-func (p *projectService) DeleteProject(projectId string) error {
-	projectExists := p.query.Exists(projectId)
-	if !projectExists {
-		p.log.Warnf("Project with id %s not found", projectId)
-		return ehand.ErrorProjectNotFound
+// PatchProject persists only the fields of the patched project which differ from the stored project
+func (p *projectService) PatchProject(projectId string, user entities.User, patched *entities.Project) (*entities.Project, error) {
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		p.log.Warnf("User{id=%d} may not update Project{id=%s}: %s", user.ID, projectId, err)
+		return nil, err
+	}
+
+	existing, err := p.GetProjectById(projectId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := projectChanges(existing, patched)
+	if len(changes) == 0 {
+		return existing, nil
+	}
+
+	if err := p.query.PatchProject(projectId, changes); err != nil {
+		p.log.Error("Could not patch Project: ", err)
+		return nil, errors.New("issue updating the project")
+	}
+
+	return p.GetProjectById(projectId, user)
+}
+
+// SetRestricted sets whether the project is visible only to its members
+func (p *projectService) SetRestricted(projectId string, user entities.User, restricted bool) error {
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		return err
+	}
+
+	err := p.query.SetRestricted(projectId, restricted)
+	if err != nil {
+		p.log.Error("Could not update the visibility of Project: ", err)
+		return errors.New("issue updating the project")
+	}
+
+	return nil
+}
+
+// SetTeam sets the team in the account owning the project, or leaves the project without a team if teamId is nil
+func (p *projectService) SetTeam(projectId string, user entities.User, teamId *string) error {
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		return err
+	}
+
+	if teamId != nil {
+		if _, err := p.teamQuery.GetTeamById(*teamId, user.AccountId); err != nil {
+			return ehand.ErrorTeamNotFound
+		}
+	}
+
+	err := p.query.SetTeam(projectId, teamId)
+	if err != nil {
+		p.log.Error("Could not update the team owning Project: ", err)
+		return errors.New("issue updating the project")
+	}
+
+	return nil
+}
+
+func (p *projectService) DeleteProject(projectId string, user entities.User) error {
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		p.log.Warnf("User{id=%d} may not delete Project{id=%s}: %s", user.ID, projectId, err)
+		return err
 	}
 
 	err := p.query.DeleteProject(projectId)
@@ -97,3 +199,91 @@ func (p *projectService) DeleteProject(projectId string) error {
 
 	return nil
 }
+
+// Authorize ensures that the project is visible to the user and that their role within it grants the given role
+func (p *projectService) Authorize(projectId string, user entities.User, role enums.ProjectRole) error {
+	return p.access.authorize(user, projectId, role)
+}
+
+func (p *projectService) GetMembers(projectId string, user entities.User) (entities.ProjectMemberList, error) {
+	if err := p.access.authorize(user, projectId, enums.Reader); err != nil {
+		return nil, err
+	}
+
+	members, err := p.memberQuery.GetMembers(projectId)
+	if err != nil {
+		p.log.Errorf("Could not fetch the members of Project{id=%s}: %s", projectId, err)
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// SetMember adds a user from the same account to the project, or changes their role within it
+func (p *projectService) SetMember(
+	projectId string,
+	user entities.User,
+	memberId uint,
+	role enums.ProjectRole) (*entities.ProjectMember, error) {
+
+	if !role.IsValid() {
+		return nil, ehand.ErrorRoleNotValid
+	}
+
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		return nil, err
+	}
+
+	member, err := p.userQuery.GetUserById(memberId, user.AccountId)
+	if err != nil {
+		return nil, ehand.ErrorUserNotFound
+	}
+
+	if !member.IsActive() {
+		return nil, ehand.ErrorUserDeactivated
+	}
+
+	if err := p.memberQuery.SaveMember(projectId, memberId, role); err != nil {
+		p.log.Errorf("Could not save User{id=%d} in Project{id=%s}: %s", memberId, projectId, err)
+		return nil, err
+	}
+
+	return p.memberQuery.GetMember(projectId, memberId)
+}
+
+func (p *projectService) RemoveMember(projectId string, user entities.User, memberId uint) error {
+	if err := p.access.authorize(user, projectId, enums.Maintainer); err != nil {
+		return err
+	}
+
+	removed, err := p.memberQuery.RemoveMember(projectId, memberId)
+	if err != nil {
+		p.log.Errorf("Could not remove User{id=%d} from Project{id=%s}: %s", memberId, projectId, err)
+		return err
+	}
+
+	if !removed {
+		return ehand.ErrorProjectMemberNotFound
+	}
+
+	return nil
+}
+
+// projectChanges returns the columns of the patched project which differ from the existing project
+func projectChanges(existing, patched *entities.Project) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	if patched.Name != existing.Name {
+		changes["name"] = patched.Name
+	}
+
+	if patched.Description != existing.Description {
+		changes["description"] = patched.Description
+	}
+
+	if patched.Status != existing.Status {
+		changes["status"] = patched.Status
+	}
+
+	return changes
+}
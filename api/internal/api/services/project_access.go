@@ -0,0 +1,71 @@
+package services
+
+import (
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+)
+
+// projectAccess resolves what a user may do within a project from their account role,
+// their membership of the project and whether the project is restricted
+type projectAccess struct {
+	projectQuery repository.ProjectQuery
+	memberQuery  repository.ProjectMemberQuery
+}
+
+// projectScope the projects visible to the user
+func projectScope(user entities.User) repository.ProjectScope {
+	return repository.ProjectScope{
+		AccountId:   user.AccountId,
+		UserId:      user.ID,
+		AllProjects: auth.HasPermission(user.Role, auth.PermissionProjectAdmin),
+	}
+}
+
+// role returns the user's effective role within the project.
+// ErrorProjectNotFound is returned if the project is not visible to the user.
+func (a projectAccess) role(user entities.User, projectId string) (enums.ProjectRole, error) {
+	scope := projectScope(user)
+
+	_, err := a.projectQuery.FindProject(projectId, scope)
+	if err != nil {
+		return enums.Reader, ehand.ErrorProjectNotFound
+	}
+
+	// Users whose account role is read-only can't be given more through a project
+	if !auth.HasPermission(user.Role, auth.PermissionProjectWrite) {
+		return enums.Reader, nil
+	}
+
+	if scope.AllProjects {
+		return enums.Maintainer, nil
+	}
+
+	role, isMember, err := a.memberQuery.GetMemberRole(projectId, user.ID)
+	if err != nil {
+		return enums.Reader, err
+	}
+
+	if isMember {
+		return role, nil
+	}
+
+	// Only unrestricted projects are visible to those who aren't members
+	return enums.Contributor, nil
+}
+
+// authorize ensures the user's role within the project grants the required role
+func (a projectAccess) authorize(user entities.User, projectId string, required enums.ProjectRole) error {
+	role, err := a.role(user, projectId)
+	if err != nil {
+		return err
+	}
+
+	if !role.Grants(required) {
+		return ehand.ErrorPermissionDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+	"sync"
+	"time"
+)
+
+const (
+	// How long the keys are cached before being re-read, so rotations reach every instance
+	signingKeyCacheTTL = time.Minute
+
+	// The minimum time between reloads triggered by a token signed with an unknown kid
+	signingKeyReloadInterval = 10 * time.Second
+
+	// The kid assumed for tokens signed with the legacy JWT_KEY secret, which carry no kid header
+	legacyKeyId = ""
+)
+
+var ErrUnknownSigningKey = errors.New("the token was not signed with a known key")
+
+type SigningKeyService interface {
+	SigningKey() (*auth.Key, error)
+	VerificationKey(kid string) (*auth.Key, error)
+	JWKS() (*auth.JWKS, error)
+	RotateKeys() (*auth.Key, error)
+}
+
+type signingKeyService struct {
+	log         ilog.StdLogger
+	query       repository.SigningKeyQuery
+	algorithm   string
+	gracePeriod time.Duration
+	legacyKey   *auth.Key
+
+	mu       sync.RWMutex
+	signing  *auth.Key
+	keys     map[string]*auth.Key
+	loadedAt time.Time
+
+	// When the legacy key stops verifying tokens, a grace period after the first key replaced it; nil
+	// until then
+	legacyRetiresAt *time.Time
+	legacyRetired   sync.Once
+
+	// Held while creating the first key, such that concurrent logins create only one
+	bootstrap sync.Mutex
+}
+
+// NewSigningKeyService creates the service managing the JWT key set.
+// If legacySecret is not empty, tokens signed with the old HMAC secret remain valid for verification
+// until the grace period has passed since the first key of the key set was created.
+func NewSigningKeyService(
+	query repository.SigningKeyQuery,
+	algorithm string,
+	gracePeriod time.Duration,
+	legacySecret []byte,
+	logger ilog.StdLogger) SigningKeyService {
+
+	s := &signingKeyService{
+		log:         logger,
+		query:       query,
+		algorithm:   algorithm,
+		gracePeriod: gracePeriod,
+	}
+
+	if len(legacySecret) > 0 {
+		s.legacyKey = auth.NewHMACKey(legacyKeyId, legacySecret)
+	}
+
+	return s
+}
+
+// SigningKey returns the newest active key, creating the first key if the key set is empty
+func (s *signingKeyService) SigningKey() (*auth.Key, error) {
+	if err := s.refreshIfStale(signingKeyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	signing := s.signing
+	s.mu.RUnlock()
+
+	if signing != nil {
+		return signing, nil
+	}
+
+	return s.createFirstKey()
+}
+
+// createFirstKey creates the first key of an empty key set. Instances doing so at the same time are
+// serialized, within this instance by the bootstrap lock and between instances by the database, such that
+// one key is created and used by all.
+func (s *signingKeyService) createFirstKey() (*auth.Key, error) {
+	s.bootstrap.Lock()
+	defer s.bootstrap.Unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	if signing := s.cachedSigningKey(); signing != nil {
+		return signing, nil
+	}
+
+	s.log.Info("No active signing key exists, creating one")
+	_, stored, err := s.generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.query.CreateFirstSigningKey(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if !created {
+		s.log.Info("The first signing key was created by another instance")
+	}
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	if signing := s.cachedSigningKey(); signing != nil {
+		return signing, nil
+	}
+
+	return nil, errors.New("no active signing key could be loaded")
+}
+
+func (s *signingKeyService) VerificationKey(kid string) (*auth.Key, error) {
+	if kid == legacyKeyId {
+		return s.legacyVerificationKey()
+	}
+
+	if err := s.refreshIfStale(signingKeyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	if key, ok := s.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	// The key may have been created by a rotation since the cache was loaded
+	if err := s.refreshIfStale(signingKeyReloadInterval); err != nil {
+		return nil, err
+	}
+
+	if key, ok := s.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownSigningKey
+}
+
+// JWKS returns the public keys of every key still valid for verification
+func (s *signingKeyService) JWKS() (*auth.JWKS, error) {
+	if err := s.refreshIfStale(signingKeyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jwks := &auth.JWKS{Keys: []auth.JWK{}}
+	for _, key := range s.keys {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks, nil
+}
+
+// legacyVerificationKey the legacy key, verifying the tokens without a kid which were signed before the key
+// set replaced it, until it retires
+func (s *signingKeyService) legacyVerificationKey() (*auth.Key, error) {
+	if s.legacyKey == nil {
+		return nil, ErrUnknownSigningKey
+	}
+
+	if err := s.refreshIfStale(signingKeyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	retiresAt := s.legacyRetiresAt
+	s.mu.RUnlock()
+
+	if retiresAt != nil && !time.Now().Before(*retiresAt) {
+		s.legacyRetired.Do(func() {
+			s.log.Warnf("The legacy JWT_KEY retired at %s; tokens without a kid are rejected and it may be removed", retiresAt)
+		})
+
+		return nil, ErrUnknownSigningKey
+	}
+
+	return s.legacyKey, nil
+}
+
+// RotateKeys creates a new signing key; the previous keys verify tokens for the grace period
+func (s *signingKeyService) RotateKeys() (*auth.Key, error) {
+	key, stored, err := s.generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.query.RotateSigningKeys(stored, s.gracePeriod); err != nil {
+		return nil, err
+	}
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// generateKey generates a key of the service's algorithm, along with the entity storing it
+func (s *signingKeyService) generateKey() (*auth.Key, *entities.SigningKey, error) {
+	key, err := auth.GenerateKey(s.algorithm)
+	if err != nil {
+		s.log.Errorf("Could not generate a %s signing key: %s", s.algorithm, err)
+		return nil, nil, err
+	}
+
+	privateKey, err := key.PrivateKeyPEM()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, &entities.SigningKey{
+		Kid:        key.Kid,
+		Algorithm:  key.Algorithm,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+func (s *signingKeyService) cachedSigningKey() *auth.Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.signing
+}
+
+func (s *signingKeyService) cachedKey(kid string) (*auth.Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *signingKeyService) refreshIfStale(maxAge time.Duration) error {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > maxAge
+	s.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+
+	return s.reload()
+}
+
+func (s *signingKeyService) reload() error {
+	stored, err := s.query.GetValidSigningKeys(time.Now())
+	if err != nil {
+		return err
+	}
+
+	var signing *auth.Key
+	keys := make(map[string]*auth.Key, len(stored))
+
+	for _, k := range stored {
+		key, err := auth.ParseKey(k.Kid, k.Algorithm, k.PrivateKey)
+		if err != nil {
+			s.log.Errorf("Could not parse SigningKey{kid=%s}: %s", k.Kid, err)
+			continue
+		}
+
+		keys[key.Kid] = key
+
+		// Keys are ordered newest first
+		if signing == nil && k.RetiredAt == nil {
+			signing = key
+		}
+	}
+
+	var legacyRetiresAt *time.Time
+	if s.legacyKey != nil {
+		first, err := s.query.GetFirstSigningKey()
+		if err != nil {
+			return err
+		}
+
+		if first != nil {
+			retiresAt := first.CreatedAt.Add(s.gracePeriod)
+			legacyRetiresAt = &retiresAt
+		}
+	}
+
+	s.mu.Lock()
+	s.signing = signing
+	s.keys = keys
+	s.legacyRetiresAt = legacyRetiresAt
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
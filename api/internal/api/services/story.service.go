@@ -5,30 +5,42 @@ import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 )
 
 type StoryService interface {
-	GetStoriesInfo(accountId string) (entities.StoryInfoList, error)
-	GetStoryById(accountId, storyId string) (*entities.Story, error)
-	CreateStory(newStory *entities.Story) (*entities.Story, error)
-	UpdateStory(storyId string, newStoryData *entities.Story) error
-	DeleteStory(storyId string) error
+	GetStoriesInfo(user entities.User) (entities.StoryInfoList, error)
+	GetStoryById(user entities.User, storyId string) (*entities.Story, error)
+	GetStoryIncluding(user entities.User, storyId string, preload repository.Preload) (*entities.Story, error)
+	GetStoriesByIds(user entities.User, storyIds []string) ([]*entities.Story, error)
+	GetStoriesByProjectIds(user entities.User, projectIds []string) ([]*entities.Story, error)
+	CreateStory(user entities.User, newStory *entities.Story) (*entities.Story, error)
+	UpdateStory(user entities.User, storyId string, newStoryData *entities.Story) error
+	PatchStory(user entities.User, patched *entities.Story) (*entities.Story, error)
+	DeleteStory(user entities.User, storyId string) error
 }
 
 type storyService struct {
-	log   ilog.StdLogger
-	query repository.StoryQuery
+	log    ilog.StdLogger
+	query  repository.StoryQuery
+	access projectAccess
 }
 
-func NewStoryService(storyQuery repository.StoryQuery, log ilog.StdLogger) StoryService {
+func NewStoryService(
+	storyQuery repository.StoryQuery,
+	projectQuery repository.ProjectQuery,
+	memberQuery repository.ProjectMemberQuery,
+	log ilog.StdLogger) StoryService {
+
 	return &storyService{
-		log:   log,
-		query: storyQuery,
+		log:    log,
+		query:  storyQuery,
+		access: projectAccess{projectQuery: projectQuery, memberQuery: memberQuery},
 	}
 }
 
-func (s *storyService) GetStoriesInfo(accountId string) (entities.StoryInfoList, error) {
-	info, err := s.query.GetStoriesInfo(accountId)
+func (s *storyService) GetStoriesInfo(user entities.User) (entities.StoryInfoList, error) {
+	info, err := s.query.GetStoriesInfo(projectScope(user))
 	if err != nil {
 		s.log.Error("error fetching info from database: ", err)
 		return nil, err
@@ -37,17 +49,50 @@ func (s *storyService) GetStoriesInfo(accountId string) (entities.StoryInfoList,
 	return info, nil
 }
 
-func (s *storyService) GetStoryById(accountId, storyId string) (*entities.Story, error) {
-	story, err := s.query.GetStoryById(accountId, storyId)
+// GetStoryById returns the story with its creator, without its tasks
+func (s *storyService) GetStoryById(user entities.User, storyId string) (*entities.Story, error) {
+	return s.GetStoryIncluding(user, storyId, repository.PreloadCreator)
+}
+
+// GetStoryIncluding returns the story, loading only the associations given by preload
+func (s *storyService) GetStoryIncluding(user entities.User, storyId string, preload repository.Preload) (*entities.Story, error) {
+	story, err := s.query.GetStoryById(storyId, projectScope(user), preload)
 	if err != nil {
-		s.log.Infof("Story with accountId %s and storyId %s not found", accountId, storyId)
+		s.log.Infof("Story with accountId %s and storyId %s not found", user.AccountId, storyId)
 		return nil, ehand.ErrorStoryNotFound
 	}
 
 	return story, err
 }
 
-func (s *storyService) CreateStory(newStory *entities.Story) (*entities.Story, error) {
+// GetStoriesByIds returns those of the stories which are visible to the user, without their tasks
+func (s *storyService) GetStoriesByIds(user entities.User, storyIds []string) ([]*entities.Story, error) {
+	stories, err := s.query.GetStoriesByIds(storyIds, projectScope(user))
+	if err != nil {
+		s.log.Error("error fetching stories from database: ", err)
+		return nil, err
+	}
+
+	return stories, nil
+}
+
+// GetStoriesByProjectIds returns the stories of those of the projects which are visible to the user
+func (s *storyService) GetStoriesByProjectIds(user entities.User, projectIds []string) ([]*entities.Story, error) {
+	stories, err := s.query.GetStoriesByProjectIds(projectIds, projectScope(user))
+	if err != nil {
+		s.log.Error("error fetching stories from database: ", err)
+		return nil, err
+	}
+
+	return stories, nil
+}
+
+func (s *storyService) CreateStory(user entities.User, newStory *entities.Story) (*entities.Story, error) {
+	if err := s.access.authorize(user, newStory.ProjectId, enums.Contributor); err != nil {
+		s.log.Debugf("User{id=%d} may not add stories to Project{id=%s}: %s", user.ID, newStory.ProjectId, err)
+		return nil, err
+	}
+
 	createdStory, err := s.query.CreateStory(newStory)
 
 	if err != nil {
@@ -58,17 +103,25 @@ func (s *storyService) CreateStory(newStory *entities.Story) (*entities.Story, e
 	return createdStory, nil
 }
 
-func (s *storyService) Exists(storyId string) bool {
-	return s.query.Exists(storyId)
-}
+// UpdateStory updates the story, ensuring the user may change stories in both
+// its current project and the project it is being moved to
+func (s *storyService) UpdateStory(user entities.User, storyId string, newStoryData *entities.Story) error {
+	existing, err := s.GetStoryById(user, storyId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.access.authorize(user, existing.ProjectId, enums.Contributor); err != nil {
+		return err
+	}
 
-func (s *storyService) UpdateStory(storyId string, newStoryData *entities.Story) error {
-	exists := s.Exists(storyId)
-	if !exists {
-		return ehand.ErrorStoryNotFound
+	if newStoryData.ProjectId != existing.ProjectId {
+		if err := s.access.authorize(user, newStoryData.ProjectId, enums.Contributor); err != nil {
+			return err
+		}
 	}
 
-	err := s.query.UpdateStory(newStoryData)
+	err = s.query.UpdateStory(newStoryData)
 	if err != nil {
 		s.log.Errorf("Could not update Story with storyId %s: %S", storyId, err.Error())
 		return ehand.ErrorStoryNotUpdated
@@ -77,13 +130,47 @@ func (s *storyService) UpdateStory(storyId string, newStoryData *entities.Story)
 	return nil
 }
 
-func (s *storyService) DeleteStory(storyId string) error {
-	exists := s.Exists(storyId)
-	if !exists {
-		return ehand.ErrorStoryNotFound
+// PatchStory persists only the fields of the patched story which differ from the stored story
+func (s *storyService) PatchStory(user entities.User, patched *entities.Story) (*entities.Story, error) {
+	existing, err := s.GetStoryById(user, patched.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.access.authorize(user, existing.ProjectId, enums.Contributor); err != nil {
+		return nil, err
+	}
+
+	changes := storyChanges(existing, patched)
+	if len(changes) == 0 {
+		return existing, nil
+	}
+
+	if _, moved := changes["project_id"]; moved {
+		if err := s.access.authorize(user, patched.ProjectId, enums.Contributor); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.query.PatchStory(existing.ID, changes); err != nil {
+		s.log.Errorf("Could not patch Story with storyId %s: %s", existing.ID, err.Error())
+		return nil, ehand.ErrorStoryNotUpdated
+	}
+
+	return s.GetStoryById(user, existing.ID)
+}
+
+func (s *storyService) DeleteStory(user entities.User, storyId string) error {
+	existing, err := s.GetStoryById(user, storyId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.access.authorize(user, existing.ProjectId, enums.Contributor); err != nil {
+		return err
 	}
 
-	err := s.query.DeleteStory(storyId)
+	err = s.query.DeleteStory(storyId)
 	if err != nil {
 		s.log.Errorf("Could not delete Story with storyId %s: %S", storyId, err.Error())
 		return ehand.ErrorStoryNotDeleted
@@ -91,3 +178,22 @@ func (s *storyService) DeleteStory(storyId string) error {
 
 	return nil
 }
+
+// storyChanges returns the columns of the patched story which differ from the existing story
+func storyChanges(existing, patched *entities.Story) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	if patched.Name != existing.Name {
+		changes["name"] = patched.Name
+	}
+
+	if patched.Description != existing.Description {
+		changes["description"] = patched.Description
+	}
+
+	if patched.ProjectId != existing.ProjectId {
+		changes["project_id"] = patched.ProjectId
+	}
+
+	return changes
+}
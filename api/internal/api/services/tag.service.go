@@ -12,6 +12,8 @@ type TagService interface {
 	CreateTag(newTag entities.Tag) (*entities.Tag, error)
 	DeleteTag(tagId uint, projectId string) (*entities.Tag, error)
 	GetTagById(tagId uint, projectId string) (*entities.Tag, error)
+	GetTagsByProjectIds(projectIds []string) (entities.TagList, error)
+	GetTagsByTaskIds(taskIds []string) (map[string]entities.TagList, error)
 	UpdateTag(newTag entities.Tag) (*entities.Tag, error)
 	AddToTask(tagId uint, taskId string) error
 	RemoveFromTask(taskId string, tagId uint) error
@@ -36,6 +38,16 @@ func (t *tagService) GetTagById(tagId uint, projectId string) (*entities.Tag, er
 	return tag, nil
 }
 
+// GetTagsByProjectIds returns the tags of the projects, which must be visible to the user
+func (t *tagService) GetTagsByProjectIds(projectIds []string) (entities.TagList, error) {
+	return t.query.GetTagsByProjectIds(projectIds)
+}
+
+// GetTagsByTaskIds returns the tags of each of the tasks, which must be visible to the user, keyed by the task's id
+func (t *tagService) GetTagsByTaskIds(taskIds []string) (map[string]entities.TagList, error) {
+	return t.query.GetTagsByTaskIds(taskIds)
+}
+
 func (t *tagService) CreateTag(newTag entities.Tag) (*entities.Tag, error) {
 	exists := t.query.ExistsWithName(newTag.Name, newTag.ProjectId)
 	if exists {
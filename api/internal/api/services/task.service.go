@@ -6,27 +6,53 @@ import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 )
 
 type TaskService interface {
-	Exists(accountId, taskId string) bool
-	GetTasks(accountId string) (entities.TaskList, error)
-	GetTaskById(taskId, accountId string) (*entities.Task, error)
-	CreateTask(newTask entities.Task) (*entities.Task, error)
-	UpdateTask(newTask *entities.Task) (*entities.Task, error)
+	Exists(user entities.User, taskId string) bool
+	GetTasks(user entities.User, teamId string, preload repository.Preload) (entities.TaskList, error)
+	GetTaskById(taskId string, user entities.User) (*entities.Task, error)
+	GetTaskIncluding(taskId string, user entities.User, preload repository.Preload) (*entities.Task, error)
+	GetTasksByStoryIds(user entities.User, storyIds []string) (entities.TaskList, error)
+	CreateTask(user entities.User, newTask entities.Task) (*entities.Task, error)
+	UpdateTask(user entities.User, newTask *entities.Task) (*entities.Task, error)
+	PatchTask(user entities.User, patched *entities.Task) (*entities.Task, error)
+	Authorize(taskId string, user entities.User, role enums.ProjectRole) error
 }
 
 type taskService struct {
-	log   ilog.StdLogger
-	query repository.TaskQuery
+	log        ilog.StdLogger
+	query      repository.TaskQuery
+	storyQuery repository.StoryQuery
+	userQuery  repository.ApiUserQuery
+	access     projectAccess
 }
 
-func NewTaskService(query repository.TaskQuery, logger ilog.StdLogger) TaskService {
-	return &taskService{log: logger, query: query}
+func NewTaskService(
+	query repository.TaskQuery,
+	storyQuery repository.StoryQuery,
+	projectQuery repository.ProjectQuery,
+	memberQuery repository.ProjectMemberQuery,
+	userQuery repository.ApiUserQuery,
+	logger ilog.StdLogger) TaskService {
+
+	return &taskService{
+		log:        logger,
+		query:      query,
+		storyQuery: storyQuery,
+		userQuery:  userQuery,
+		access:     projectAccess{projectQuery: projectQuery, memberQuery: memberQuery},
+	}
 }
 
-func (t *taskService) GetTasks(accountId string) (entities.TaskList, error) {
-	tasks, err := t.query.GetAllTasks(accountId)
+// GetTasks returns the tasks visible to the user, loading the associations given by preload; if teamId is
+// given, only those in projects owned by the team
+func (t *taskService) GetTasks(user entities.User, teamId string, preload repository.Preload) (entities.TaskList, error) {
+	scope := projectScope(user)
+	scope.TeamId = teamId
+
+	tasks, err := t.query.GetAllTasks(scope, preload)
 	if err != nil {
 		t.log.Infof("Error fetching projects from the database: ", err)
 		return nil, errors.New("no tasks found in the database")
@@ -35,17 +61,45 @@ func (t *taskService) GetTasks(accountId string) (entities.TaskList, error) {
 	return tasks, nil
 }
 
-func (t *taskService) GetTaskById(taskId, accountId string) (*entities.Task, error) {
-	task, err := t.query.GetTaskById(taskId, accountId)
+// GetTaskById returns the task with its creator, without its tags
+func (t *taskService) GetTaskById(taskId string, user entities.User) (*entities.Task, error) {
+	return t.GetTaskIncluding(taskId, user, repository.PreloadCreator)
+}
+
+// GetTaskIncluding returns the task, loading only the associations given by preload
+func (t *taskService) GetTaskIncluding(taskId string, user entities.User, preload repository.Preload) (*entities.Task, error) {
+	task, err := t.query.GetTaskById(taskId, projectScope(user), preload)
 	if err != nil {
-		t.log.Debugf("Task with projectId %s and accountId %s not found", taskId, accountId)
+		t.log.Debugf("Task with projectId %s and accountId %s not found", taskId, user.AccountId)
 		return nil, ehand.ErrorTaskNotFound
 	}
 
 	return &task, nil
 }
 
-func (t *taskService) CreateTask(newTask entities.Task) (*entities.Task, error) {
+// GetTasksByStoryIds returns the tasks of those of the stories which are visible to the user
+func (t *taskService) GetTasksByStoryIds(user entities.User, storyIds []string) (entities.TaskList, error) {
+	tasks, err := t.query.GetTasksByStoryIds(storyIds, projectScope(user))
+	if err != nil {
+		t.log.Error("Could not fetch Tasks: ", err)
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (t *taskService) CreateTask(user entities.User, newTask entities.Task) (*entities.Task, error) {
+	if err := t.authorizeStory(user, newTask.StoryId, enums.Contributor); err != nil {
+		return nil, err
+	}
+
+	// Tasks are assigned to their creator unless another assignee is given
+	if newTask.AssigneeId == nil {
+		newTask.AssigneeId = &user.ID
+	} else if err := t.validateAssignee(user, *newTask.AssigneeId); err != nil {
+		return nil, err
+	}
+
 	created, err := t.query.CreateTask(newTask)
 
 	if err != nil {
@@ -56,7 +110,30 @@ func (t *taskService) CreateTask(newTask entities.Task) (*entities.Task, error)
 	return &created, nil
 }
 
-func (t *taskService) UpdateTask(newTask *entities.Task) (*entities.Task, error) {
+// UpdateTask updates the task, ensuring the user may change tasks in both
+// the story it belongs to and the story it is being moved to
+func (t *taskService) UpdateTask(user entities.User, newTask *entities.Task) (*entities.Task, error) {
+	existing, err := t.GetTaskById(newTask.ID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.authorizeStory(user, existing.StoryId, enums.Contributor); err != nil {
+		return nil, err
+	}
+
+	if newTask.StoryId != existing.StoryId {
+		if err := t.authorizeStory(user, newTask.StoryId, enums.Contributor); err != nil {
+			return nil, err
+		}
+	}
+
+	if newTask.AssigneeId != nil && !sameAssignee(newTask.AssigneeId, existing.AssigneeId) {
+		if err := t.validateAssignee(user, *newTask.AssigneeId); err != nil {
+			return nil, err
+		}
+	}
+
 	updated, err := t.query.UpdateTask(newTask)
 	if err != nil {
 		return nil, ehand.ErrorTaskNotUpdated
@@ -65,7 +142,116 @@ func (t *taskService) UpdateTask(newTask *entities.Task) (*entities.Task, error)
 	return updated, nil
 }
 
-func (t *taskService) Exists(accountId, taskId string) bool {
-	exists := t.query.Exists(accountId, taskId)
+// PatchTask persists only the fields of the patched task which differ from the stored task
+func (t *taskService) PatchTask(user entities.User, patched *entities.Task) (*entities.Task, error) {
+	existing, err := t.GetTaskById(patched.ID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.authorizeStory(user, existing.StoryId, enums.Contributor); err != nil {
+		return nil, err
+	}
+
+	changes := taskChanges(existing, patched)
+	if len(changes) == 0 {
+		return existing, nil
+	}
+
+	if _, moved := changes["story_id"]; moved {
+		if err := t.authorizeStory(user, patched.StoryId, enums.Contributor); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, reassigned := changes["assignee_id"]; reassigned && patched.AssigneeId != nil {
+		if err := t.validateAssignee(user, *patched.AssigneeId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.query.PatchTask(existing.ID, changes); err != nil {
+		return nil, ehand.ErrorTaskNotUpdated
+	}
+
+	return t.GetTaskById(existing.ID, user)
+}
+
+// Authorize ensures that the task is visible to the user and that their role
+// within the task's project grants the given role
+func (t *taskService) Authorize(taskId string, user entities.User, role enums.ProjectRole) error {
+	task, err := t.GetTaskById(taskId, user)
+	if err != nil {
+		return err
+	}
+
+	return t.authorizeStory(user, task.StoryId, role)
+}
+
+func (t *taskService) Exists(user entities.User, taskId string) bool {
+	exists := t.query.Exists(taskId, projectScope(user))
 	return exists
 }
+
+func (t *taskService) authorizeStory(user entities.User, storyId string, role enums.ProjectRole) error {
+	story, err := t.storyQuery.GetStoryById(storyId, projectScope(user), nil)
+	if err != nil {
+		t.log.Debugf("Story{id=%s} is not visible to User{id=%d}", storyId, user.ID)
+		return ehand.ErrorStoryNotFound
+	}
+
+	return t.access.authorize(user, story.ProjectId, role)
+}
+
+// validateAssignee ensures that tasks are only assigned to active users in the account
+func (t *taskService) validateAssignee(user entities.User, assigneeId uint) error {
+	assignee, err := t.userQuery.GetUserById(assigneeId, user.AccountId)
+	if err != nil {
+		return ehand.ErrorUserNotFound
+	}
+
+	if !assignee.IsActive() {
+		return ehand.ErrorUserDeactivated
+	}
+
+	return nil
+}
+
+func sameAssignee(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// taskChanges returns the columns of the patched task which differ from the existing task
+func taskChanges(existing, patched *entities.Task) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	if patched.Name != existing.Name {
+		changes["name"] = patched.Name
+	}
+
+	if patched.Description != existing.Description {
+		changes["description"] = patched.Description
+	}
+
+	if patched.Type != existing.Type {
+		changes["type"] = patched.Type
+	}
+
+	if patched.Status != existing.Status {
+		changes["status"] = patched.Status
+	}
+
+	if patched.StoryId != existing.StoryId {
+		changes["story_id"] = patched.StoryId
+	}
+
+	if !sameAssignee(patched.AssigneeId, existing.AssigneeId) {
+		changes["assignee_id"] = patched.AssigneeId
+	}
+
+	return changes
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+)
+
+type TeamService interface {
+	GetTeams(user entities.User) (entities.TeamList, error)
+	GetTeamById(teamId string, user entities.User) (*entities.Team, error)
+	CreateTeam(user entities.User, name string, leadId *uint) (*entities.Team, error)
+	UpdateTeam(teamId string, user entities.User, name *string, leadId *uint) (*entities.Team, error)
+	DeleteTeam(teamId string, user entities.User) error
+	AddMember(teamId string, user entities.User, memberId uint) (*entities.Team, error)
+	RemoveMember(teamId string, user entities.User, memberId uint) error
+	GetWorkload(teamId string, user entities.User) (*entities.TeamWorkload, error)
+}
+
+type teamService struct {
+	log       ilog.StdLogger
+	query     repository.TeamQuery
+	userQuery repository.ApiUserQuery
+}
+
+func NewTeamService(teamQuery repository.TeamQuery, userQuery repository.ApiUserQuery, logger ilog.StdLogger) TeamService {
+	return &teamService{
+		log:       logger,
+		query:     teamQuery,
+		userQuery: userQuery,
+	}
+}
+
+func (t *teamService) GetTeams(user entities.User) (entities.TeamList, error) {
+	teams, err := t.query.GetTeams(user.AccountId)
+	if err != nil {
+		t.log.Errorf("Could not fetch the teams in Account{id=%s}: %s", user.AccountId, err)
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (t *teamService) GetTeamById(teamId string, user entities.User) (*entities.Team, error) {
+	team, err := t.query.GetTeamById(teamId, user.AccountId)
+	if err != nil {
+		t.log.Debugf("Team{id=%s} not found in Account{id=%s}", teamId, user.AccountId)
+		return nil, ehand.ErrorTeamNotFound
+	}
+
+	return team, nil
+}
+
+func (t *teamService) CreateTeam(user entities.User, name string, leadId *uint) (*entities.Team, error) {
+	if leadId != nil {
+		if err := t.validateMember(user, *leadId); err != nil {
+			return nil, err
+		}
+	}
+
+	team := &entities.Team{AccountId: user.AccountId, Name: name, LeadId: leadId}
+	if _, err := t.query.CreateTeam(team); err != nil {
+		t.log.Errorf("Could not create Team: %s", err)
+		return nil, ehand.ErrorTeamNotCreated
+	}
+
+	return t.GetTeamById(team.ID, user)
+}
+
+// UpdateTeam renames the team or changes its lead; omitted fields are left unchanged
+func (t *teamService) UpdateTeam(teamId string, user entities.User, name *string, leadId *uint) (*entities.Team, error) {
+	team, err := t.GetTeamById(teamId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == nil {
+		name = &team.Name
+	}
+
+	if leadId == nil {
+		leadId = team.LeadId
+	} else if err := t.validateMember(user, *leadId); err != nil {
+		return nil, err
+	}
+
+	if err := t.query.UpdateTeam(teamId, *name, leadId); err != nil {
+		t.log.Errorf("Could not update Team{id=%s}: %s", teamId, err)
+		return nil, ehand.ErrorTeamNotUpdated
+	}
+
+	return t.GetTeamById(teamId, user)
+}
+
+func (t *teamService) DeleteTeam(teamId string, user entities.User) error {
+	if _, err := t.GetTeamById(teamId, user); err != nil {
+		return err
+	}
+
+	if err := t.query.DeleteTeam(teamId); err != nil {
+		t.log.Errorf("Could not delete Team{id=%s}: %s", teamId, err)
+		return ehand.ErrorTeamNotUpdated
+	}
+
+	return nil
+}
+
+// AddMember adds an active user in the account to the team; only the team's lead
+// and those allowed to manage teams may change its members
+func (t *teamService) AddMember(teamId string, user entities.User, memberId uint) (*entities.Team, error) {
+	team, err := t.getManagedTeam(teamId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateMember(user, memberId); err != nil {
+		return nil, err
+	}
+
+	if err := t.query.AddMember(team.ID, memberId); err != nil {
+		t.log.Errorf("Could not add User{id=%d} to Team{id=%s}: %s", memberId, teamId, err)
+		return nil, ehand.ErrorTeamNotUpdated
+	}
+
+	return t.GetTeamById(teamId, user)
+}
+
+func (t *teamService) RemoveMember(teamId string, user entities.User, memberId uint) error {
+	team, err := t.getManagedTeam(teamId, user)
+	if err != nil {
+		return err
+	}
+
+	removed, err := t.query.RemoveMember(team.ID, memberId)
+	if err != nil {
+		t.log.Errorf("Could not remove User{id=%d} from Team{id=%s}: %s", memberId, teamId, err)
+		return ehand.ErrorTeamNotUpdated
+	}
+
+	if !removed {
+		return ehand.ErrorTeamMemberNotFound
+	}
+
+	return nil
+}
+
+// GetWorkload counts the open tasks assigned to each member of the team by progress status.
+// Only tasks in projects visible to the user are counted.
+func (t *teamService) GetWorkload(teamId string, user entities.User) (*entities.TeamWorkload, error) {
+	team, err := t.GetTeamById(teamId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := t.query.GetOpenTaskCounts(team.ID, projectScope(user))
+	if err != nil {
+		t.log.Errorf("Could not count the open tasks of Team{id=%s}: %s", teamId, err)
+		return nil, err
+	}
+
+	workload := &entities.TeamWorkload{TeamId: team.ID, Members: entities.MemberWorkloadList{}}
+	byUser := make(map[uint]*entities.MemberWorkload, len(team.Members))
+
+	for _, member := range team.Members {
+		memberWorkload := &entities.MemberWorkload{
+			User:     member.User,
+			ByStatus: map[string]int64{enums.New.String(): 0, enums.InProgress.String(): 0},
+		}
+
+		byUser[member.UserId] = memberWorkload
+		workload.Members = append(workload.Members, memberWorkload)
+	}
+
+	for _, count := range counts {
+		if memberWorkload, ok := byUser[count.AssigneeId]; ok {
+			memberWorkload.Open += count.Count
+			memberWorkload.ByStatus[count.Status.String()] += count.Count
+		}
+	}
+
+	return workload, nil
+}
+
+func (t *teamService) getManagedTeam(teamId string, user entities.User) (*entities.Team, error) {
+	team, err := t.GetTeamById(teamId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	isLead := team.LeadId != nil && *team.LeadId == user.ID
+	if !isLead && !auth.HasPermission(user.Role, auth.PermissionTeamManage) {
+		t.log.Infof("User{id=%d} may not change the members of Team{id=%s}", user.ID, teamId)
+		return nil, ehand.ErrorPermissionDenied
+	}
+
+	return team, nil
+}
+
+// validateMember ensures that only active users in the account join teams
+func (t *teamService) validateMember(user entities.User, memberId uint) error {
+	member, err := t.userQuery.GetUserById(memberId, user.AccountId)
+	if err != nil {
+		return ehand.ErrorUserNotFound
+	}
+
+	if !member.IsActive() {
+		return ehand.ErrorUserDeactivated
+	}
+
+	return nil
+}
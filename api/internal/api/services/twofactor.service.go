@@ -0,0 +1,208 @@
+package services
+
+import (
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository"
+	"godo/internal/repository/entities"
+	"time"
+)
+
+const (
+	totpIssuer        = "Godo"
+	recoveryCodeCount = 10
+
+	// The consecutive failed codes after which the user is locked out, first for twoFactorLockout and
+	// for twice as long after each further failure, up to maxTwoFactorLockout
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = time.Minute
+	maxTwoFactorLockout  = time.Hour
+
+	// The failed codes a login challenge token accepts before it is no longer valid
+	maxChallengeFailures = 3
+)
+
+type TwoFactorService interface {
+	BeginEnrollment(user *entities.User) (*auth.TOTPKey, error)
+	ConfirmEnrollment(user *entities.User, code string) ([]string, error)
+	Verify(user *entities.User, code string) error
+	VerifyChallenge(user *entities.User, code string, issuedFailures uint) error
+	Disable(user *entities.User, code string) error
+}
+
+type twoFactorService struct {
+	log               ilog.StdLogger
+	userQuery         repository.ApiUserQuery
+	recoveryCodeQuery repository.RecoveryCodeQuery
+}
+
+func NewTwoFactorService(
+	userQuery repository.ApiUserQuery,
+	recoveryCodeQuery repository.RecoveryCodeQuery,
+	logger ilog.StdLogger) TwoFactorService {
+
+	return &twoFactorService{
+		log:               logger,
+		userQuery:         userQuery,
+		recoveryCodeQuery: recoveryCodeQuery,
+	}
+}
+
+// BeginEnrollment generates a new TOTP secret for the user.
+// The secret is not enforced until the user confirms it with a valid code.
+func (s *twoFactorService) BeginEnrollment(user *entities.User) (*auth.TOTPKey, error) {
+	if user.TwoFactorEnabled {
+		return nil, ehand.ErrorTwoFactorAlreadyEnabled
+	}
+
+	key, err := auth.GenerateTOTPKey(totpIssuer, user.Email)
+	if err != nil {
+		s.log.Error("Could not generate TOTP key: ", err)
+		return nil, err
+	}
+
+	user.TwoFactorSecret = key.Secret
+	user.TwoFactorLastStep = 0
+	if err := s.userQuery.UpdateUser(user); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication once the user has proven that their
+// authenticator app is set up, returning the plain text recovery codes which are only shown once
+func (s *twoFactorService) ConfirmEnrollment(user *entities.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, ehand.ErrorTwoFactorAlreadyEnabled
+	}
+
+	if user.TwoFactorSecret == "" {
+		return nil, ehand.ErrorTwoFactorNotEnrolled
+	}
+
+	if !s.verifyTOTP(user, code) {
+		return nil, ehand.ErrorTwoFactorInvalidCode
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		s.log.Error("Could not generate recovery codes: ", err)
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = auth.HashRecoveryCode(c)
+	}
+
+	if err := s.recoveryCodeQuery.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	if err := s.userQuery.UpdateUser(user); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify checks the code as either a current TOTP code or an unused recovery code
+func (s *twoFactorService) Verify(user *entities.User, code string) error {
+	return s.verify(user, code, nil)
+}
+
+// VerifyChallenge checks the code completing a login, given with a challenge token issued when the user had
+// failed issuedFailures codes; the challenge is invalidated once maxChallengeFailures more have failed
+func (s *twoFactorService) VerifyChallenge(user *entities.User, code string, issuedFailures uint) error {
+	return s.verify(user, code, &issuedFailures)
+}
+
+func (s *twoFactorService) verify(user *entities.User, code string, issuedFailures *uint) error {
+	if !user.TwoFactorEnabled {
+		return ehand.ErrorTwoFactorNotEnrolled
+	}
+
+	failures, ok, err := s.userQuery.ReserveTwoFactorAttempt(user.ID, twoFactorLockedUntil)
+	if err != nil {
+		return err
+	}
+
+	// A locked out user is told only that the code is not valid, such that the lockout cannot be probed
+	if !ok {
+		s.log.Infof("User{id=%d} gave a two-factor code while locked out", user.ID)
+		return ehand.ErrorTwoFactorInvalidCode
+	}
+
+	if issuedFailures != nil && failures > *issuedFailures+maxChallengeFailures {
+		s.log.Infof("Too many two-factor codes were given with the challenge of User{id=%d}", user.ID)
+		return ehand.ErrorTwoFactorChallengeInvalid
+	}
+
+	if s.verifyTOTP(user, code) {
+		return s.resetFailures(user)
+	}
+
+	used, err := s.recoveryCodeQuery.UseRecoveryCode(user.ID, auth.HashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		s.log.Debugf("Invalid two-factor code given for User{id=%d}", user.ID)
+		return ehand.ErrorTwoFactorInvalidCode
+	}
+
+	return s.resetFailures(user)
+}
+
+// resetFailures saves the user once a code is accepted, clearing their failed codes
+func (s *twoFactorService) resetFailures(user *entities.User) error {
+	user.TwoFactorFailures = 0
+	user.TwoFactorLockedUntil = nil
+	return s.userQuery.UpdateUser(user)
+}
+
+// twoFactorLockedUntil the time until which a user who has failed the number of consecutive codes is locked
+// out, or nil while they may fail more
+func twoFactorLockedUntil(failures uint) *time.Time {
+	if failures < maxTwoFactorFailures {
+		return nil
+	}
+
+	lockout := maxTwoFactorLockout
+	if shift := failures - maxTwoFactorFailures; shift < 16 && twoFactorLockout<<shift < lockout {
+		lockout = twoFactorLockout << shift
+	}
+
+	until := time.Now().Add(lockout)
+	return &until
+}
+
+func (s *twoFactorService) Disable(user *entities.User, code string) error {
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+
+	if err := s.recoveryCodeQuery.DeleteRecoveryCodes(user.ID); err != nil {
+		return err
+	}
+
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	user.TwoFactorLastStep = 0
+	return s.userQuery.UpdateUser(user)
+}
+
+// verifyTOTP validates the code and records its time step so that it cannot be replayed
+func (s *twoFactorService) verifyTOTP(user *entities.User, code string) bool {
+	step, ok := auth.ValidateTOTP(user.TwoFactorSecret, code, time.Now())
+	if !ok || step <= user.TwoFactorLastStep {
+		return false
+	}
+
+	user.TwoFactorLastStep = step
+	return true
+}
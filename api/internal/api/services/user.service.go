@@ -1,27 +1,53 @@
 package services
 
 import (
+	"errors"
 	ehand "godo/internal/api/errorhandler"
+	"godo/internal/auth"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"github.com/jinzhu/gorm"
 )
 
 type UserService interface {
 	GetUserByEmailAddress(email string) (user *entities.User, err error)
 	UserWithEmailAddressExists(email string) (bool, error)
 	CreateUser(newUser entities.User) (*entities.User, error)
+	CreateAccountOwner(newAccount *entities.Account, owner entities.User) (*entities.User, error)
+	ChangeRole(actor entities.User, userId uint, role enums.AccountRole) (*entities.User, error)
+	GetAccountUsers(accountId string) (entities.UserList, error)
+	GetUsersByIds(accountId string, userIds []uint) (entities.UserList, error)
+	UpdateProfile(user entities.User, name, username *string) (*entities.User, error)
+	ChangePassword(user entities.User, currentPassword, newPassword string) (*entities.User, error)
+	GetUserByHandle(handle, accountId string) (*entities.User, error)
+	DeactivateUser(actor entities.User, userId uint) (*entities.User, error)
+	ReactivateUser(actor entities.User, userId uint) (*entities.User, error)
+	OffboardUser(actor entities.User, userId, successorId uint) (*entities.Offboarding, error)
+	UseAccount(user entities.User, accountId string) (*entities.User, error)
+	GetMemberships(user entities.User) (entities.AccountMemberList, error)
+	JoinAccount(user entities.User, invitation *entities.Invitation) (*entities.AccountMember, error)
 }
 
 type userService struct {
-	log   ilog.StdLogger
-	query repository.ApiUserQuery
+	log         ilog.StdLogger
+	query       repository.ApiUserQuery
+	memberQuery repository.AccountMemberQuery
 }
 
-func NewUserService(apiUserQuery repository.ApiUserQuery, logger ilog.StdLogger) UserService {
+func NewUserService(
+	apiUserQuery repository.ApiUserQuery,
+	memberQuery repository.AccountMemberQuery,
+	logger ilog.StdLogger) UserService {
+
 	return &userService{
-		log:   logger,
-		query: apiUserQuery,
+		log:         logger,
+		query:       apiUserQuery,
+		memberQuery: memberQuery,
 	}
 }
 
@@ -53,6 +79,299 @@ func (s *userService) CreateUser(newUser entities.User) (*entities.User, error)
 	return s.query.CreateUser(newUser)
 }
 
+// CreateAccountOwner creates the account along with the user owning it; if either cannot be created, neither is
+func (s *userService) CreateAccountOwner(newAccount *entities.Account, owner entities.User) (*entities.User, error) {
+	exists, err := s.query.UserWithEmailAddressExists(owner.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, ehand.ErrorUserAlreadyExists
+	}
+
+	return s.query.CreateAccountOwner(newAccount, owner)
+}
+
 func (s *userService) UserWithEmailAddressExists(email string) (bool, error) {
 	return s.query.UserWithEmailAddressExists(email)
 }
+
+// ChangeRole changes the role of a user in the actor's account.
+// Only those allowed to manage owners may grant or revoke the owner role.
+func (s *userService) ChangeRole(actor entities.User, userId uint, role enums.AccountRole) (*entities.User, error) {
+	if !role.IsValid() {
+		return nil, ehand.ErrorRoleNotValid
+	}
+
+	user, err := s.query.GetUserById(userId, actor.AccountId)
+	if err != nil {
+		return nil, ehand.ErrorUserNotFound
+	}
+
+	if !user.IsActive() {
+		return nil, ehand.ErrorUserDeactivated
+	}
+
+	involvesOwner := role == enums.Owner || user.Role == enums.Owner
+	if involvesOwner && !auth.HasPermission(actor.Role, auth.PermissionOwnerManage) {
+		s.log.Infof("User{id=%d} may not change the owner role of User{id=%d}", actor.ID, userId)
+		return nil, ehand.ErrorPermissionDenied
+	}
+
+	err = s.query.ChangeRole(userId, actor.AccountId, role)
+	if err == ehand.ErrorLastOwner {
+		return nil, err
+	}
+
+	if err != nil {
+		s.log.Errorf("Could not change the role of User{id=%d}: %s", userId, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	user.Role = role
+	user.RoleValue = role.String()
+	return user, nil
+}
+
+func (s *userService) GetAccountUsers(accountId string) (entities.UserList, error) {
+	users, err := s.query.GetUsersInAccount(accountId)
+	if err != nil {
+		s.log.Errorf("Could not fetch the users in Account{id=%s}: %s", accountId, err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetUsersByIds returns those of the users who are members of the account
+func (s *userService) GetUsersByIds(accountId string, userIds []uint) (entities.UserList, error) {
+	users, err := s.query.GetUsersByIds(userIds, accountId)
+	if err != nil {
+		s.log.Errorf("Could not fetch the users in Account{id=%s}: %s", accountId, err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// UpdateProfile updates the fields of the user's profile which are given.
+// Changing the username issues a new discriminator, changing the user's handle.
+func (s *userService) UpdateProfile(user entities.User, name, username *string) (*entities.User, error) {
+	newName := user.Name
+	if name != nil {
+		newName = *name
+	}
+
+	newUsername := user.Username
+	if username != nil {
+		newUsername = *username
+	}
+
+	err := s.query.UpdateProfile(&user, newName, newUsername)
+
+	// A taken username or an invalid name is the client's to fix
+	var validationErrs validator.ValidationErrors
+	if errors.Is(err, ehand.ErrorUsernameUnavailable) || errors.As(err, &validationErrs) {
+		return nil, err
+	}
+
+	if err != nil {
+		s.log.Errorf("Could not update the profile of User{id=%d}: %s", user.ID, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	return &user, nil
+}
+
+// ChangePassword replaces the user's password once the current password has been verified.
+// Every existing session is revoked; the caller is expected to issue the user a new token.
+func (s *userService) ChangePassword(user entities.User, currentPassword, newPassword string) (*entities.User, error) {
+	if err := user.VerifyPassword(currentPassword); err != nil {
+		s.log.Infof("User{id=%d} gave an incorrect password when changing their password", user.ID)
+		return nil, ehand.ErrorPasswordIncorrect
+	}
+
+	if err := user.HashPassword(newPassword); err != nil {
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	user.SessionVersion++
+
+	if err := s.query.UpdateUser(&user); err != nil {
+		s.log.Errorf("Could not change the password of User{id=%d}: %s", user.ID, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	return &user, nil
+}
+
+// GetUserByHandle finds the user in the account with the given username#discriminator handle
+func (s *userService) GetUserByHandle(handle, accountId string) (*entities.User, error) {
+	username, discriminator, ok := entities.ParseHandle(handle)
+	if !ok {
+		return nil, ehand.ErrorHandleNotValid
+	}
+
+	return s.query.GetUserByHandle(username, discriminator, accountId)
+}
+
+// DeactivateUser prevents a user in the actor's account from logging in, ending their current sessions.
+// The user's work remains attributed to them.
+func (s *userService) DeactivateUser(actor entities.User, userId uint) (*entities.User, error) {
+	user, err := s.getManagedUser(actor, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, ehand.ErrorUserAlreadyDeactivated
+	}
+
+	err = s.query.DeactivateUser(userId, actor.AccountId)
+	if err == ehand.ErrorLastOwner {
+		return nil, err
+	}
+
+	if err != nil {
+		s.log.Errorf("Could not deactivate User{id=%d}: %s", userId, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	s.log.Infof("User{id=%d} was deactivated by User{id=%d}", userId, actor.ID)
+	return s.query.GetUserById(userId, actor.AccountId)
+}
+
+func (s *userService) ReactivateUser(actor entities.User, userId uint) (*entities.User, error) {
+	user, err := s.getManagedUser(actor, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive() {
+		return nil, ehand.ErrorUserNotDeactivated
+	}
+
+	if err := s.query.ReactivateUser(userId, actor.AccountId); err != nil {
+		s.log.Errorf("Could not reactivate User{id=%d}: %s", userId, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	s.log.Infof("User{id=%d} was reactivated by User{id=%d}", userId, actor.ID)
+	return s.query.GetUserById(userId, actor.AccountId)
+}
+
+// OffboardUser deactivates a user who is leaving, handing their open tasks and project memberships
+// to the successor. The successor must be an active user in the account who may change tasks.
+func (s *userService) OffboardUser(actor entities.User, userId, successorId uint) (*entities.Offboarding, error) {
+	user, err := s.getManagedUser(actor, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, ehand.ErrorUserAlreadyDeactivated
+	}
+
+	successor, err := s.query.GetUserById(successorId, actor.AccountId)
+	if err != nil || successor.ID == user.ID || !successor.IsActive() ||
+		!auth.HasPermission(successor.Role, auth.PermissionTaskWrite) {
+
+		return nil, ehand.ErrorSuccessorNotValid
+	}
+
+	offboarding, err := s.query.OffboardUser(userId, successorId, actor.AccountId)
+	if err == ehand.ErrorLastOwner {
+		return nil, err
+	}
+
+	if err != nil {
+		s.log.Errorf("Could not offboard User{id=%d}: %s", userId, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	s.log.Infof("User{id=%d} was offboarded to User{id=%d} by User{id=%d}", userId, successorId, actor.ID)
+	return offboarding, nil
+}
+
+// UseAccount returns the user acting within the given account, or within their default account if none is given.
+// The user must be an active member of the account.
+func (s *userService) UseAccount(user entities.User, accountId string) (*entities.User, error) {
+	if accountId == "" {
+		accountId = user.DefaultAccountId
+	}
+
+	member, err := s.memberQuery.GetMember(accountId, user.ID)
+	if gorm.IsRecordNotFoundError(err) {
+		s.log.Infof("User{id=%d} is not a member of Account{id=%s}", user.ID, accountId)
+		return nil, ehand.ErrorAccountMemberNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	user.ApplyMembership(member)
+	if !user.IsActive() {
+		s.log.Infof("User{id=%d} has been deactivated in Account{id=%s}", user.ID, accountId)
+		return nil, ehand.ErrorUserDeactivated
+	}
+
+	return &user, nil
+}
+
+// GetMemberships returns every account the user is a member of, marking their default account
+func (s *userService) GetMemberships(user entities.User) (entities.AccountMemberList, error) {
+	members, err := s.memberQuery.GetMemberships(user.ID)
+	if err != nil {
+		s.log.Errorf("Could not fetch the memberships of User{id=%d}: %s", user.ID, err)
+		return nil, err
+	}
+
+	for _, member := range members {
+		member.Default = member.AccountId == user.DefaultAccountId
+	}
+
+	return members, nil
+}
+
+// JoinAccount makes the user a member of the account they were invited to.
+// The invitation must have been claimed and sent to the user's email address.
+func (s *userService) JoinAccount(user entities.User, invitation *entities.Invitation) (*entities.AccountMember, error) {
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		s.log.Infof("User{id=%d} attempted to accept Invitation{id=%s} sent to another email address", user.ID, invitation.ID)
+		return nil, ehand.ErrorInvitationNotValid
+	}
+
+	if _, err := s.memberQuery.GetMember(invitation.AccountId, user.ID); err == nil {
+		return nil, ehand.ErrorAccountMemberAlreadyExists
+	}
+
+	member, err := s.memberQuery.AddMember(invitation.AccountId, user.ID, invitation.Role)
+	if err != nil {
+		s.log.Errorf("Could not add User{id=%d} to Account{id=%s}: %s", user.ID, invitation.AccountId, err)
+		return nil, ehand.ErrorUserNotUpdated
+	}
+
+	return member, nil
+}
+
+// getManagedUser fetches a user whose activation the actor may change; only those
+// allowed to manage owners may change owners, and no one may deactivate themselves
+func (s *userService) getManagedUser(actor entities.User, userId uint) (*entities.User, error) {
+	if actor.ID == userId {
+		return nil, ehand.ErrorDeactivateSelf
+	}
+
+	user, err := s.query.GetUserById(userId, actor.AccountId)
+	if err != nil {
+		return nil, ehand.ErrorUserNotFound
+	}
+
+	if user.Role == enums.Owner && !auth.HasPermission(actor.Role, auth.PermissionOwnerManage) {
+		s.log.Infof("User{id=%d} may not change the activation of owner User{id=%d}", actor.ID, userId)
+		return nil, ehand.ErrorPermissionDenied
+	}
+
+	return user, nil
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Version a version of the API, served under its own path prefix
+type Version struct {
+	// Number the major version, e.g. 1 for /api/v1
+	Number int
+
+	// Prefix the path under which the version's routes are mounted
+	Prefix string
+
+	// Deprecated whether clients are warned, by the Deprecation header, to move to the successor
+	Deprecated bool
+
+	// Sunset when the deprecated version stops being served, if decided
+	Sunset *time.Time
+
+	// Successor the prefix of the version replacing the deprecated version
+	Successor string
+}
+
+// VersionKey the context key of the API version serving the request
+type VersionKey struct{}
+
+// NewVersion returns the current version with the given number, mounted under /api/v<number>
+func NewVersion(number int) Version {
+	return Version{Number: number, Prefix: fmt.Sprintf("/api/v%d", number)}
+}
+
+// Name the name of the version, e.g. v1
+func (v Version) Name() string {
+	return fmt.Sprintf("v%d", v.Number)
+}
+
+// VersionFromContext returns the API version serving the request, such that handlers may
+// decode and respond with the DTOs of that version
+func VersionFromContext(ctx context.Context) Version {
+	if v, ok := ctx.Value(VersionKey{}).(Version); ok {
+		return v
+	}
+
+	return Version{}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Algorithms supported for signing JWTs
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmHS512 = "HS512"
+)
+
+const rsaKeyBits = 2048
+
+var ErrUnsupportedAlgorithm = errors.New("unsupported JWT signing algorithm")
+
+// Key a key used to sign or verify JWTs, identified in the token header by its kid
+type Key struct {
+	Kid       string
+	Algorithm string
+
+	// signingKey is a crypto.Signer for asymmetric keys, or the shared secret for HMAC keys
+	signingKey interface{}
+}
+
+// GenerateKey creates a new asymmetric key for the given algorithm with a random kid
+func GenerateKey(algorithm string) (*Key, error) {
+	var signingKey interface{}
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		signingKey, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, signingKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{Kid: uuid.NewV4().String(), Algorithm: algorithm, signingKey: signingKey}, nil
+}
+
+// NewHMACKey wraps a shared secret; HMAC keys can't be published and so are only used to verify legacy tokens
+func NewHMACKey(kid string, secret []byte) *Key {
+	return &Key{Kid: kid, Algorithm: AlgorithmHS512, signingKey: secret}
+}
+
+// ParseKey restores a key from its PKCS #8 PEM encoding
+func ParseKey(kid, algorithm, privateKeyPEM string) (*Key, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("the private key for kid %s is not PEM encoded", kid)
+	}
+
+	signingKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{Kid: kid, Algorithm: algorithm, signingKey: signingKey}
+	if _, err := key.publicKey(); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// PrivateKeyPEM returns the PKCS #8 PEM encoding of the private key
+func (k *Key) PrivateKeyPEM() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.signingKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// Sign signs the claims, adding the kid header so verifiers can select the matching key
+func (k *Key) Sign(claims jwt.Claims) (string, error) {
+	t := jwt.NewWithClaims(k.SigningMethod(), claims)
+	t.Header["kid"] = k.Kid
+	return t.SignedString(k.signingKey)
+}
+
+// VerificationKey the key as expected by the jwt library when verifying a signature
+func (k *Key) VerificationKey() (interface{}, error) {
+	if k.Algorithm == AlgorithmHS512 {
+		return k.signingKey, nil
+	}
+
+	return k.publicKey()
+}
+
+func (k *Key) publicKey() (crypto.PublicKey, error) {
+	switch key := k.signingKey.(type) {
+	case *rsa.PrivateKey:
+		if k.Algorithm == AlgorithmRS256 {
+			return key.Public(), nil
+		}
+	case ed25519.PrivateKey:
+		if k.Algorithm == AlgorithmEdDSA {
+			return key.Public(), nil
+		}
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// JWK a public key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA public key parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP public key parameters
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS a set of public keys published so that other services can verify our tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public half of the key; false is returned for keys which can't be published
+func (k *Key) JWK() (JWK, bool) {
+	pub, err := k.publicKey()
+	if err != nil {
+		return JWK{}, false
+	}
+
+	jwk := JWK{Kid: k.Kid, Use: "sig", Alg: k.Algorithm}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk, true
+}
@@ -0,0 +1,92 @@
+package auth
+
+import "godo/internal/repository/enums"
+
+// Permission an action a user may be allowed to perform within their account
+type Permission string
+
+const (
+	PermissionProjectRead   Permission = "project:read"
+	PermissionProjectWrite  Permission = "project:write"
+	PermissionProjectDelete Permission = "project:delete"
+	PermissionStoryRead     Permission = "story:read"
+	PermissionStoryWrite    Permission = "story:write"
+	PermissionTaskRead      Permission = "task:read"
+	PermissionTaskWrite     Permission = "task:write"
+	PermissionUserRead      Permission = "user:read"
+	PermissionTeamRead      Permission = "team:read"
+
+	// PermissionProfileManage changing one's own profile and password
+	PermissionProfileManage Permission = "profile:manage"
+
+	// PermissionProjectAdmin access to every project in the account, regardless of project membership
+	PermissionProjectAdmin Permission = "project:admin"
+
+	// PermissionTeamManage creating, renaming and deleting teams, and managing the members of any team
+	PermissionTeamManage Permission = "team:manage"
+
+	// PermissionUserManage inviting users and changing the role of non-owners
+	PermissionUserManage Permission = "user:manage"
+
+	// PermissionAccountRead viewing the account and its settings
+	PermissionAccountRead Permission = "account:read"
+
+	// PermissionAccountManage changing the account settings
+	PermissionAccountManage Permission = "account:manage"
+
+	// PermissionAccountDelete scheduling or cancelling the deletion of the account
+	PermissionAccountDelete Permission = "account:delete"
+
+	// PermissionOwnerManage granting or revoking the owner role
+	PermissionOwnerManage Permission = "owner:manage"
+)
+
+var viewerPermissions = []Permission{
+	PermissionProjectRead,
+	PermissionStoryRead,
+	PermissionTaskRead,
+	PermissionUserRead,
+	PermissionProfileManage,
+	PermissionAccountRead,
+	PermissionTeamRead,
+}
+
+var memberPermissions = append([]Permission{
+	PermissionProjectWrite,
+	PermissionStoryWrite,
+	PermissionTaskWrite,
+}, viewerPermissions...)
+
+var adminPermissions = append([]Permission{
+	PermissionProjectDelete,
+	PermissionProjectAdmin,
+	PermissionUserManage,
+	PermissionAccountManage,
+	PermissionTeamManage,
+}, memberPermissions...)
+
+var ownerPermissions = append([]Permission{
+	PermissionOwnerManage,
+	PermissionAccountDelete,
+}, adminPermissions...)
+
+var rolePermissions = map[enums.AccountRole]map[Permission]bool{
+	enums.Viewer: toSet(viewerPermissions),
+	enums.Member: toSet(memberPermissions),
+	enums.Admin:  toSet(adminPermissions),
+	enums.Owner:  toSet(ownerPermissions),
+}
+
+// HasPermission whether users with the given role are allowed the permission
+func HasPermission(role enums.AccountRole, permission Permission) bool {
+	return rolePermissions[role][permission]
+}
+
+func toSet(permissions []Permission) map[Permission]bool {
+	set := make(map[Permission]bool, len(permissions))
+	for _, p := range permissions {
+		set[p] = true
+	}
+
+	return set
+}
@@ -2,7 +2,7 @@ package auth
 
 import (
 	"encoding/json"
-	"github.com/go-playground/validator"
+	"godo/internal/helper/validate"
 	"io"
 	"log"
 )
@@ -14,6 +14,10 @@ type TokenRequest struct {
 
 type TokenRequestKey struct{}
 
+func init() {
+	validate.Register(TokenRequest{})
+}
+
 func (t *TokenRequest) FromJSON(r io.Reader) error {
 	e := json.NewDecoder(r)
 	err := e.Decode(t)
@@ -25,6 +29,5 @@ func (t *TokenRequest) FromJSON(r io.Reader) error {
 }
 
 func (t *TokenRequest) Validate() error {
-	validate := validator.New()
 	return validate.Struct(t)
 }
@@ -1,7 +1,9 @@
 package router_builder
 
 import (
+	"godo/configuration"
 	"godo/internal/api/middleware"
+	"godo/internal/api/openapi"
 	"godo/internal/helper/ilog"
 )
 
@@ -10,15 +12,17 @@ type MiddlewareCollection struct {
 	Account middleware.AccountMiddleware
 	Auth    middleware.AuthMiddleware
 	Project middleware.ProjectMiddleware
+	OpenApi middleware.OpenApiMiddleware
 }
 
-func newMiddlewareCollection(sc ServiceCollection) MiddlewareCollection {
+func newMiddlewareCollection(sc ServiceCollection, docs map[int]*openapi.Document, config configuration.Config) MiddlewareCollection {
 	logger := ilog.MakeLoggerWithTag("Middleware")
 
 	return MiddlewareCollection{
 		Generic: middleware.NewGenericMiddleware(logger),
 		Account: middleware.NewAccountMiddleware(logger),
-		Auth:    middleware.NewAuthMiddleware(logger, sc.authService, sc.userService),
+		Auth:    middleware.NewAuthMiddleware(logger, sc.authService, sc.userService, sc.accountService),
 		Project: middleware.NewProjectMiddleware(logger),
+		OpenApi: middleware.NewOpenApiMiddleware(logger, docs, config.OpenApiValidation),
 	}
 }
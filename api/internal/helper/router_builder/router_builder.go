@@ -1,42 +1,95 @@
 package router_builder
 
 import (
+	"fmt"
 	redoc "github.com/go-openapi/runtime/middleware"
 	"github.com/gorilla/mux"
 	"godo/configuration"
+	"godo/internal/api"
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/api/graphql"
+	"godo/internal/api/grpc"
 	"godo/internal/api/handler"
+	"godo/internal/api/middleware"
+	"godo/internal/api/openapi"
+	"godo/internal/auth"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository"
+	grpcgo "google.golang.org/grpc"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 )
 
 type RouterBuilder interface {
 	Init() *mux.Router
+
+	// InitGRPC returns the gRPC server, served by the same services as the router
+	InitGRPC() *grpcgo.Server
+
+	// CheckRoutes reports every operation in the API documents which is not routed and every route
+	// which is not documented; call after Init
+	CheckRoutes() error
+
+	// Document reduces the API document scanned from the handlers to that of the named version, listing
+	// only the operations it routes; call after Init
+	Document(name string, scanned []byte) ([]byte, error)
 }
 
 type routerBuilder struct {
-	router *mux.Router // Base router
-	r      *mux.Router // Unauthenticated router
-	ar     *mux.Router // Authenticated router
-	sc     ServiceCollection
-	mc     MiddlewareCollection
+	router   *mux.Router // Base router
+	r        *mux.Router // Unauthenticated router
+	ar       *mux.Router // Authenticated router
+	er       *mux.Router // Authenticated router not requiring two-factor enrollment
+	version  api.Version // The version whose routes are being built
+	versions []api.Version
+	docs     map[int]*openapi.Document // The API document of each version number
+	validate bool                      // Whether requests are validated against the API documents
+	graphql  graphql.Limits
+	grpc     grpc.Options
+	sc       ServiceCollection
+	mc       MiddlewareCollection
 }
 
 func New(dao repository.DAO, config configuration.Config) RouterBuilder {
-	sc := newServiceCollection(dao, config.JWTKey)
-	mc := newMiddlewareCollection(sc)
+	v1 := api.NewVersion(1)
+
+	// The unversioned prefix serves v1 until it is retired
+	legacy := v1
+	legacy.Prefix = "/api"
+	legacy.Deprecated = true
+	legacy.Successor = v1.Prefix
+	legacy.Sunset = legacySunset(config)
+
+	versions := []api.Version{v1, legacy}
+	docs := loadDocuments(versions)
+
+	sc := newServiceCollection(dao, config)
+	mc := newMiddlewareCollection(sc, docs, config)
 
 	router := mux.NewRouter()
-	openRouter := router.PathPrefix("/api").Subrouter()
-	authedRouter := router.PathPrefix("/api").Subrouter()
-	authedRouter.Use(mc.Auth.AuthenticateRequestMiddleware)
+	router.Use(mc.Generic.RequestIdMiddleware)
+	router.Use(mc.Generic.ConditionalGetMiddleware)
+	router.Use(mc.Generic.CompressionMiddleware)
+
+	eh := ehand.New()
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eh.HandleApiError(w, ehand.ErrorRouteNotFound)
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eh.HandleApiError(w, ehand.ErrorMethodNotAllowed)
+	})
 
 	return &routerBuilder{
-		router: router,
-		r:      openRouter,
-		ar:     authedRouter,
-		sc:     sc,
-		mc:     mc,
+		router:   router,
+		versions: versions,
+		docs:     docs,
+		validate: openApiValidation(config) != middleware.OpenApiValidationOff,
+		graphql:  graphql.Limits{MaxDepth: config.GraphQLMaxDepth, MaxComplexity: config.GraphQLMaxComplexity},
+		grpc:     grpc.Options{WatchInterval: config.GrpcWatchInterval, Reflection: config.GrpcReflection},
+		sc:       sc,
+		mc:       mc,
 	}
 }
 
@@ -45,15 +98,193 @@ func (b *routerBuilder) Init() *mux.Router {
 	return b.router
 }
 
+func (b *routerBuilder) InitGRPC() *grpcgo.Server {
+	logger := ilog.MakeLoggerWithTag("GRPC")
+
+	if b.grpc.WatchInterval <= 0 {
+		logger.Fatalf("GRPC_WATCH_INTERVAL must be a positive duration such as 5s, not %s", b.grpc.WatchInterval)
+	}
+
+	return grpc.New(grpc.Services{
+		Projects: b.sc.projectService,
+		Stories:  b.sc.storyService,
+		Tasks:    b.sc.taskService,
+		Tags:     b.sc.tagService,
+	}, &b.mc.Auth, b.grpc, logger)
+}
+
 func (b *routerBuilder) buildRouters() {
-	b.buildAccountRouter()
-	b.buildProjectRouter()
-	b.buildStoryRouter()
-	b.buildTaskRouter()
+	b.buildGraphQL()
+
+	for _, version := range b.versions {
+		b.mount(version)
 
+		b.buildAccountRouter()
+		b.buildProjectRouter()
+		b.buildStoryRouter()
+		b.buildTaskRouter()
+		b.buildTeamRouter()
+	}
+
+	b.buildWellKnown()
 	b.buildSwagger()
 }
 
+// mount creates the routers of the version under its prefix, to which the routes are then added
+func (b *routerBuilder) mount(version api.Version) {
+	versionMiddleware := b.mc.Generic.VersionMiddleware(version, b.router)
+
+	b.version = version
+	b.r = b.router.PathPrefix(version.Prefix).Subrouter()
+	b.r.Use(versionMiddleware)
+	b.er = b.router.PathPrefix(version.Prefix).Subrouter()
+	b.er.Use(versionMiddleware)
+	b.er.Use(b.mc.Auth.AuthenticateRequestMiddleware)
+	b.ar = b.router.PathPrefix(version.Prefix).Subrouter()
+	b.ar.Use(versionMiddleware)
+	b.ar.Use(b.mc.Auth.AuthenticateRequestMiddleware)
+	b.ar.Use(b.mc.Auth.RequireTwoFactorEnrollmentMiddleware)
+
+	b.r.Use(b.mc.Generic.NegotiationMiddleware)
+	b.er.Use(b.mc.Generic.NegotiationMiddleware)
+	b.ar.Use(b.mc.Generic.NegotiationMiddleware)
+
+	// Validated once authenticated, such that unauthenticated clients are told so first
+	if b.validate {
+		b.r.Use(b.mc.OpenApi.ValidateMiddleware)
+		b.er.Use(b.mc.OpenApi.ValidateMiddleware)
+		b.ar.Use(b.mc.OpenApi.ValidateMiddleware)
+	}
+}
+
+// legacySunset the date from which the unversioned API prefix is no longer served, if decided
+func legacySunset(config configuration.Config) *time.Time {
+	if config.LegacyApiSunset == "" {
+		return nil
+	}
+
+	sunset, err := time.Parse("2006-01-02", config.LegacyApiSunset)
+	if err != nil {
+		ilog.MakeLoggerWithTag("RouterBuilder").Fatalf("LEGACY_API_SUNSET must be a date such as 2006-01-02: %s", err)
+	}
+
+	return &sunset
+}
+
+// openApiValidation the OPENAPI_VALIDATION mode
+func openApiValidation(config configuration.Config) string {
+	switch config.OpenApiValidation {
+	case middleware.OpenApiValidationOff, middleware.OpenApiValidationRequests, middleware.OpenApiValidationResponses:
+		return config.OpenApiValidation
+	}
+
+	ilog.MakeLoggerWithTag("RouterBuilder").Fatalf(
+		"OPENAPI_VALIDATION must be %s, %s or %s, not %q",
+		middleware.OpenApiValidationOff, middleware.OpenApiValidationRequests, middleware.OpenApiValidationResponses,
+		config.OpenApiValidation,
+	)
+	return ""
+}
+
+// loadDocuments loads the API document of each version number from ./swagger/<version>.yaml
+func loadDocuments(versions []api.Version) map[int]*openapi.Document {
+	docs := make(map[int]*openapi.Document)
+	for _, version := range versions {
+		if _, ok := docs[version.Number]; ok {
+			continue
+		}
+
+		doc, err := openapi.Load("./swagger/" + version.Name() + ".yaml")
+		if err != nil {
+			ilog.MakeLoggerWithTag("RouterBuilder").Fatalf("Could not load the API document of %s: %s", version.Name(), err)
+		}
+
+		docs[version.Number] = doc
+	}
+
+	return docs
+}
+
+func (b *routerBuilder) CheckRoutes() error {
+	var mismatches []string
+
+	for _, version := range b.versions {
+		if version.Deprecated {
+			continue
+		}
+
+		routed, err := b.routedOperations(version)
+		if err != nil {
+			return err
+		}
+
+		documented := make(map[string]bool)
+		for _, operation := range b.docs[version.Number].Operations() {
+			documented[operation] = true
+
+			if !routed[operation] {
+				mismatches = append(mismatches, fmt.Sprintf("%s: %s is documented but not routed", version.Name(), operation))
+			}
+		}
+
+		for operation := range routed {
+			if !documented[operation] {
+				mismatches = append(mismatches, fmt.Sprintf("%s: %s is routed but not documented", version.Name(), operation))
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("the routes do not match the API documents:\n%s", strings.Join(mismatches, "\n"))
+	}
+
+	return nil
+}
+
+func (b *routerBuilder) Document(name string, scanned []byte) ([]byte, error) {
+	for _, version := range b.versions {
+		if version.Deprecated || version.Name() != name {
+			continue
+		}
+
+		routed, err := b.routedOperations(version)
+		if err != nil {
+			return nil, err
+		}
+
+		return openapi.Prune(scanned, version.Prefix, routed)
+	}
+
+	return nil, fmt.Errorf("there is no version %s", name)
+}
+
+// routedOperations the operations routed under the version's prefix, keyed as by openapi.OperationKey
+func (b *routerBuilder) routedOperations(version api.Version) (map[string]bool, error) {
+	operations := make(map[string]bool)
+
+	err := b.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, version.Prefix+"/") {
+			return nil
+		}
+
+		// Prefixes, such as that of the version, have no methods
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			operations[openapi.OperationKey(method, strings.TrimPrefix(template, version.Prefix))] = true
+		}
+
+		return nil
+	})
+
+	return operations, err
+}
+
 func (b *routerBuilder) buildAccountRouter() {
 	userHandlerLogger := ilog.MakeLoggerWithTag("UserHandler")
 	userHandler := handler.NewUsersHandler(
@@ -61,79 +292,231 @@ func (b *routerBuilder) buildAccountRouter() {
 		b.sc.authService,
 		b.sc.accountService,
 		b.sc.userService,
+		b.sc.twoFactorService,
+		b.sc.invitationService,
 	)
 
 	b.r.HandleFunc("/auth/login", userHandler.Login).Methods(http.MethodPost)
+	b.r.HandleFunc("/auth/2fa", userHandler.CompleteTwoFactorLogin).Methods(http.MethodPost)
 	b.r.HandleFunc("/auth/register", userHandler.Register).Methods(http.MethodPost)
+
+	accountHandlerLogger := ilog.MakeLoggerWithTag("AccountHandler")
+	accountHandler := handler.NewAccountsHandler(accountHandlerLogger, b.sc.accountService, b.sc.userService)
+
+	createAccount := b.mc.Account.ValidateNewAccountDtoMiddleware(http.HandlerFunc(accountHandler.CreateAccount))
+	b.r.Handle("/account", createAccount).Methods(http.MethodPost)
+
+	b.name(api.RouteAccount, b.Get("/account", accountHandler.GetAccount, auth.PermissionAccountRead))
+	b.Patch("/account", accountHandler.UpdateAccount, auth.PermissionAccountManage)
+	b.Put("/account/2fa", accountHandler.UpdateTwoFactorRequirement, auth.PermissionAccountManage)
+	b.Post("/account/deletion", accountHandler.ScheduleDeletion, auth.PermissionAccountDelete)
+	b.Delete("/account/deletion", accountHandler.CancelDeletion, auth.PermissionAccountDelete)
+	b.Get("/account/users", userHandler.GetAccountUsers, auth.PermissionUserRead)
+	b.Put("/account/user/{id:[0-9]+}/role", userHandler.ChangeRole, auth.PermissionUserManage)
+	b.Post("/account/user/{id:[0-9]+}/deactivation", userHandler.DeactivateUser, auth.PermissionUserManage)
+	b.Delete("/account/user/{id:[0-9]+}/deactivation", userHandler.ReactivateUser, auth.PermissionUserManage)
+	b.Post("/account/user/{id:[0-9]+}/offboard", userHandler.OffboardUser, auth.PermissionUserManage)
+
+	b.Get("/user/lookup", userHandler.LookupUser, auth.PermissionUserRead)
+
+	// The authenticated user
+	b.Get("/me", userHandler.GetMe, auth.PermissionProfileManage)
+	b.Patch("/me", userHandler.UpdateMe, auth.PermissionProfileManage)
+	b.Post("/me/password", userHandler.ChangePassword, auth.PermissionProfileManage)
+	b.Post("/me/accounts", userHandler.JoinAccount, auth.PermissionProfileManage)
+
+	// Invitations
+	invitationLogger := ilog.MakeLoggerWithTag("InvitationHandler")
+	invitationHandler := handler.NewInvitationsHandler(invitationLogger, b.sc.invitationService)
+
+	b.Post("/account/invite", invitationHandler.CreateInvitation, auth.PermissionUserManage)
+	b.Get("/account/invite", invitationHandler.GetInvitations, auth.PermissionUserManage)
+	b.Post("/account/invite/{id:[a-f0-9-]+}/resend", invitationHandler.ResendInvitation, auth.PermissionUserManage)
+	b.Delete("/account/invite/{id:[a-f0-9-]+}", invitationHandler.RevokeInvitation, auth.PermissionUserManage)
+
+	// Two-factor enrollment must remain reachable by users whose account requires it
+	twoFactorLogger := ilog.MakeLoggerWithTag("TwoFactorHandler")
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorLogger, b.sc.twoFactorService)
+
+	b.er.HandleFunc("/user/2fa", twoFactorHandler.BeginEnrollment).Methods(http.MethodPost)
+	b.er.HandleFunc("/user/2fa/confirm", twoFactorHandler.ConfirmEnrollment).Methods(http.MethodPost)
+	b.er.HandleFunc("/user/2fa", twoFactorHandler.Disable).Methods(http.MethodDelete)
+
+	// Switching account must remain possible when the current account requires two-factor enrollment
+	b.er.HandleFunc("/auth/switch-account", userHandler.SwitchAccount).Methods(http.MethodPost)
+	b.er.HandleFunc("/me/accounts", userHandler.GetMyAccounts).Methods(http.MethodGet)
+
+	// Data-subject requests must remain possible when the current account requires two-factor enrollment
+	personalDataLogger := ilog.MakeLoggerWithTag("PersonalDataHandler")
+	personalDataHandler := handler.NewPersonalDataHandler(personalDataLogger, b.sc.personalDataService)
+
+	b.er.HandleFunc("/me/export", personalDataHandler.ExportMe).Methods(http.MethodGet)
+	b.er.HandleFunc("/me/erasure", personalDataHandler.EraseMe).Methods(http.MethodPost)
 }
 
 func (b *routerBuilder) buildProjectRouter() {
 	projectLogger := ilog.MakeLoggerWithTag("ProjectHandler")
 	projectHandler := handler.NewProjectsHandler(projectLogger, b.sc.projectService, b.sc.tagService)
 
-	b.Post("/project", projectHandler.CreateProject)
-	b.Get("/project", projectHandler.GetAllProjects)
-	b.Get("/project/{id:[a-f0-9-]+}", projectHandler.GetProjectById)
-	b.Delete("/project/{id:[a-f0-9-]+}", projectHandler.DeleteProject)
+	b.Post("/project", projectHandler.CreateProject, auth.PermissionProjectWrite)
+	b.name(api.RouteProjects, b.Get("/project", projectHandler.GetAllProjects, auth.PermissionProjectRead))
+	b.name(api.RouteProject, b.Get("/project/{id:[a-f0-9-]+}", projectHandler.GetProjectById, auth.PermissionProjectRead))
+	b.Put("/project/{id:[a-f0-9-]+}", projectHandler.UpdateProject, auth.PermissionProjectWrite)
+	b.Patch("/project/{id:[a-f0-9-]+}", projectHandler.PatchProject, auth.PermissionProjectWrite)
+	b.Delete("/project/{id:[a-f0-9-]+}", projectHandler.DeleteProject, auth.PermissionProjectDelete)
 
 	// Status
-	b.Put("/project/{id:[a-f0-9-]+}/status", projectHandler.UpdateProjectStatus)
+	b.Put("/project/{id:[a-f0-9-]+}/status", projectHandler.UpdateProjectStatus, auth.PermissionProjectWrite)
+
+	// Visibility and members
+	b.Put("/project/{id:[a-f0-9-]+}/visibility", projectHandler.UpdateProjectVisibility, auth.PermissionProjectWrite)
+	b.Put("/project/{id:[a-f0-9-]+}/team", projectHandler.UpdateProjectTeam, auth.PermissionProjectWrite)
+	b.Get("/project/{id:[a-f0-9-]+}/member", projectHandler.GetProjectMembers, auth.PermissionProjectRead)
+	b.Put("/project/{id:[a-f0-9-]+}/member/{userId:[0-9]+}", projectHandler.SetProjectMember, auth.PermissionProjectWrite)
+	b.Delete("/project/{id:[a-f0-9-]+}/member/{userId:[0-9]+}", projectHandler.RemoveProjectMember, auth.PermissionProjectWrite)
 
 	// Tags
-	b.Post("/project/{id:[a-f0-9-]+}/tag", projectHandler.AddTagToProject)
-	b.Delete("/project/{projectId:[a-f0-9-]+}/tag/{tagId:[0-9]+}", projectHandler.DeleteProjectTag)
+	b.Post("/project/{id:[a-f0-9-]+}/tag", projectHandler.AddTagToProject, auth.PermissionProjectWrite)
+	b.Delete("/project/{projectId:[a-f0-9-]+}/tag/{tagId:[0-9]+}", projectHandler.DeleteProjectTag, auth.PermissionProjectWrite)
 }
 
 func (b *routerBuilder) buildStoryRouter() {
 	storyLogger := ilog.MakeLoggerWithTag("StoryHandler")
-	storyHandler := handler.NewStoriesHandler(storyLogger, b.sc.storyService, b.sc.projectService)
+	storyHandler := handler.NewStoriesHandler(storyLogger, b.sc.storyService)
 
-	b.Get("/story", storyHandler.GetStoriesInfo)
-	b.Get("/story", storyHandler.CreateStory)
-	b.Get("/story/{id:[a-f0-9-]+}", storyHandler.GetStoryById)
-	b.Put("/story/{id:[a-f0-9-]+}", storyHandler.UpdateStory)
-	b.Delete("/story/{id:[a-f0-9-]+}", storyHandler.DeleteStory)
+	b.name(api.RouteStories, b.Get("/story", storyHandler.GetStoriesInfo, auth.PermissionStoryRead))
+	b.Post("/story", storyHandler.CreateStory, auth.PermissionStoryWrite)
+	b.name(api.RouteStory, b.Get("/story/{id:[a-f0-9-]+}", storyHandler.GetStoryById, auth.PermissionStoryRead))
+	b.Put("/story/{id:[a-f0-9-]+}", storyHandler.UpdateStory, auth.PermissionStoryWrite)
+	b.Patch("/story/{id:[a-f0-9-]+}", storyHandler.PatchStory, auth.PermissionStoryWrite)
+	b.Delete("/story/{id:[a-f0-9-]+}", storyHandler.DeleteStory, auth.PermissionStoryWrite)
 }
 
 func (b *routerBuilder) buildTaskRouter() {
 	taskLogger := ilog.MakeLoggerWithTag("TaskHandler")
 	taskHandler := handler.NewTasksHandler(taskLogger, b.sc.taskService, b.sc.tagService)
 
-	b.Post("/task", taskHandler.CreateTask)
-	b.Get("/task", taskHandler.GetAllTasks)
-	b.Get("/task/{id:[a-f0-9-]+}", taskHandler.GetTaskById)
-	b.Put("/task/{id:[a-f0-9-]+}", taskHandler.UpdateTask)
+	b.Post("/task", taskHandler.CreateTask, auth.PermissionTaskWrite)
+	b.name(api.RouteTasks, b.Get("/task", taskHandler.GetAllTasks, auth.PermissionTaskRead))
+	b.name(api.RouteTask, b.Get("/task/{id:[a-f0-9-]+}", taskHandler.GetTaskById, auth.PermissionTaskRead))
+	b.Put("/task/{id:[a-f0-9-]+}", taskHandler.UpdateTask, auth.PermissionTaskWrite)
+	b.Patch("/task/{id:[a-f0-9-]+}", taskHandler.PatchTask, auth.PermissionTaskWrite)
 
 	// Type and status
-	b.Put("/task/{id:[a-f0-9-]+}/type", taskHandler.UpdateTaskStatus)
-	b.Put("/task/{id:[a-f0-9-]+}/status", taskHandler.UpdateTaskStatus)
+	b.Put("/task/{id:[a-f0-9-]+}/type", taskHandler.UpdateTaskType, auth.PermissionTaskWrite)
+	b.Put("/task/{id:[a-f0-9-]+}/status", taskHandler.UpdateTaskStatus, auth.PermissionTaskWrite)
 
 	// Tags
-	b.Put("/task/{taskId:[a-f0-9-]+}/tag/{tagId:[0-9]+}", taskHandler.AddTag)
-	b.Delete("/task/{taskId:[a-f0-9-]+}/tag/{tagId:[0-9]+}", taskHandler.RemoveTag)
+	b.Put("/task/{taskId:[a-f0-9-]+}/tag/{tagId:[0-9]+}", taskHandler.AddTag, auth.PermissionTaskWrite)
+	b.Delete("/task/{taskId:[a-f0-9-]+}/tag/{tagId:[0-9]+}", taskHandler.RemoveTag, auth.PermissionTaskWrite)
+}
+
+func (b *routerBuilder) buildTeamRouter() {
+	teamLogger := ilog.MakeLoggerWithTag("TeamHandler")
+	teamHandler := handler.NewTeamsHandler(teamLogger, b.sc.teamService)
+
+	b.Post("/team", teamHandler.CreateTeam, auth.PermissionTeamManage)
+	b.Get("/team", teamHandler.GetTeams, auth.PermissionTeamRead)
+	b.Get("/team/{id:[a-f0-9-]+}", teamHandler.GetTeamById, auth.PermissionTeamRead)
+	b.Patch("/team/{id:[a-f0-9-]+}", teamHandler.UpdateTeam, auth.PermissionTeamManage)
+	b.Delete("/team/{id:[a-f0-9-]+}", teamHandler.DeleteTeam, auth.PermissionTeamManage)
+	b.Get("/team/{id:[a-f0-9-]+}/workload", teamHandler.GetTeamWorkload, auth.PermissionTeamRead)
+
+	// Members; the team's lead may change its members without the team management permission
+	b.Put("/team/{id:[a-f0-9-]+}/member/{userId:[0-9]+}", teamHandler.AddTeamMember, auth.PermissionTeamRead)
+	b.Delete("/team/{id:[a-f0-9-]+}/member/{userId:[0-9]+}", teamHandler.RemoveTeamMember, auth.PermissionTeamRead)
+}
+
+// buildGraphQL serves the GraphQL API at /api/graphql, authenticated as the REST routes are; it is not
+// versioned, the schema instead evolving by deprecating its fields
+func (b *routerBuilder) buildGraphQL() {
+	logger := ilog.MakeLoggerWithTag("GraphQL")
+
+	graphqlApi, err := graphql.New(graphql.Services{
+		Projects: b.sc.projectService,
+		Stories:  b.sc.storyService,
+		Tasks:    b.sc.taskService,
+		Tags:     b.sc.tagService,
+		Users:    b.sc.userService,
+	}, b.graphql, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	graphqlLogger := ilog.MakeLoggerWithTag("GraphQLHandler")
+	graphqlHandler := handler.NewGraphQLHandler(graphqlLogger, graphqlApi)
+
+	// Each field checks the permission of its operation, all of which require reading projects
+	query := b.requirePermission(graphqlHandler.Query, auth.PermissionProjectRead)
+	query = b.mc.Auth.RequireTwoFactorEnrollmentMiddleware(query)
+	query = b.mc.Auth.AuthenticateRequestMiddleware(query)
+
+	b.router.Handle("/api/graphql", query).Methods(http.MethodPost)
+}
+
+func (b *routerBuilder) buildWellKnown() {
+	keysLogger := ilog.MakeLoggerWithTag("KeysHandler")
+	keysHandler := handler.NewKeysHandler(keysLogger, b.sc.signingKeyService)
+
+	b.router.HandleFunc("/.well-known/jwks.json", keysHandler.GetJWKS).Methods(http.MethodGet)
 }
 
+// buildSwagger serves the document of each version at /swagger/<version>.yaml, documented at
+// /docs/<version>; /swagger.yaml and /docs serve the latest version
 func (b *routerBuilder) buildSwagger() {
-	opts := redoc.RedocOpts{SpecURL: "/swagger.yaml"}
-	sh := redoc.Redoc(opts, nil)
-	b.router.Handle("/swagger.yaml", http.FileServer(http.Dir("./")))
-	b.router.Handle("/docs", sh)
+	documents := http.StripPrefix("/swagger/", http.FileServer(http.Dir("./swagger")))
+	b.router.PathPrefix("/swagger/").Handler(documents).Methods(http.MethodGet)
+
+	latest := b.versions[0]
+	for _, version := range b.versions {
+		if version.Deprecated {
+			continue
+		}
+
+		if version.Number > latest.Number {
+			latest = version
+		}
+
+		opts := redoc.RedocOpts{Path: "/docs/" + version.Name(), SpecURL: "/swagger/" + version.Name() + ".yaml"}
+		b.router.Handle(opts.Path, redoc.Redoc(opts, nil)).Methods(http.MethodGet)
+	}
+
+	b.router.Handle("/swagger.yaml", http.RedirectHandler("/swagger/"+latest.Name()+".yaml", http.StatusFound))
+	b.router.Handle("/docs", http.RedirectHandler("/docs/"+latest.Name(), http.StatusFound))
 }
 
 type HttpHandlerFunc = func(w http.ResponseWriter, r *http.Request)
 
-func (b *routerBuilder) Get(path string, f HttpHandlerFunc) {
-	b.ar.HandleFunc(path, f).Methods(http.MethodGet)
+// Get, Post, Put, Patch and Delete register the authenticated route in the version being built. Every
+// version serves the same handlers for now; a route differing between versions would be registered by
+// checking b.version, as the routes are built once for each version.
+
+func (b *routerBuilder) Get(path string, f HttpHandlerFunc, permission auth.Permission) *mux.Route {
+	return b.ar.Handle(path, b.requirePermission(f, permission)).Methods(http.MethodGet)
+}
+
+func (b *routerBuilder) Post(path string, f HttpHandlerFunc, permission auth.Permission) *mux.Route {
+	return b.ar.Handle(path, b.requirePermission(f, permission)).Methods(http.MethodPost)
+}
+
+func (b *routerBuilder) Put(path string, f HttpHandlerFunc, permission auth.Permission) *mux.Route {
+	return b.ar.Handle(path, b.requirePermission(f, permission)).Methods(http.MethodPut)
+}
+
+func (b *routerBuilder) Patch(path string, f HttpHandlerFunc, permission auth.Permission) *mux.Route {
+	return b.ar.Handle(path, b.requirePermission(f, permission)).Methods(http.MethodPatch)
 }
 
-func (b *routerBuilder) Post(path string, f HttpHandlerFunc) {
-	b.ar.HandleFunc(path, f).Methods(http.MethodPost)
+func (b *routerBuilder) Delete(path string, f HttpHandlerFunc, permission auth.Permission) *mux.Route {
+	return b.ar.Handle(path, b.requirePermission(f, permission)).Methods(http.MethodDelete)
 }
 
-func (b *routerBuilder) Put(path string, f HttpHandlerFunc) {
-	b.ar.HandleFunc(path, f).Methods(http.MethodPut)
+// name names the route within the version being built, such that responses may link to it; see
+// api.Routes
+func (b *routerBuilder) name(name string, route *mux.Route) {
+	route.Name(b.version.RouteName(name))
 }
 
-func (b *routerBuilder) Delete(path string, f HttpHandlerFunc) {
-	b.ar.HandleFunc(path, f).Methods(http.MethodDelete)
+func (b *routerBuilder) requirePermission(f HttpHandlerFunc, permission auth.Permission) http.Handler {
+	return b.mc.Auth.RequirePermission(permission)(http.HandlerFunc(f))
 }
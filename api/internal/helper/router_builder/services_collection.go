@@ -1,8 +1,10 @@
 package router_builder
 
 import (
+	"godo/configuration"
 	"godo/internal/api/services"
 	"godo/internal/helper/ilog"
+	"godo/internal/mail"
 	"godo/internal/repository"
 )
 
@@ -13,14 +15,23 @@ type ServiceCollection struct {
 	storyService   services.StoryService
 	tagService     services.TagService
 	taskService    services.TaskService
+	teamService    services.TeamService
 	userService    services.UserService
+
+	twoFactorService  services.TwoFactorService
+	signingKeyService services.SigningKeyService
+	invitationService services.InvitationService
+
+	personalDataService services.PersonalDataService
 }
 
-func newServiceCollection(dao repository.DAO, JWTKey string) ServiceCollection {
+func newServiceCollection(dao repository.DAO, config configuration.Config) ServiceCollection {
 	authServiceLogger := ilog.MakeLoggerWithTag("AuthService")
 	accountServiceLogger := ilog.MakeLoggerWithTag("AccountService")
 	accountQueryLogger := ilog.MakeLoggerWithTag("AccountQuery")
+	accountMemberQueryLogger := ilog.MakeLoggerWithTag("AccountMemberQuery")
 	projectQueryLogger := ilog.MakeLoggerWithTag("ProjectRepo")
+	projectMemberQueryLogger := ilog.MakeLoggerWithTag("ProjectMemberRepo")
 	projectServiceLogger := ilog.MakeLoggerWithTag("ProjectService")
 	storyQueryLogger := ilog.MakeLoggerWithTag("StoryRepo")
 	storyServiceLogger := ilog.MakeLoggerWithTag("StoryService")
@@ -28,25 +39,82 @@ func newServiceCollection(dao repository.DAO, JWTKey string) ServiceCollection {
 	tagServiceLogger := ilog.MakeLoggerWithTag("TagService")
 	taskQueryLogger := ilog.MakeLoggerWithTag("TaskQuery")
 	taskServiceLogger := ilog.MakeLoggerWithTag("TaskService")
+	teamQueryLogger := ilog.MakeLoggerWithTag("TeamQuery")
+	teamServiceLogger := ilog.MakeLoggerWithTag("TeamService")
 	userQueryLogger := ilog.MakeLoggerWithTag("UserQuery")
 	userServiceLogger := ilog.MakeLoggerWithTag("UserService")
+	recoveryCodeQueryLogger := ilog.MakeLoggerWithTag("RecoveryCodeQuery")
+	twoFactorServiceLogger := ilog.MakeLoggerWithTag("TwoFactorService")
+	signingKeyQueryLogger := ilog.MakeLoggerWithTag("SigningKeyQuery")
+	signingKeyServiceLogger := ilog.MakeLoggerWithTag("SigningKeyService")
+	invitationQueryLogger := ilog.MakeLoggerWithTag("InvitationQuery")
+	invitationServiceLogger := ilog.MakeLoggerWithTag("InvitationService")
+	personalDataQueryLogger := ilog.MakeLoggerWithTag("PersonalDataQuery")
+	personalDataServiceLogger := ilog.MakeLoggerWithTag("PersonalDataService")
+	mailerLogger := ilog.MakeLoggerWithTag("Mailer")
+
+	mailer := mail.NewMailer(mail.Config{
+		Host:     config.SMTPHost,
+		Port:     config.SMTPPort,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.MailFrom,
+	}, mailerLogger)
 
 	// Initialize the repositories
 	accountQuery := dao.NewAccountQuery(accountQueryLogger)
+	accountMemberQuery := dao.NewAccountMemberQuery(accountMemberQueryLogger)
 	projectQuery := dao.NewProjectQuery(projectQueryLogger)
+	projectMemberQuery := dao.NewProjectMemberQuery(projectMemberQueryLogger)
 	storyQuery := dao.NewStoryQuery(storyQueryLogger)
 	tagQuery := dao.NewTagQuery(tagQueryLogger)
 	taskQuery := dao.NewTaskQuery(taskQueryLogger)
+	teamQuery := dao.NewTeamQuery(teamQueryLogger)
 	userQuery := dao.NewApiUserQuery(userQueryLogger)
+	recoveryCodeQuery := dao.NewRecoveryCodeQuery(recoveryCodeQueryLogger)
+	signingKeyQuery := dao.NewSigningKeyQuery(signingKeyQueryLogger)
+	invitationQuery := dao.NewInvitationQuery(invitationQueryLogger)
+	personalDataQuery := dao.NewPersonalDataQuery(personalDataQueryLogger)
 
 	// Initialize the services
-	authService := services.NewAuthService(userQuery, []byte(JWTKey), authServiceLogger)
-	accountService := services.NewAccountService(accountQuery, accountServiceLogger)
-	projectService := services.NewProjectService(projectQuery, projectServiceLogger)
-	storyService := services.NewStoryService(storyQuery, storyServiceLogger)
+	signingKeyService := services.NewSigningKeyService(
+		signingKeyQuery,
+		config.JWTAlgorithm,
+		config.JWTKeyGracePeriod,
+		[]byte(config.JWTKey),
+		signingKeyServiceLogger,
+	)
+	authService := services.NewAuthService(userQuery, signingKeyService, authServiceLogger)
+	invitationService := services.NewInvitationService(
+		invitationQuery,
+		userQuery,
+		accountQuery,
+		mailer,
+		config.WebUIURL,
+		invitationServiceLogger,
+	)
+	accountService := services.NewAccountService(accountQuery, config.AccountDeletionGracePeriod, accountServiceLogger)
+	projectService := services.NewProjectService(
+		projectQuery,
+		projectMemberQuery,
+		userQuery,
+		teamQuery,
+		projectServiceLogger,
+	)
+	storyService := services.NewStoryService(storyQuery, projectQuery, projectMemberQuery, storyServiceLogger)
 	tagService := services.NewTagService(tagQuery, tagServiceLogger)
-	taskService := services.NewTaskService(taskQuery, taskServiceLogger)
-	userService := services.NewUserService(userQuery, userServiceLogger)
+	taskService := services.NewTaskService(
+		taskQuery,
+		storyQuery,
+		projectQuery,
+		projectMemberQuery,
+		userQuery,
+		taskServiceLogger,
+	)
+	teamService := services.NewTeamService(teamQuery, userQuery, teamServiceLogger)
+	userService := services.NewUserService(userQuery, accountMemberQuery, userServiceLogger)
+	twoFactorService := services.NewTwoFactorService(userQuery, recoveryCodeQuery, twoFactorServiceLogger)
+	personalDataService := services.NewPersonalDataService(personalDataQuery, personalDataServiceLogger)
 
 	return ServiceCollection{
 		authService,
@@ -55,6 +123,11 @@ func newServiceCollection(dao repository.DAO, JWTKey string) ServiceCollection {
 		storyService,
 		tagService,
 		taskService,
+		teamService,
 		userService,
+		twoFactorService,
+		signingKeyService,
+		invitationService,
+		personalDataService,
 	}
 }
@@ -0,0 +1,65 @@
+package validate
+
+import (
+	"reflect"
+
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator"
+)
+
+// The messages describing each failed rule, keyed by the rule and, where the message differs
+// for strings and lists, the kind of field. {0} is the field's name and {1} the rule's parameter.
+var englishMessages = map[string]string{
+	"required":     "{0} is required",
+	"min":          "{0} must be at least {1}",
+	"min.string":   "{0} must be at least {1} characters long",
+	"min.items":    "{0} must contain at least {1} items",
+	"max":          "{0} must be at most {1}",
+	"max.string":   "{0} must be at most {1} characters long",
+	"max.items":    "{0} must contain at most {1} items",
+	"len":          "{0} must be {1}",
+	"len.string":   "{0} must be {1} characters long",
+	"len.items":    "{0} must contain {1} items",
+	"gte":          "{0} must be {1} or greater",
+	"lte":          "{0} must be {1} or less",
+	"oneof":        "{0} must be one of {1}",
+	"email":        "{0} must be a valid email address",
+	"uuid":         "{0} must be a valid UUID",
+	"excludes":     "{0} must not contain '{1}'",
+	"enum":         "{0} is not one of the allowed values",
+	"unknown.rule": "{0} failed the {1} rule",
+}
+
+func newTranslator() ut.Translator {
+	english := en.New()
+	trans, _ := ut.New(english, english).GetTranslator(english.Locale())
+
+	for key, message := range englishMessages {
+		if err := trans.Add(key, message, false); err != nil {
+			panic(err)
+		}
+	}
+
+	return trans
+}
+
+// Message describes why the field failed validation
+func Message(fe validator.FieldError) string {
+	keys := []string{fe.Tag()}
+	switch fe.Kind() {
+	case reflect.String:
+		keys = []string{fe.Tag() + ".string", fe.Tag()}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		keys = []string{fe.Tag() + ".items", fe.Tag()}
+	}
+
+	for _, key := range keys {
+		if message, err := translator.T(key, fe.Field(), fe.Param()); err == nil {
+			return message
+		}
+	}
+
+	message, _ := translator.T("unknown.rule", fe.Field(), fe.Tag())
+	return message
+}
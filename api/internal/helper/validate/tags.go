@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The struct tag keys in use; any other key is most likely a misspelling, such as validation for validate
+var knownTagKeys = map[string]bool{
+	"json":     true,
+	"validate": true,
+	"gorm":     true,
+	"sql":      true,
+}
+
+// The models validated by the API, registered by the packages defining them
+var (
+	modelsMu sync.Mutex
+	models   []any
+)
+
+// Register adds models validated by the API to those CheckTags checks; each package registers the models
+// it defines in an init function, such that they are checked at startup
+func Register(validated ...any) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+
+	models = append(models, validated...)
+}
+
+// CheckTags reports the struct tags of the registered models which would not do what they appear to: tags
+// with an unknown key, which are silently ignored, validation rules which don't exist, which panic when
+// validated, and the enum rule on fields which aren't enumerations. It is run at startup so mistakes fail
+// fast.
+func CheckTags() error {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+
+	var problems []string
+	for _, model := range models {
+		problems = append(problems, typeProblems(reflect.TypeOf(model))...)
+	}
+
+	return problemsError(problems)
+}
+
+// typeProblems the problems with the struct tags of the type
+func typeProblems(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	problems := checkFields(t, t.Name())
+	if problem := checkRules(t); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+func problemsError(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid struct tags: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func checkFields(t reflect.Type, path string) []string {
+	var problems []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := path + "." + field.Name
+
+		for _, key := range tagKeys(field.Tag) {
+			if !knownTagKeys[key] {
+				problems = append(problems, fmt.Sprintf("%s has an unknown %q tag", name, key))
+			}
+		}
+
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		for _, rule := range rules {
+			if rule == "enum" && !field.Type.Implements(reflect.TypeOf((*enum)(nil)).Elem()) {
+				problems = append(problems, fmt.Sprintf("%s uses the enum rule but is not an enumeration", name))
+			}
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			problems = append(problems, checkFields(field.Type, path)...)
+		}
+	}
+
+	return problems
+}
+
+// checkRules validates an empty model; the validator panics if a rule doesn't exist
+func checkRules(t reflect.Type) (problem string) {
+	defer func() {
+		if r := recover(); r != nil {
+			problem = fmt.Sprintf("%s: %v", t.Name(), r)
+		}
+	}()
+
+	_ = validate.Struct(reflect.New(t).Interface())
+	return ""
+}
+
+// tagKeys returns the keys of the struct tag, following the conventional key:"value" format
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	s := string(tag)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		i := strings.Index(s, ":")
+		if i <= 0 {
+			break
+		}
+
+		keys = append(keys, s[:i])
+
+		value, err := strconv.QuotedPrefix(s[i+1:])
+		if err != nil {
+			break
+		}
+
+		s = s[i+1+len(value):]
+	}
+
+	return keys
+}
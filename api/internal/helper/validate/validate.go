@@ -1,10 +1,73 @@
 package validate
 
 import (
+	"reflect"
+	"strings"
+	"sync"
+
 	"github.com/go-playground/validator"
 )
 
+// enum a value of an enumeration which knows its own range, such as enums.TaskType
+type enum interface {
+	IsValid() bool
+}
+
+var (
+	validate   = newValidator()
+	translator = newTranslator()
+
+	// The problems with the tags of each type validated, checked before it is first validated
+	checked sync.Map
+)
+
+// Struct validates the struct. The tags of a type are checked before it is first validated, such that a
+// model which was not registered fails with its problems rather than ignoring a tag or panicking.
 func Struct(s any) error {
+	if err := checkType(reflect.TypeOf(s)); err != nil {
+		return err
+	}
+
+	return validate.Struct(s)
+}
+
+// StructExcept validates the struct but for the named fields, such as those not known until it is saved
+func StructExcept(s any, fields ...string) error {
+	if err := checkType(reflect.TypeOf(s)); err != nil {
+		return err
+	}
+
+	return validate.StructExcept(s, fields...)
+}
+
+func checkType(t reflect.Type) error {
+	problems, ok := checked.Load(t)
+	if !ok {
+		problems, _ = checked.LoadOrStore(t, typeProblems(t))
+	}
+
+	return problemsError(problems.([]string))
+}
+
+func newValidator() *validator.Validate {
 	v := validator.New()
-	return v.Struct(s)
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	// enum checks that the value is within the range of its enumeration
+	_ = v.RegisterValidation("enum", func(fl validator.FieldLevel) bool {
+		e, ok := fl.Field().Interface().(enum)
+		return ok && e.IsValid()
+	})
+
+	return v
+}
+
+// jsonFieldName names fields in validation errors as they are named in request bodies
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" || name == "" {
+		return field.Name
+	}
+
+	return name
 }
@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"fmt"
+	"godo/internal/helper/ilog"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewMailer returns a mailer sending through the configured SMTP server.
+// Without an SMTP host, emails are written to the log instead, which is convenient in development.
+func NewMailer(config Config, logger ilog.StdLogger) Mailer {
+	if config.Host == "" {
+		logger.Warn("No SMTP host is configured, emails will be logged rather than sent")
+		return &logMailer{log: logger}
+	}
+
+	return &smtpMailer{config: config, log: logger}
+}
+
+type smtpMailer struct {
+	config Config
+	log    ilog.StdLogger
+}
+
+func (m *smtpMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.config.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	addr := fmt.Sprintf("%s:%s", m.config.Host, m.config.Port)
+	err := smtp.SendMail(addr, auth, m.config.From, []string{to}, []byte(msg))
+	if err != nil {
+		m.log.Errorf("Could not send email to %s: %s", to, err)
+	}
+
+	return err
+}
+
+type logMailer struct {
+	log ilog.StdLogger
+}
+
+func (m *logMailer) Send(to, subject, body string) error {
+	m.log.Infof("Email to %s\nSubject: %s\n\n%s", to, subject, body)
+	return nil
+}
@@ -1,16 +1,23 @@
 package repository
 
 import (
+	"database/sql"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"strings"
+	"time"
 )
 
 type AccountQuery interface {
 	GetAllAccounts() ([]*entities.Account, error)
 	GetAccountById(accountId string) (*entities.Account, error)
-	CreateAccount(newAccount *entities.Account) (*entities.Account, error)
 	AccountExists(accountId string) (bool, error)
 	AccountWithEmailAddressExists(email string) (bool, error)
+	UpdateAccount(account *entities.Account) error
+	GetAccountsDueForDeletion(now time.Time) ([]*entities.Account, error)
+	PurgeAccount(accountId string, now time.Time) (bool, error)
+	PromoteFirstMembersToOwner() (int64, error)
 }
 
 type accountQuery struct {
@@ -41,18 +48,6 @@ func (q *accountQuery) GetAccountById(accountId string) (*entities.Account, erro
 	return &account, err
 }
 
-func (q *accountQuery) CreateAccount(newAccount *entities.Account) (*entities.Account, error) {
-	q.log.Debugf("Creating Account with name of %s and email of %s", newAccount.Name)
-
-	err := Database.Create(&newAccount).Error
-	if err != nil {
-		q.log.Error(err)
-		return nil, err
-	}
-
-	return newAccount, err
-}
-
 func (q *accountQuery) AccountExists(accountId string) (bool, error) {
 	var count int64
 	r := Database.Model(&entities.Account{}).
@@ -74,3 +69,134 @@ func (q *accountQuery) AccountWithEmailAddressExists(email string) (bool, error)
 
 	return count >= 1, r.Error
 }
+
+func (q *accountQuery) UpdateAccount(account *entities.Account) error {
+	q.log.Debugf("Updating Account{id=%s}", account.ID)
+
+	err := Database.Save(account).Error
+	ilog.ErrorlnIf(err, q.log)
+
+	return err
+}
+
+func (q *accountQuery) GetAccountsDueForDeletion(now time.Time) ([]*entities.Account, error) {
+	q.log.Debug("Fetching the accounts due for deletion")
+
+	var accounts []*entities.Account
+	err := Database.
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Find(&accounts).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return accounts, err
+}
+
+// PurgeAccount permanently erases the account along with every project, story, task and tag in it,
+// and every user who is not a member of another account, returning whether it was erased. The account is
+// locked and its deletion checked to still be due, such that one whose deletion was cancelled after it was
+// found to be due is kept.
+func (q *accountQuery) PurgeAccount(accountId string, now time.Time) (bool, error) {
+	q.log.Infof("Purging Account{id=%s}", accountId)
+
+	const (
+		projects = "SELECT id FROM projects WHERE account_id = ?"
+		stories  = "SELECT id FROM stories WHERE project_id IN (" + projects + ")"
+		tasks    = "SELECT id FROM tasks WHERE story_id IN (" + stories + ")"
+
+		// Users who are members of other accounts are kept
+		users = "SELECT user_id FROM account_members WHERE account_id = ? AND user_id NOT IN " +
+			"(SELECT user_id FROM account_members WHERE account_id <> ?)"
+
+		// Locks the account, if its deletion is still due
+		lockDueAccount = "SELECT 1 FROM accounts " +
+			"WHERE id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? FOR UPDATE"
+	)
+
+	// Ordered so that rows are removed before the rows they reference
+	statements := []string{
+		"DELETE FROM task_tags WHERE task_id IN (" + tasks + ")",
+		"DELETE FROM tasks WHERE story_id IN (" + stories + ")",
+		"DELETE FROM tags WHERE project_id IN (" + projects + ")",
+		"DELETE FROM stories WHERE project_id IN (" + projects + ")",
+		"DELETE FROM project_members WHERE project_id IN (" + projects + ")",
+		"DELETE FROM team_members WHERE team_id IN (SELECT id FROM teams WHERE account_id = ?)",
+		"DELETE FROM teams WHERE account_id = ?",
+		"DELETE FROM projects WHERE account_id = ?",
+		"DELETE FROM invitations WHERE account_id = ?",
+		"DELETE FROM recovery_codes WHERE user_id IN (" + users + ")",
+		"DELETE FROM users WHERE id IN (" + users + ")",
+		"DELETE FROM account_members WHERE account_id = ?",
+		"UPDATE users SET default_account_id = (SELECT account_id FROM account_members " +
+			"WHERE user_id = users.id ORDER BY created_at LIMIT 1) WHERE default_account_id = ?",
+		"DELETE FROM accounts WHERE id = ?",
+	}
+
+	tx := Database.Begin()
+
+	var due int
+	err := tx.
+		Raw(lockDueAccount, accountId, now).
+		Row().
+		Scan(&due)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		q.log.Infof("The deletion of Account{id=%s} is no longer due, keeping it", accountId)
+		return false, nil
+	}
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Errorf("Could not lock Account{id=%s}: %s", accountId, err)
+		return false, err
+	}
+
+	for _, statement := range statements {
+		args := make([]interface{}, strings.Count(statement, "?"))
+		for i := range args {
+			args[i] = accountId
+		}
+
+		if err := tx.Exec(statement, args...).Error; err != nil {
+			tx.Rollback()
+			q.log.Errorf("Could not purge Account{id=%s}: %s", accountId, err)
+			return false, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		q.log.Errorf("Could not purge Account{id=%s}: %s", accountId, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PromoteFirstMembersToOwner makes the earliest user of every account without an active owner its owner,
+// as the accounts created before account roles were added have none, returning the number promoted
+func (q *accountQuery) PromoteFirstMembersToOwner() (int64, error) {
+	q.log.Info("Promoting the earliest user of every account without an owner to owner")
+
+	const (
+		// The earliest active user of each account
+		firstMembers = "SELECT DISTINCT ON (m.account_id) m.account_id, m.user_id " +
+			"FROM account_members m JOIN users ON users.id = m.user_id " +
+			"WHERE m.deactivated_at IS NULL " +
+			"ORDER BY m.account_id, users.created_at, users.id"
+
+		owners = "SELECT 1 FROM account_members o WHERE o.account_id = account_members.account_id " +
+			"AND o.role = ? AND o.deactivated_at IS NULL"
+	)
+
+	r := Database.Exec(
+		"UPDATE account_members SET role = ? FROM ("+firstMembers+") f "+
+			"WHERE account_members.account_id = f.account_id AND account_members.user_id = f.user_id "+
+			"AND NOT EXISTS ("+owners+")",
+		enums.Owner,
+		enums.Owner,
+	)
+
+	ilog.ErrorlnIf(r.Error, q.log)
+	return r.RowsAffected, r.Error
+}
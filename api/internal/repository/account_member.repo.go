@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+)
+
+type AccountMemberQuery interface {
+	GetMember(accountId string, userId uint) (*entities.AccountMember, error)
+	GetMemberships(userId uint) (entities.AccountMemberList, error)
+	AddMember(accountId string, userId uint, role enums.AccountRole) (*entities.AccountMember, error)
+}
+
+type accountMemberQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewAccountMemberQuery(logger ilog.StdLogger) AccountMemberQuery {
+	return &accountMemberQuery{log: logger}
+}
+
+func (q *accountMemberQuery) GetMember(accountId string, userId uint) (*entities.AccountMember, error) {
+	q.log.Debugf("Fetching the membership of User{id=%d} in Account{id=%s}", userId, accountId)
+
+	var member entities.AccountMember
+	err := Database.First(&member, "account_id = ? AND user_id = ?", accountId, userId).Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return &member, err
+}
+
+// GetMemberships returns every account the user is a member of, oldest membership first
+func (q *accountMemberQuery) GetMemberships(userId uint) (entities.AccountMemberList, error) {
+	q.log.Debugf("Fetching the memberships of User{id=%d}", userId)
+
+	var members entities.AccountMemberList
+	err := Database.
+		Preload("Account").
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&members).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return members, err
+}
+
+func (q *accountMemberQuery) AddMember(accountId string, userId uint, role enums.AccountRole) (*entities.AccountMember, error) {
+	q.log.Debugf("Adding User{id=%d} to Account{id=%s} as %s", userId, accountId, role)
+
+	member := entities.AccountMember{AccountId: accountId, UserId: userId, Role: role}
+	err := Database.Create(&member).Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return &member, err
+}
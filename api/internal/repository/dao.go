@@ -8,11 +8,18 @@ import (
 
 type DAO interface {
 	NewAccountQuery(logger ilog.StdLogger) AccountQuery
+	NewAccountMemberQuery(logger ilog.StdLogger) AccountMemberQuery
 	NewApiUserQuery(logger ilog.StdLogger) ApiUserQuery
 	NewStoryQuery(logger ilog.StdLogger) StoryQuery
 	NewProjectQuery(logger ilog.StdLogger) ProjectQuery
+	NewProjectMemberQuery(logger ilog.StdLogger) ProjectMemberQuery
 	NewTaskQuery(logger ilog.StdLogger) TaskQuery
 	NewTagQuery(logger ilog.StdLogger) TagQuery
+	NewRecoveryCodeQuery(logger ilog.StdLogger) RecoveryCodeQuery
+	NewSigningKeyQuery(logger ilog.StdLogger) SigningKeyQuery
+	NewInvitationQuery(logger ilog.StdLogger) InvitationQuery
+	NewTeamQuery(logger ilog.StdLogger) TeamQuery
+	NewPersonalDataQuery(logger ilog.StdLogger) PersonalDataQuery
 }
 
 type dao struct {
@@ -25,3 +32,8 @@ func NewDAO(logger ilog.StdLogger) DAO {
 	Database = GetDatabase(logger)
 	return &dao{log: logger}
 }
+
+// NewDetachedDAO a DAO not connected to the database, for building the routers without one; its queries fail
+func NewDetachedDAO(logger ilog.StdLogger) DAO {
+	return &dao{log: logger}
+}
@@ -5,6 +5,7 @@ import (
 	"godo/configuration"
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 
 	"github.com/jinzhu/gorm"
 )
@@ -52,6 +53,13 @@ func dropAllTables(db *gorm.DB) {
 	db.DropTableIfExists(&entities.Task{})
 	db.DropTableIfExists(&entities.Tag{})
 	db.DropTableIfExists("task_tags")
+	db.DropTableIfExists(&entities.RecoveryCode{})
+	db.DropTableIfExists(&entities.SigningKey{})
+	db.DropTableIfExists(&entities.Invitation{})
+	db.DropTableIfExists(&entities.ProjectMember{})
+	db.DropTableIfExists(&entities.AccountMember{})
+	db.DropTableIfExists(&entities.Team{})
+	db.DropTableIfExists(&entities.TeamMember{})
 }
 
 func migrate(db *gorm.DB) {
@@ -61,16 +69,26 @@ func migrate(db *gorm.DB) {
 	db.AutoMigrate(&entities.Story{})
 	db.AutoMigrate(&entities.Task{})
 	db.AutoMigrate(&entities.Tag{})
+	db.AutoMigrate(&entities.RecoveryCode{})
+	db.AutoMigrate(&entities.SigningKey{})
+	db.AutoMigrate(&entities.Invitation{})
+	db.AutoMigrate(&entities.ProjectMember{})
+	db.AutoMigrate(&entities.AccountMember{})
+	db.AutoMigrate(&entities.Team{})
+	db.AutoMigrate(&entities.TeamMember{})
 }
 
 func populateTestData(db *gorm.DB) {
 	db.Create(&account)
 
-	user.AccountId = account.ID
+	user.DefaultAccountId = account.ID
 	db.Create(&user)
+	db.Create(&entities.AccountMember{AccountId: account.ID, UserId: user.ID, Role: enums.Owner})
 
 	project.Creator = user
+	project.AccountId = account.ID
 	db.Create(&project)
+	db.Create(&entities.ProjectMember{ProjectId: project.ID, UserId: user.ID, Role: enums.Maintainer})
 
 	story1.Creator = user
 	story1.Project = project
@@ -1,5 +1,7 @@
 package entities
 
+import "time"
+
 //
 type Account struct {
 	Base
@@ -7,6 +9,17 @@ type Account struct {
 	Name  string `json:"name" validate:"required" gorm:"not null"`
 	Email string `json:"email" validate:"required" gorm:"not null"`
 
+	// RequireTwoFactor when set, every user in the account must enroll in two-factor authentication
+	RequireTwoFactor bool `json:"require_two_factor" gorm:"not null;default:false"`
+
+	// TimeZone the IANA time zone used by default for the account's users
+	TimeZone string `json:"time_zone" gorm:"not null;default:'UTC'"`
+
+	// DeletionScheduledAt when set, the account and everything in it is erased at this time
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+
+	Links *Links `json:"_links,omitempty" gorm:"-:all"`
+
 	TimestampBase
 }
 
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"github.com/jinzhu/gorm"
+	"godo/internal/repository/enums"
+	"time"
+)
+
+// AccountMember grants a user a role within an account.
+// A user may be a member of several accounts, acting in one of them at a time.
+type AccountMember struct {
+	AccountId string            `json:"account_id" gorm:"primary_key"`
+	Account   Account           `json:"account" gorm:"foreignKey:AccountId"`
+	UserId    uint              `json:"-" gorm:"primary_key;auto_increment:false"`
+	User      User              `json:"-" gorm:"foreignKey:UserId"`
+	Role      enums.AccountRole `json:"-" gorm:"type:smallint;default:0;not null"`
+	RoleValue string            `json:"role" gorm:"-:all"`
+	CreatedAt time.Time         `json:"created_at"`
+
+	// DeactivatedAt is set once the user has left the account; they can no longer act within it
+	// but remain the creator of their work
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+
+	// Default whether the account is the one the user logs in to when none is chosen
+	Default bool `json:"default" gorm:"-:all"`
+}
+
+type AccountMemberList []*AccountMember
+
+func (m *AccountMember) AfterFind(tx *gorm.DB) {
+	m.RoleValue = m.Role.String()
+}
+
+func (m *AccountMember) AfterCreate(tx *gorm.DB) {
+	m.RoleValue = m.Role.String()
+}
+
+func (m *AccountMember) AfterSave(tx *gorm.DB) {
+	m.RoleValue = m.Role.String()
+}
+
+// AccountMemberResponse the authenticated user's membership of an account
+// swagger:response accountMemberResponse
+type AccountMemberResponse struct {
+	// The resultant AccountMember
+	// in: body
+	Body AccountMember
+}
+
+// AccountMemberListResponse the accounts the authenticated user is a member of
+// swagger:response accountMemberListResponse
+type AccountMemberListResponse struct {
+	// All accounts the user is a member of
+	// in: body
+	Body AccountMemberList
+}
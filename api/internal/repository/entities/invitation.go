@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"github.com/jinzhu/gorm"
+	"godo/internal/repository/enums"
+	"time"
+)
+
+// Invitation statuses, derived from the timestamps on the invitation
+const (
+	InvitationPending  = "Pending"
+	InvitationAccepted = "Accepted"
+	InvitationRevoked  = "Revoked"
+	InvitationExpired  = "Expired"
+)
+
+// Invitation an invitation for the owner of an email address to join an account with the given role.
+// Only the hash of the single-use token sent to the invitee is stored.
+type Invitation struct {
+	Base
+
+	AccountId   string            `json:"-" gorm:"index;not null"`
+	Email       string            `json:"email" gorm:"not null" validate:"required,email"`
+	Role        enums.AccountRole `json:"-" gorm:"type:smallint;default:0;not null"`
+	RoleValue   string            `json:"role" gorm:"-:all"`
+	TokenHash   string            `json:"-" gorm:"unique_index;not null"`
+	InviterId   uint              `json:"-"`
+	Inviter     User              `json:"inviter" gorm:"foreignKey:InviterId"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	AcceptedAt  *time.Time        `json:"accepted_at"`
+	RevokedAt   *time.Time        `json:"revoked_at"`
+	StatusValue string            `json:"status" gorm:"-:all"`
+
+	TimestampBase
+}
+
+type InvitationList []*Invitation
+
+func (i *Invitation) Status() string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case time.Now().After(i.ExpiresAt):
+		return InvitationExpired
+	}
+
+	return InvitationPending
+}
+
+func (i *Invitation) IsPending() bool {
+	return i.Status() == InvitationPending
+}
+
+func (i *Invitation) AfterFind(tx *gorm.DB) {
+	i.RoleValue = i.Role.String()
+	i.StatusValue = i.Status()
+}
+
+func (i *Invitation) AfterCreate(tx *gorm.DB) {
+	i.RoleValue = i.Role.String()
+	i.StatusValue = i.Status()
+}
+
+func (i *Invitation) AfterSave(tx *gorm.DB) {
+	i.RoleValue = i.Role.String()
+	i.StatusValue = i.Status()
+}
+
+// InvitationResponse the specified Invitation
+// swagger:response invitationResponse
+type InvitationResponse struct {
+	// The resultant Invitation
+	// in: body
+	Body Invitation
+}
+
+// InvitationListResponse a list of the invitations made for the authenticated account
+// swagger:response invitationListResponse
+type InvitationListResponse struct {
+	// All invitations for the account
+	// in: body
+	Body InvitationList
+}
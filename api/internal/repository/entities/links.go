@@ -0,0 +1,24 @@
+package entities
+
+// Links the hypermedia links of a resource, keyed by their relation to it
+// swagger:model Links
+type Links struct {
+	// The resource itself
+	Self *Link `json:"self,omitempty"`
+
+	// The resource the resource belongs to, such as the project of a story
+	Parent *Link `json:"parent,omitempty"`
+
+	// The resources belonging to the resource, such as the stories of a project
+	Children []Link `json:"children,omitempty"`
+
+	// The collection listing the resource
+	Collection *Link `json:"collection,omitempty"`
+}
+
+// Link a link to a resource of the API
+// swagger:model Link
+type Link struct {
+	// The path of the resource, within the version of the API serving the request
+	Href string `json:"href"`
+}
@@ -0,0 +1,20 @@
+package entities
+
+import "godo/internal/helper/validate"
+
+// The entities with validation rules, validated directly or as part of the models embedding them, are
+// checked for misspelt struct tags and unknown validation rules when the API starts
+func init() {
+	validate.Register(
+		Account{},
+		Invitation{},
+		Project{},
+		ProjectInfo{},
+		Story{},
+		StoryInfo{},
+		Tag{},
+		Task{},
+		Team{},
+		User{},
+	)
+}
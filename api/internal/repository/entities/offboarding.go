@@ -0,0 +1,21 @@
+package entities
+
+// Offboarding a summary of the work handed over when a user was offboarded
+type Offboarding struct {
+	UserId      uint `json:"user_id"`
+	SuccessorId uint `json:"successor_id"`
+
+	// TasksReassigned the number of open tasks now assigned to the successor
+	TasksReassigned int64 `json:"tasks_reassigned"`
+
+	// ProjectsTransferred the number of project memberships handed to the successor
+	ProjectsTransferred int64 `json:"projects_transferred"`
+}
+
+// OffboardingResponse a summary of the offboarded user's reassigned work
+// swagger:response offboardingResponse
+type OffboardingResponse struct {
+	// The resultant Offboarding
+	// in: body
+	Body Offboarding
+}
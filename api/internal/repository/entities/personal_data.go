@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// PersonalData everything stored about a user, exported in answer to a data-subject access request
+type PersonalData struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Profile    User      `json:"profile"`
+
+	// The accounts, projects and teams the user is a member of
+	Accounts           AccountMemberList `json:"accounts"`
+	ProjectMemberships ProjectMemberList `json:"project_memberships"`
+	Teams              TeamList          `json:"teams"`
+
+	// The work the user created, along with the tasks assigned to them
+	Projects ProjectList `json:"projects_created"`
+	Stories  []*Story    `json:"stories_created"`
+	Tasks    TaskList    `json:"tasks"`
+
+	// The invitations the user sent, and those sent to their email address
+	InvitationsSent     InvitationList `json:"invitations_sent"`
+	InvitationsReceived InvitationList `json:"invitations_received"`
+
+	// The user's two-factor recovery codes; only when they were created and used is exported
+	RecoveryCodes []*RecoveryCode `json:"recovery_codes"`
+}
+
+// PersonalDataResponse an archive of everything stored about the authenticated user
+// swagger:response personalDataResponse
+type PersonalDataResponse struct {
+	// The resultant PersonalData
+	// in: body
+	Body PersonalData
+}
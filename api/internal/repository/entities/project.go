@@ -14,10 +14,15 @@ type Project struct {
 	Description string              `json:"description" validate:"max=280"`
 	Status      enums.ProjectStatus `json:"-" gorm:"type:smallint;default:0;not null" validate:"gte=0"`
 	StatusValue string              `json:"status" gorm:"-:all"`
+	AccountId   string              `json:"-" gorm:"index"`
+	Restricted  bool                `json:"restricted" gorm:"default:false;not null"`
+	TeamId      *string             `json:"team_id" gorm:"index"`
 	CreatorId   uint                `json:"creator_id"`
 	Creator     User                `json:"creator" gorm:"foreignKey:CreatorId"`
 	Stories     []Story             `json:"stories,omitempty"`
 	Tags        []Tag               `json:"tags,omitempty"`
+	Links       *Links              `json:"_links,omitempty" gorm:"-:all"`
+	Version     uint                `json:"-" gorm:"not null;default:1"`
 
 	TimestampBase
 }
@@ -48,10 +53,13 @@ type ProjectInfo struct {
 	Description string              `json:"description" validate:"max=280"`
 	Status      enums.ProjectStatus `json:"-" validate:"gte=0"`
 	StatusValue string              `json:"status"`
+	Restricted  bool                `json:"restricted"`
+	TeamId      *string             `json:"team_id"`
 	StoryCount  uint16              `json:"story_count"`
 	TagCount    uint16              `json:"tag_count"`
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
+	Links       *Links              `json:"_links,omitempty" gorm:"-:all"`
 }
 
 type ProjectInfoList []*ProjectInfo
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"github.com/jinzhu/gorm"
+	"godo/internal/repository/enums"
+	"time"
+)
+
+// ProjectMember grants a user a role within a project.
+// Membership is what gives access to a restricted project.
+type ProjectMember struct {
+	ProjectId string            `json:"project_id" gorm:"primary_key"`
+	UserId    uint              `json:"user_id" gorm:"primary_key;auto_increment:false"`
+	User      User              `json:"user" gorm:"foreignKey:UserId"`
+	Role      enums.ProjectRole `json:"-" gorm:"type:smallint;default:0;not null"`
+	RoleValue string            `json:"role" gorm:"-:all"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type ProjectMemberList []*ProjectMember
+
+func (m *ProjectMember) AfterFind(tx *gorm.DB) {
+	m.RoleValue = m.Role.String()
+}
+
+func (m *ProjectMember) AfterCreate(tx *gorm.DB) {
+	m.RoleValue = m.Role.String()
+}
+
+func (m *ProjectMember) AfterSave(tx *gorm.DB) {
+	m.RoleValue = m.Role.String()
+}
+
+// ProjectMemberResponse the specified member of a Project
+// swagger:response projectMemberResponse
+type ProjectMemberResponse struct {
+	// The resultant ProjectMember
+	// in: body
+	Body ProjectMember
+}
+
+// ProjectMemberListResponse the members of a Project
+// swagger:response projectMemberListResponse
+type ProjectMemberListResponse struct {
+	// All members of the Project
+	// in: body
+	Body ProjectMemberList
+}
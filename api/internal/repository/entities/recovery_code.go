@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// RecoveryCode a single-use code allowing a user to pass two-factor authentication
+// without their authenticator app. Only the hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"-" gorm:"primary_key"`
+	UserId    uint       `json:"-" gorm:"index;not null"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
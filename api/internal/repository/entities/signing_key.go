@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// SigningKey a key used to sign JWTs. Only the newest key without a RetiredAt is used for signing,
+// retired keys continue to verify tokens until ExpiresAt so that rotation doesn't log everyone out.
+type SigningKey struct {
+	Kid        string     `gorm:"primary_key"`
+	Algorithm  string     `gorm:"not null"`
+	PrivateKey string     `gorm:"type:text;not null"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	RetiredAt  *time.Time `gorm:"index"`
+	ExpiresAt  *time.Time `gorm:"index"`
+}
@@ -19,6 +19,8 @@ type Story struct {
 	CreatorId   uint                 `json:"creator_id"`
 	Creator     User                 `json:"creator" gorm:"foreignKey:CreatorId"`
 	Tasks       []Task               `json:"tasks"`
+	Links       *Links               `json:"_links,omitempty" gorm:"-:all"`
+	Version     uint                 `json:"-" gorm:"not null;default:1"`
 
 	TimestampBase
 }
@@ -49,6 +51,7 @@ type StoryInfo struct {
 	TaskCount   uint16               `json:"task_count"`
 	CreatedAt   time.Time            `json:"created_at"`
 	UpdatedAt   time.Time            `json:"updated_at"`
+	Links       *Links               `json:"_links,omitempty" gorm:"-:all"`
 }
 
 type StoryInfoList []*StoryInfo
@@ -18,7 +18,10 @@ type Task struct {
 	Story       Story                `json:"-"`
 	CreatorId   uint                 `json:"-"`
 	Creator     User                 `json:"creator" gorm:"foreignKey:CreatorId"`
+	AssigneeId  *uint                `json:"assignee_id"`
 	Tags        []Tag                `json:"tags" gorm:"many2many:task_tags"`
+	Links       *Links               `json:"_links,omitempty" gorm:"-:all"`
+	Version     uint                 `json:"-" gorm:"not null;default:1"`
 
 	TimestampBase
 }
@@ -0,0 +1,67 @@
+package entities
+
+import "time"
+
+// Team a group of users within an account, such as a squad, which may own projects
+type Team struct {
+	Base
+
+	AccountId string         `json:"-" gorm:"index;not null"`
+	Name      string         `json:"name" gorm:"not null" validate:"required,min=1,max=40"`
+	LeadId    *uint          `json:"lead_id"`
+	Lead      *User          `json:"lead,omitempty" gorm:"foreignKey:LeadId"`
+	Members   TeamMemberList `json:"members,omitempty"`
+
+	TimestampBase
+}
+
+type TeamList []*Team
+
+// TeamMember a user belonging to a team
+type TeamMember struct {
+	TeamId    string    `json:"-" gorm:"primary_key"`
+	UserId    uint      `json:"-" gorm:"primary_key;auto_increment:false"`
+	User      User      `json:"user" gorm:"foreignKey:UserId"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TeamMemberList []*TeamMember
+
+// TeamWorkload the open tasks assigned to each member of a team
+type TeamWorkload struct {
+	TeamId  string             `json:"team_id"`
+	Members MemberWorkloadList `json:"members"`
+}
+
+// MemberWorkload the open tasks assigned to a user, counted by progress status
+type MemberWorkload struct {
+	User     User             `json:"user"`
+	Open     int64            `json:"open"`
+	ByStatus map[string]int64 `json:"by_status"`
+}
+
+type MemberWorkloadList []*MemberWorkload
+
+// TeamResponse the specified Team
+// swagger:response teamResponse
+type TeamResponse struct {
+	// The resultant Team
+	// in: body
+	Body Team
+}
+
+// TeamListResponse the teams in the authenticated account
+// swagger:response teamListResponse
+type TeamListResponse struct {
+	// All teams in the authenticated account
+	// in: body
+	Body TeamList
+}
+
+// TeamWorkloadResponse the open tasks of each member of the specified Team
+// swagger:response teamWorkloadResponse
+type TeamWorkloadResponse struct {
+	// The resultant TeamWorkload
+	// in: body
+	Body TeamWorkload
+}
@@ -2,30 +2,136 @@ package entities
 
 import (
 	"fmt"
+	"github.com/jinzhu/gorm"
+	"godo/internal/repository/enums"
 	"golang.org/x/crypto/bcrypt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type User struct {
 	ID            uint       `json:"id" gorm:"primary_key"`
 	Name          string     `json:"name" validate:"required,min=1,max=25"`
-	Username      string     `json:"username" gorm:"index" validate:"required"`
-	Discriminator uint32     `json:"discriminator" validate:"required"`
+	Username      string     `json:"username" gorm:"unique_index:idx_users_handle" validate:"required"`
+	Discriminator uint32     `json:"discriminator" gorm:"unique_index:idx_users_handle" validate:"required"`
 	Email         string     `json:"email" gorm:"unique,index" validate:"required"`
 	Password      string     `json:"-" validate:"required"`
-	AccountId     string     `json:"-" validate:"required"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 	DeletedAt     *time.Time `json:"-" sql:"index"`
+
+	// DefaultAccountId the account the user logs in to when they don't choose one
+	DefaultAccountId string `json:"-" gorm:"not null" validate:"required"`
+
+	// The account the user is acting within, along with their role and status in it, taken
+	// from their membership of the account; AccountId is empty until a membership is applied
+	AccountId     string            `json:"-" gorm:"-:all"`
+	Role          enums.AccountRole `json:"-" gorm:"-:all"`
+	RoleValue     string            `json:"role,omitempty" gorm:"-:all"`
+	DeactivatedAt *time.Time        `json:"deactivated_at,omitempty" gorm:"-:all"`
+
+	// Handle the username#discriminator identifying the user
+	Handle string `json:"handle" gorm:"-:all"`
+
+	// SessionVersion is embedded in issued tokens; incrementing it revokes every existing session
+	SessionVersion uint `json:"-" gorm:"not null;default:0"`
+
+	// Two-factor authentication; the secret is only set once enrollment has begun
+	TwoFactorEnabled  bool   `json:"two_factor_enabled" gorm:"not null;default:false"`
+	TwoFactorSecret   string `json:"-"`
+	TwoFactorLastStep int64  `json:"-"`
+
+	// The consecutive two-factor codes the user has failed, and the time until which they are locked out of
+	// giving any more after failing too many
+	TwoFactorFailures    uint       `json:"-" gorm:"not null;default:0"`
+	TwoFactorLockedUntil *time.Time `json:"-"`
+
+	// ErasedAt when the user's personal details were replaced by a tombstone
+	ErasedAt *time.Time `json:"-"`
 }
 
+// MaxDiscriminator the number of users who may share a username
+const MaxDiscriminator = 9999
+
+// The name given to erased users. Their usernames are made unique with their ID and use
+// a discriminator of 0, which is never allocated, so that no one can take their handle.
+const (
+	TombstoneName          = "Deleted user"
+	tombstoneUsername      = "deleted"
+	tombstoneDiscriminator = 0
+)
+
+type UserList []*User
 type UserKey struct{}
 
 func (u *User) String() string {
 	return fmt.Sprintf("User{ID: %d, Name: %s}", u.ID, u.Name)
 }
 
+// IsActive whether the user has not been deactivated in the account they are acting within
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
+// ApplyMembership sets the account the user is acting within, along with their role and status in it
+func (u *User) ApplyMembership(member *AccountMember) {
+	u.AccountId = member.AccountId
+	u.Role = member.Role
+	u.RoleValue = member.Role.String()
+	u.DeactivatedAt = member.DeactivatedAt
+}
+
+// MakeHandle returns the username#discriminator handle used to tell apart users sharing a username
+func (u *User) MakeHandle() string {
+	return fmt.Sprintf("%s#%d", u.Username, u.Discriminator)
+}
+
+// ParseHandle splits a username#discriminator handle into its username and discriminator;
+// false is returned if the handle is not in that format
+func ParseHandle(handle string) (string, uint32, bool) {
+	i := strings.LastIndex(handle, "#")
+	if i < 1 {
+		return "", 0, false
+	}
+
+	discriminator, err := strconv.ParseUint(handle[i+1:], 10, 32)
+	if err != nil || discriminator < 1 || discriminator > MaxDiscriminator {
+		return "", 0, false
+	}
+
+	return handle[:i], uint32(discriminator), true
+}
+
+func (u *User) AfterFind(tx *gorm.DB) {
+	u.Handle = u.MakeHandle()
+}
+
+func (u *User) AfterCreate(tx *gorm.DB) {
+	u.Handle = u.MakeHandle()
+}
+
+func (u *User) AfterSave(tx *gorm.DB) {
+	u.Handle = u.MakeHandle()
+}
+
+// Erase replaces the user's personal details with a tombstone identity. The user remains so that
+// the work they created stays attributed, but no longer to anyone identifiable, and can't log in.
+func (u *User) Erase(at time.Time) {
+	u.Name = TombstoneName
+	u.Username = fmt.Sprintf("%s-%d", tombstoneUsername, u.ID)
+	u.Discriminator = tombstoneDiscriminator
+	u.Email = fmt.Sprintf("%s-%d@erased.invalid", tombstoneUsername, u.ID)
+	u.Password = ""
+	u.TwoFactorEnabled = false
+	u.TwoFactorSecret = ""
+	u.TwoFactorLastStep = 0
+	u.SessionVersion++
+	u.ErasedAt = &at
+	u.Handle = u.MakeHandle()
+}
+
 func (u *User) HashPassword(password string) error {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	if err != nil {
@@ -46,3 +152,19 @@ func (u *User) VerifyPassword(passwordCheck string) error {
 
 	return nil
 }
+
+// UserResponse the specified User
+// swagger:response userResponse
+type UserResponse struct {
+	// The resultant User
+	// in: body
+	Body User
+}
+
+// UserListResponse the users in the authenticated account
+// swagger:response userListResponse
+type UserListResponse struct {
+	// All users in the authenticated account
+	// in: body
+	Body UserList
+}
@@ -0,0 +1,36 @@
+package enums
+
+import "fmt"
+
+// AccountRole the role of a user within their account.
+// Member is the zero value so that existing users keep their current access.
+type AccountRole uint8
+
+const (
+	Member AccountRole = iota
+	Viewer
+	Admin
+	Owner
+)
+
+func (r AccountRole) String() string {
+	switch r {
+	case Member:
+	case Viewer:
+		return "Viewer"
+	case Admin:
+		return "Admin"
+	case Owner:
+		return "Owner"
+	}
+
+	return "Member"
+}
+
+func (r AccountRole) IsValid() bool {
+	return r <= Owner
+}
+
+func (r AccountRole) Print() {
+	fmt.Println("AccountRole: ", r.String())
+}
@@ -22,6 +22,10 @@ func (s ProgressStatus) String() string {
 	return "New"
 }
 
+func (s ProgressStatus) IsValid() bool {
+	return s <= Complete
+}
+
 func (s ProgressStatus) Print() {
 	fmt.Println("ProgressStatus: ", s.String())
 }
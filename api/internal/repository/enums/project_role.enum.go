@@ -0,0 +1,38 @@
+package enums
+
+import "fmt"
+
+// ProjectRole the role of a member within a project.
+// Roles are ordered so that each grants everything the roles before it do.
+type ProjectRole uint8
+
+const (
+	Reader ProjectRole = iota
+	Contributor
+	Maintainer
+)
+
+func (r ProjectRole) String() string {
+	switch r {
+	case Reader:
+	case Contributor:
+		return "Contributor"
+	case Maintainer:
+		return "Maintainer"
+	}
+
+	return "Reader"
+}
+
+func (r ProjectRole) IsValid() bool {
+	return r <= Maintainer
+}
+
+// Grants whether the role allows everything the required role does
+func (r ProjectRole) Grants(required ProjectRole) bool {
+	return r >= required
+}
+
+func (r ProjectRole) Print() {
+	fmt.Println("ProjectRole: ", r.String())
+}
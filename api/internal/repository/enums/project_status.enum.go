@@ -19,6 +19,10 @@ func (p ProjectStatus) String() string {
 	return "Open"
 }
 
+func (p ProjectStatus) IsValid() bool {
+	return p <= Closed
+}
+
 func (p ProjectStatus) Print() {
 	fmt.Println("ProjectStatus: ", p.String())
 }
@@ -22,6 +22,10 @@ func (t TaskType) String() string {
 	return "Task"
 }
 
+func (t TaskType) IsValid() bool {
+	return t <= Test
+}
+
 func (t TaskType) Print() {
 	fmt.Println("ProjectStatus: ", t.String())
 }
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"time"
+)
+
+type InvitationQuery interface {
+	CreateInvitation(invitation *entities.Invitation) (*entities.Invitation, error)
+	GetInvitations(accountId string) (entities.InvitationList, error)
+	GetInvitationById(invitationId, accountId string) (*entities.Invitation, error)
+	GetInvitationByTokenHash(tokenHash string) (*entities.Invitation, error)
+	PendingInvitationExists(email, accountId string) (bool, error)
+	UpdateInvitation(invitation *entities.Invitation) error
+	ClaimInvitation(invitationId string) (bool, error)
+	ReleaseInvitation(invitationId string) error
+}
+
+type invitationQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewInvitationQuery(logger ilog.StdLogger) InvitationQuery {
+	return &invitationQuery{log: logger}
+}
+
+func (q *invitationQuery) CreateInvitation(invitation *entities.Invitation) (*entities.Invitation, error) {
+	q.log.Debugf("Creating Invitation{email=%s} for Account{id=%s}", invitation.Email, invitation.AccountId)
+
+	err := Database.Create(invitation).Error
+	ilog.ErrorlnIf(err, q.log)
+
+	return invitation, err
+}
+
+func (q *invitationQuery) GetInvitations(accountId string) (entities.InvitationList, error) {
+	q.log.Debugf("Fetching all Invitations for Account{id=%s}", accountId)
+
+	var invitations entities.InvitationList
+	err := Database.
+		Preload("Inviter").
+		Where("account_id = ?", accountId).
+		Order("created_at DESC").
+		Find(&invitations).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return invitations, err
+}
+
+func (q *invitationQuery) GetInvitationById(invitationId, accountId string) (*entities.Invitation, error) {
+	q.log.Debugf("Fetching Invitation{id=%s} for Account{id=%s}", invitationId, accountId)
+
+	var invitation entities.Invitation
+	err := Database.
+		Preload("Inviter").
+		First(&invitation, "id = ? AND account_id = ?", invitationId, accountId).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return &invitation, err
+}
+
+func (q *invitationQuery) GetInvitationByTokenHash(tokenHash string) (*entities.Invitation, error) {
+	q.log.Debug("Fetching Invitation by token")
+
+	var invitation entities.Invitation
+	err := Database.First(&invitation, "token_hash = ?", tokenHash).Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return &invitation, err
+}
+
+func (q *invitationQuery) PendingInvitationExists(email, accountId string) (bool, error) {
+	var count int64
+	r := Database.Model(&entities.Invitation{}).
+		Where("email = ? AND account_id = ?", email, accountId).
+		Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now()).
+		Count(&count)
+
+	ilog.ErrorlnIf(r.Error, q.log)
+	return count >= 1, r.Error
+}
+
+func (q *invitationQuery) UpdateInvitation(invitation *entities.Invitation) error {
+	q.log.Debugf("Updating Invitation{id=%s}", invitation.ID)
+
+	err := Database.Save(invitation).Error
+	ilog.ErrorlnIf(err, q.log)
+
+	return err
+}
+
+// ClaimInvitation atomically marks a pending invitation as accepted, so that its token can only be used once
+func (q *invitationQuery) ClaimInvitation(invitationId string) (bool, error) {
+	q.log.Debugf("Claiming Invitation{id=%s}", invitationId)
+
+	now := time.Now()
+	r := Database.Model(&entities.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", invitationId, now).
+		Update("accepted_at", now)
+
+	ilog.ErrorlnIf(r.Error, q.log)
+	return r.RowsAffected == 1, r.Error
+}
+
+// ReleaseInvitation reverts a claim when the invitee could not be registered
+func (q *invitationQuery) ReleaseInvitation(invitationId string) error {
+	q.log.Debugf("Releasing Invitation{id=%s}", invitationId)
+
+	err := Database.Model(&entities.Invitation{}).
+		Where("id = ?", invitationId).
+		Update("accepted_at", nil).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	ehand "godo/internal/api/errorhandler"
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type PersonalDataQuery interface {
+	GetPersonalData(userId uint) (*entities.PersonalData, error)
+	EraseUser(userId uint) error
+}
+
+type personalDataQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewPersonalDataQuery(logger ilog.StdLogger) PersonalDataQuery {
+	return &personalDataQuery{log: logger}
+}
+
+// GetPersonalData assembles everything stored about the user, across every account they belong to
+func (q *personalDataQuery) GetPersonalData(userId uint) (*entities.PersonalData, error) {
+	q.log.Debugf("Exporting the personal data of User{id=%d}", userId)
+
+	data := entities.PersonalData{ExportedAt: time.Now()}
+	results := []*gorm.DB{
+		Database.First(&data.Profile, userId),
+		Database.
+			Preload("Account").
+			Where("user_id = ?", userId).
+			Order("created_at").
+			Find(&data.Accounts),
+		Database.
+			Preload("User").
+			Where("user_id = ?", userId).
+			Order("created_at").
+			Find(&data.ProjectMemberships),
+		Database.
+			Joins("JOIN team_members ON team_members.team_id = teams.id").
+			Where("team_members.user_id = ?", userId).
+			Order("teams.name").
+			Find(&data.Teams),
+		Database.
+			Preload("Creator").
+			Where("creator_id = ?", userId).
+			Order("created_at").
+			Find(&data.Projects),
+		Database.
+			Preload("Creator").
+			Where("creator_id = ?", userId).
+			Order("created_at").
+			Find(&data.Stories),
+		Database.
+			Preload("Creator").
+			Preload("Tags").
+			Where("creator_id = ? OR assignee_id = ?", userId, userId).
+			Order("created_at").
+			Find(&data.Tasks),
+		Database.
+			Preload("Inviter").
+			Where("inviter_id = ?", userId).
+			Order("created_at").
+			Find(&data.InvitationsSent),
+		Database.
+			Preload("Inviter").
+			Where("lower(email) = (SELECT lower(email) FROM users WHERE id = ?)", userId).
+			Order("created_at").
+			Find(&data.InvitationsReceived),
+		Database.
+			Where("user_id = ?", userId).
+			Order("created_at").
+			Find(&data.RecoveryCodes),
+	}
+
+	for _, result := range results {
+		if gorm.IsRecordNotFoundError(result.Error) {
+			return nil, ehand.ErrorUserNotFound
+		}
+
+		if result.Error != nil {
+			q.log.Errorf("Could not export the personal data of User{id=%d}: %s", userId, result.Error)
+			return nil, result.Error
+		}
+	}
+
+	return &data, nil
+}
+
+// EraseUser anonymises the user, replacing them with a tombstone identity which remains the creator
+// of their work. Their memberships, recovery codes and the pending invitations sent to them are removed
+// and their open tasks are unassigned. The last active owner of an account can't be erased.
+func (q *personalDataQuery) EraseUser(userId uint) error {
+	q.log.Infof("Erasing User{id=%d}", userId)
+
+	tx := Database.Begin()
+
+	var user entities.User
+	err := tx.Set("gorm:query_option", "FOR UPDATE").First(&user, userId).Error
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return ehand.ErrorUserNotFound
+	}
+
+	var ownerships entities.AccountMemberList
+	err = tx.Where("user_id = ? AND role = ? AND deactivated_at IS NULL", userId, enums.Owner).
+		Find(&ownerships).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	for _, ownership := range ownerships {
+		owners, err := lockActiveOwners(tx, ownership.AccountId)
+		if err != nil {
+			tx.Rollback()
+			q.log.Error(err)
+			return err
+		}
+
+		if len(owners) <= 1 {
+			tx.Rollback()
+			return ehand.ErrorLastOwner
+		}
+	}
+
+	// Ordered so that the user's email address is still known when their invitations are removed
+	statements := []string{
+		"DELETE FROM invitations WHERE accepted_at IS NULL AND lower(email) = " +
+			"(SELECT lower(email) FROM users WHERE id = ?)",
+		"DELETE FROM recovery_codes WHERE user_id = ?",
+		"DELETE FROM team_members WHERE user_id = ?",
+		"UPDATE teams SET lead_id = NULL WHERE lead_id = ?",
+		"DELETE FROM project_members WHERE user_id = ?",
+		"DELETE FROM account_members WHERE user_id = ?",
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement, userId).Error; err != nil {
+			tx.Rollback()
+			q.log.Errorf("Could not erase User{id=%d}: %s", userId, err)
+			return err
+		}
+	}
+
+	err = tx.Model(&entities.Task{}).
+		Where("assignee_id = ? AND status <> ?", userId, enums.Complete).
+		Update("assignee_id", gorm.Expr("NULL")).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	user.Erase(time.Now())
+	if err := tx.Save(&user).Error; err != nil {
+		tx.Rollback()
+		q.log.Errorf("Could not erase User{id=%d}: %s", userId, err)
+		return err
+	}
+
+	return tx.Commit().Error
+}
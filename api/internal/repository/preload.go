@@ -0,0 +1,33 @@
+package repository
+
+import "github.com/jinzhu/gorm"
+
+// Preload the associations loaded with the entities of a query, as the paths of their gorm associations,
+// such as "Stories.Tasks.Tags"
+type Preload []string
+
+var (
+	// PreloadCreator loads only the creator of the entity
+	PreloadCreator = Preload{"Creator"}
+
+	// PreloadProjectTree loads the whole of the project; its creator and tags, and its stories with their
+	// creators and tasks, which are loaded with their creators and tags
+	PreloadProjectTree = Preload{
+		"Creator",
+		"Stories",
+		"Stories.Creator",
+		"Stories.Tasks",
+		"Stories.Tasks.Creator",
+		"Stories.Tasks.Tags",
+		"Tags",
+	}
+)
+
+// apply adds the preloading of each association to the query
+func (p Preload) apply(db *gorm.DB) *gorm.DB {
+	for _, association := range p {
+		db = db.Preload(association)
+	}
+
+	return db
+}
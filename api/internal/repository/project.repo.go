@@ -3,14 +3,49 @@ package repository
 import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
 	"time"
+
+	"github.com/jinzhu/gorm"
 )
 
+// ProjectScope limits a query to the projects visible to a user
+type ProjectScope struct {
+	AccountId string
+	UserId    uint
+
+	// AllProjects whether restricted projects are visible without being a member of them
+	AllProjects bool
+
+	// TeamId when set, limits the scope to the projects owned by the team
+	TeamId string
+}
+
+// apply limits the query, which must include the projects table, to the projects in scope
+func (s ProjectScope) apply(db *gorm.DB) *gorm.DB {
+	db = db.Where("projects.account_id = ?", s.AccountId)
+	if s.TeamId != "" {
+		db = db.Where("projects.team_id = ?", s.TeamId)
+	}
+
+	if s.AllProjects {
+		return db
+	}
+
+	return db.Where("NOT projects.restricted OR EXISTS (SELECT 1 FROM project_members "+
+		"WHERE project_members.project_id = projects.id AND project_members.user_id = ?)", s.UserId)
+}
+
 type ProjectQuery interface {
-	GetProjectById(projectId string, accountId string) (*entities.Project, error)
-	GetProjectsInfo(accountId string) (entities.ProjectInfoList, error)
+	GetProjectById(projectId string, scope ProjectScope, preload Preload) (*entities.Project, error)
+	GetProjectsInfo(scope ProjectScope) (entities.ProjectInfoList, error)
+	GetProjectsByIds(projectIds []string, scope ProjectScope) (entities.ProjectList, error)
+	FindProject(projectId string, scope ProjectScope) (*entities.Project, error)
 	CreateProject(newProject *entities.Project) (*entities.Project, error)
 	UpdateProject(projectId string, newProject *entities.Project) error
+	PatchProject(projectId string, changes map[string]interface{}) error
+	SetRestricted(projectId string, restricted bool) error
+	SetTeam(projectId string, teamId *string) error
 	DeleteProject(projectId string) error
 	Exists(projectId string) bool
 }
@@ -23,18 +58,18 @@ func (d *dao) NewProjectQuery(logger ilog.StdLogger) ProjectQuery {
 	return &projectQuery{log: logger}
 }
 
-func (q *projectQuery) GetProjectsInfo(accountId string) (entities.ProjectInfoList, error) {
-	q.log.Debugf("Fetching all project information associated with Account{id=%s}", accountId)
+func (q *projectQuery) GetProjectsInfo(scope ProjectScope) (entities.ProjectInfoList, error) {
+	q.log.Debugf("Fetching all project information visible to User{id=%d}", scope.UserId)
 
 	var info entities.ProjectInfoList
 	r := Database.
 		Table("projects").
-		Select("projects.id, projects.name, projects.description, projects.status, "+
-			"projects.created_at, projects.updated_at, count(stories.id) story_count, count(tags.id) tag_count").
-		Joins("LEFT JOIN users ON users.account_id = ?", accountId).
+		Select("projects.id, projects.name, projects.description, projects.status, projects.restricted, " +
+			"projects.team_id, projects.created_at, projects.updated_at, count(distinct stories.id) story_count, " +
+			"count(distinct tags.id) tag_count").
 		Joins("LEFT JOIN stories ON projects.id = stories.project_id").
 		Joins("LEFT JOIN tags ON projects.id = tags.project_id").
-		Where("users.account_id = ?", accountId).
+		Scopes(scope.apply).
 		Group("projects.id").
 		Order("projects.created_at DESC").
 		Find(&info)
@@ -43,33 +78,71 @@ func (q *projectQuery) GetProjectsInfo(accountId string) (entities.ProjectInfoLi
 	return info, r.Error
 }
 
-func (q *projectQuery) GetProjectById(projectId string, accountId string) (*entities.Project, error) {
-	q.log.Debugf("Fetching project with projectId %s and accountId %s", projectId, accountId)
+// GetProjectById fetches the project in scope, loading the associations given by preload
+func (q *projectQuery) GetProjectById(projectId string, scope ProjectScope, preload Preload) (*entities.Project, error) {
+	q.log.Debugf("Fetching project with projectId %s and accountId %s", projectId, scope.AccountId)
 
 	var project entities.Project
 	result := Database.
-		Preload("Creator", "account_id = ?", accountId).
-		Preload("Stories", "stories.project_id = ?", projectId).
-		Preload("Stories.Creator").
-		Preload("Stories.Tasks").
-		Preload("Stories.Tasks.Creator").
-		Preload("Stories.Tasks.Tags").
-		Preload("Tags").
-		Joins("JOIN users on projects.creator_id = users.id").
-		Where("users.account_id = ?", accountId).
+		Scopes(preload.apply, scope.apply).
 		First(&project, "projects.id = ?", projectId)
 
 	ilog.ErrorlnIf(result.Error, q.log)
 	return &project, result.Error
 }
 
+// GetProjectsByIds fetches the projects in scope among those given, without loading their stories and tags
+func (q *projectQuery) GetProjectsByIds(projectIds []string, scope ProjectScope) (entities.ProjectList, error) {
+	q.log.Debugf("Fetching %d Projects visible to User{id=%d}", len(projectIds), scope.UserId)
+
+	var projects entities.ProjectList
+	err := Database.
+		Scopes(scope.apply).
+		Where("projects.id IN (?)", projectIds).
+		Find(&projects).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return projects, err
+}
+
+// FindProject fetches the project without loading its stories and tags
+func (q *projectQuery) FindProject(projectId string, scope ProjectScope) (*entities.Project, error) {
+	q.log.Debugf("Finding Project{id=%s} visible to User{id=%d}", projectId, scope.UserId)
+
+	var project entities.Project
+	result := Database.
+		Scopes(scope.apply).
+		First(&project, "projects.id = ?", projectId)
+
+	ilog.ErrorlnIf(result.Error, q.log)
+	return &project, result.Error
+}
+
+// CreateProject creates the project, making its creator the first maintainer
 func (q *projectQuery) CreateProject(newProject *entities.Project) (*entities.Project, error) {
 	q.log.Debugf("Creating Project with name %v", newProject.Name)
 
-	response := Database.Create(&newProject)
-	ilog.ErrorlnIf(response.Error, q.log)
+	tx := Database.Begin()
+	if err := tx.Create(&newProject).Error; err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return newProject, err
+	}
+
+	creator := entities.ProjectMember{
+		ProjectId: newProject.ID,
+		UserId:    newProject.Creator.ID,
+		Role:      enums.Maintainer,
+	}
+
+	if err := tx.Create(&creator).Error; err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return newProject, err
+	}
 
-	return newProject, response.Error
+	return newProject, tx.Commit().Error
 }
 
 func (q *projectQuery) UpdateProject(projectId string, newProject *entities.Project) error {
@@ -85,13 +158,57 @@ func (q *projectQuery) UpdateProject(projectId string, newProject *entities.Proj
 	// TODO: Place override of props into model?
 	project.Name = newProject.Name
 	project.Description = newProject.Description
+	project.Status = newProject.Status
 	project.UpdatedAt = time.Now()
+	project.Version++
 	result := Database.Save(&project)
 
 	ilog.ErrorlnIf(result.Error, q.log)
 	return result.Error
 }
 
+// PatchProject persists only the given columns of the project
+func (q *projectQuery) PatchProject(projectId string, changes map[string]interface{}) error {
+	q.log.Debugf("Patching Project{id=%s}", projectId)
+
+	changes["updated_at"] = time.Now()
+	changes["version"] = nextVersion()
+	err := Database.
+		Model(&entities.Project{}).
+		Where("id = ?", projectId).
+		Updates(changes).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+func (q *projectQuery) SetRestricted(projectId string, restricted bool) error {
+	q.log.Debugf("Setting Project{id=%s} restricted to %t", projectId, restricted)
+
+	err := Database.
+		Model(&entities.Project{}).
+		Where("id = ?", projectId).
+		Updates(map[string]interface{}{"restricted": restricted, "updated_at": time.Now(), "version": nextVersion()}).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+func (q *projectQuery) SetTeam(projectId string, teamId *string) error {
+	q.log.Debugf("Setting the team owning Project{id=%s}", projectId)
+
+	err := Database.
+		Model(&entities.Project{}).
+		Where("id = ?", projectId).
+		Updates(map[string]interface{}{"team_id": teamId, "updated_at": time.Now(), "version": nextVersion()}).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
 func (q *projectQuery) DeleteProject(projectId string) error {
 	q.log.Debugf("Deleting Project{id=%s}", projectId)
 
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+
+	"github.com/jinzhu/gorm"
+)
+
+type ProjectMemberQuery interface {
+	GetMembers(projectId string) (entities.ProjectMemberList, error)
+	GetMember(projectId string, userId uint) (*entities.ProjectMember, error)
+	GetMemberRole(projectId string, userId uint) (enums.ProjectRole, bool, error)
+	SaveMember(projectId string, userId uint, role enums.ProjectRole) error
+	RemoveMember(projectId string, userId uint) (bool, error)
+}
+
+type projectMemberQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewProjectMemberQuery(logger ilog.StdLogger) ProjectMemberQuery {
+	return &projectMemberQuery{log: logger}
+}
+
+func (q *projectMemberQuery) GetMembers(projectId string) (entities.ProjectMemberList, error) {
+	q.log.Debugf("Fetching the members of Project{id=%s}", projectId)
+
+	var members entities.ProjectMemberList
+	err := Database.
+		Preload("User").
+		Where("project_id = ?", projectId).
+		Order("role DESC, created_at").
+		Find(&members).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return members, err
+}
+
+func (q *projectMemberQuery) GetMember(projectId string, userId uint) (*entities.ProjectMember, error) {
+	q.log.Debugf("Fetching User{id=%d} in Project{id=%s}", userId, projectId)
+
+	var member entities.ProjectMember
+	err := Database.
+		Preload("User").
+		First(&member, "project_id = ? AND user_id = ?", projectId, userId).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return &member, err
+}
+
+// GetMemberRole returns the user's role in the project; false is returned if they are not a member
+func (q *projectMemberQuery) GetMemberRole(projectId string, userId uint) (enums.ProjectRole, bool, error) {
+	var member entities.ProjectMember
+	err := Database.First(&member, "project_id = ? AND user_id = ?", projectId, userId).Error
+
+	if gorm.IsRecordNotFoundError(err) {
+		return enums.Reader, false, nil
+	}
+
+	if err != nil {
+		q.log.Error(err)
+		return enums.Reader, false, err
+	}
+
+	return member.Role, true, nil
+}
+
+// SaveMember adds the user to the project, or changes their role if they are already a member
+func (q *projectMemberQuery) SaveMember(projectId string, userId uint, role enums.ProjectRole) error {
+	q.log.Debugf("Saving User{id=%d} in Project{id=%s} as %s", userId, projectId, role)
+
+	var member entities.ProjectMember
+	err := Database.
+		Where(entities.ProjectMember{ProjectId: projectId, UserId: userId}).
+		Assign(map[string]interface{}{"role": role}).
+		FirstOrCreate(&member).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+// RemoveMember removes the user from the project; false is returned if they were not a member
+func (q *projectMemberQuery) RemoveMember(projectId string, userId uint) (bool, error) {
+	q.log.Debugf("Removing User{id=%d} from Project{id=%s}", userId, projectId)
+
+	r := Database.
+		Where("project_id = ? AND user_id = ?", projectId, userId).
+		Delete(&entities.ProjectMember{})
+
+	ilog.ErrorlnIf(r.Error, q.log)
+	return r.RowsAffected == 1, r.Error
+}
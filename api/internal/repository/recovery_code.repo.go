@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"time"
+)
+
+type RecoveryCodeQuery interface {
+	ReplaceRecoveryCodes(userId uint, codeHashes []string) error
+	UseRecoveryCode(userId uint, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userId uint) error
+}
+
+type recoveryCodeQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewRecoveryCodeQuery(logger ilog.StdLogger) RecoveryCodeQuery {
+	return &recoveryCodeQuery{log: logger}
+}
+
+// ReplaceRecoveryCodes removes any existing recovery codes for the user and stores the given hashes
+func (q *recoveryCodeQuery) ReplaceRecoveryCodes(userId uint, codeHashes []string) error {
+	q.log.Debugf("Replacing recovery codes for User{id=%d}", userId)
+
+	tx := Database.Begin()
+	err := tx.Where("user_id = ?", userId).Delete(&entities.RecoveryCode{}).Error
+	if err != nil {
+		tx.Rollback()
+		q.log.Error("Could not delete existing recovery codes: ", err)
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		code := entities.RecoveryCode{UserId: userId, CodeHash: hash}
+		if err := tx.Create(&code).Error; err != nil {
+			tx.Rollback()
+			q.log.Error("Could not create recovery code: ", err)
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// UseRecoveryCode marks the matching unused recovery code as used, returning false if there was no such code
+func (q *recoveryCodeQuery) UseRecoveryCode(userId uint, codeHash string) (bool, error) {
+	q.log.Debugf("Using recovery code for User{id=%d}", userId)
+
+	r := Database.Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+
+	ilog.ErrorlnIf(r.Error, q.log)
+	return r.RowsAffected == 1, r.Error
+}
+
+func (q *recoveryCodeQuery) DeleteRecoveryCodes(userId uint) error {
+	q.log.Debugf("Deleting recovery codes for User{id=%d}", userId)
+
+	err := Database.Where("user_id = ?", userId).Delete(&entities.RecoveryCode{}).Error
+	ilog.ErrorlnIf(err, q.log)
+
+	return err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// The PostgreSQL advisory lock held while the key set is changed, such that instances creating or rotating
+// keys at the same time do not retire each other's keys
+const signingKeysLock = 0x676f646f6b657973
+
+type SigningKeyQuery interface {
+	GetValidSigningKeys(now time.Time) ([]*entities.SigningKey, error)
+	GetFirstSigningKey() (*entities.SigningKey, error)
+	CreateFirstSigningKey(key *entities.SigningKey) (bool, error)
+	RotateSigningKeys(newKey *entities.SigningKey, gracePeriod time.Duration) error
+}
+
+type signingKeyQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewSigningKeyQuery(logger ilog.StdLogger) SigningKeyQuery {
+	return &signingKeyQuery{log: logger}
+}
+
+// GetValidSigningKeys returns all keys which can still verify tokens, newest first
+func (q *signingKeyQuery) GetValidSigningKeys(now time.Time) ([]*entities.SigningKey, error) {
+	q.log.Debug("Fetching valid signing keys")
+
+	var keys []*entities.SigningKey
+	err := Database.
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("created_at DESC").
+		Find(&keys).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return keys, err
+}
+
+// GetFirstSigningKey returns the oldest key, expired or not, which replaced the legacy secret; nil when
+// no key has been created
+func (q *signingKeyQuery) GetFirstSigningKey() (*entities.SigningKey, error) {
+	var key entities.SigningKey
+	err := Database.Order("created_at ASC").First(&key).Error
+
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+
+	ilog.ErrorlnIf(err, q.log)
+	return &key, err
+}
+
+// RotateSigningKeys retires every active key, keeping them valid for verification for the grace period,
+// and adds the new signing key in the same transaction
+func (q *signingKeyQuery) RotateSigningKeys(newKey *entities.SigningKey, gracePeriod time.Duration) error {
+	q.log.Infof("Rotating signing keys to SigningKey{kid=%s, alg=%s}", newKey.Kid, newKey.Algorithm)
+
+	now := time.Now()
+	expiresAt := now.Add(gracePeriod)
+
+	tx := Database.Begin()
+	if err := lockSigningKeys(tx); err != nil {
+		tx.Rollback()
+		q.log.Error("Could not lock the signing keys: ", err)
+		return err
+	}
+
+	err := tx.Model(&entities.SigningKey{}).
+		Where("retired_at IS NULL").
+		Updates(map[string]interface{}{"retired_at": now, "expires_at": expiresAt}).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error("Could not retire the active signing keys: ", err)
+		return err
+	}
+
+	newKey.CreatedAt = now
+	if err := tx.Create(newKey).Error; err != nil {
+		tx.Rollback()
+		q.log.Error("Could not create the new signing key: ", err)
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// CreateFirstSigningKey adds the key unless an active key already exists, such as one created by another
+// instance, returning whether it was added
+func (q *signingKeyQuery) CreateFirstSigningKey(key *entities.SigningKey) (bool, error) {
+	tx := Database.Begin()
+	if err := lockSigningKeys(tx); err != nil {
+		tx.Rollback()
+		q.log.Error("Could not lock the signing keys: ", err)
+		return false, err
+	}
+
+	var active int
+	if err := tx.Model(&entities.SigningKey{}).Where("retired_at IS NULL").Count(&active).Error; err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return false, err
+	}
+
+	if active > 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	q.log.Infof("Creating the first SigningKey{kid=%s, alg=%s}", key.Kid, key.Algorithm)
+
+	key.CreatedAt = time.Now()
+	if err := tx.Create(key).Error; err != nil {
+		tx.Rollback()
+		q.log.Error("Could not create the first signing key: ", err)
+		return false, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+// lockSigningKeys holds the signing keys lock until the transaction ends
+func lockSigningKeys(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", int64(signingKeysLock)).Error
+}
@@ -3,15 +3,19 @@ package repository
 import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"time"
 )
 
 type StoryQuery interface {
 	CreateStory(newStory *entities.Story) (*entities.Story, error)
 	DeleteStory(storyId string) error
 	Exists(storyId string) bool
-	GetStoriesInfo(accountId string) (entities.StoryInfoList, error)
-	GetStoryById(accountId, storyId string) (*entities.Story, error)
+	GetStoriesInfo(scope ProjectScope) (entities.StoryInfoList, error)
+	GetStoryById(storyId string, scope ProjectScope, preload Preload) (*entities.Story, error)
+	GetStoriesByIds(storyIds []string, scope ProjectScope) ([]*entities.Story, error)
+	GetStoriesByProjectIds(projectIds []string, scope ProjectScope) ([]*entities.Story, error)
 	UpdateStory(newStory *entities.Story) error
+	PatchStory(storyId string, changes map[string]interface{}) error
 }
 
 type storyQuery struct {
@@ -24,17 +28,17 @@ func (d *dao) NewStoryQuery(logger ilog.StdLogger) StoryQuery {
 	}
 }
 
-func (q *storyQuery) GetStoriesInfo(accountId string) (entities.StoryInfoList, error) {
-	q.log.Debugf("Fetching all story info for Account{id=%s}", accountId)
+func (q *storyQuery) GetStoriesInfo(scope ProjectScope) (entities.StoryInfoList, error) {
+	q.log.Debugf("Fetching all story info visible to User{id=%d}", scope.UserId)
 
 	var info entities.StoryInfoList
 	r := Database.
 		Table("stories").
-		Select("stories.id, stories.name, stories.description, stories.status, stories.created_at, "+
+		Select("stories.id, stories.name, stories.description, stories.status, stories.created_at, " +
 			"stories.updated_at, count(tasks.id) task_count").
-		Joins("LEFT JOIN users ON users.account_id = ?", accountId).
+		Joins("JOIN projects ON stories.project_id = projects.id").
 		Joins("LEFT JOIN tasks ON stories.id = tasks.story_id").
-		Where("users.account_id = ?", accountId).
+		Scopes(scope.apply).
 		Group("stories.id").
 		Order("stories.created_at DESC").
 		Find(&info)
@@ -43,20 +47,55 @@ func (q *storyQuery) GetStoriesInfo(accountId string) (entities.StoryInfoList, e
 	return info, r.Error
 }
 
-func (q *storyQuery) GetStoryById(accountId, storyId string) (*entities.Story, error) {
-	q.log.Debugf("Fetching story with Account{id=%s} & Story{id=%s}", accountId, storyId)
+// GetStoryById fetches the story in scope, loading the associations given by preload
+func (q *storyQuery) GetStoryById(storyId string, scope ProjectScope, preload Preload) (*entities.Story, error) {
+	q.log.Debugf("Fetching story with Account{id=%s} & Story{id=%s}", scope.AccountId, storyId)
 
 	story := entities.Story{}
 	result := Database.
-		Preload("Creator", "account_id = ?", accountId).
-		Joins("JOIN users on stories.creator_id = users.id").
-		Where("users.account_id = ?", accountId).
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(preload.apply, scope.apply).
 		First(&story, "stories.id = ?", storyId)
 
 	ilog.ErrorlnIf(result.Error, q.log)
 	return &story, result.Error
 }
 
+// GetStoriesByIds fetches the stories in scope among those given, without loading their tasks
+func (q *storyQuery) GetStoriesByIds(storyIds []string, scope ProjectScope) ([]*entities.Story, error) {
+	q.log.Debugf("Fetching %d Stories visible to User{id=%d}", len(storyIds), scope.UserId)
+
+	var stories []*entities.Story
+	err := Database.
+		Select("stories.*").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(scope.apply).
+		Where("stories.id IN (?)", storyIds).
+		Find(&stories).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return stories, err
+}
+
+// GetStoriesByProjectIds fetches the stories of the projects in scope among those given, without loading their tasks
+func (q *storyQuery) GetStoriesByProjectIds(projectIds []string, scope ProjectScope) ([]*entities.Story, error) {
+	q.log.Debugf("Fetching the Stories of %d Projects visible to User{id=%d}", len(projectIds), scope.UserId)
+
+	var stories []*entities.Story
+	err := Database.
+		Select("stories.*").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(scope.apply).
+		Where("stories.project_id IN (?)", projectIds).
+		Order("stories.created_at DESC").
+		Find(&stories).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return stories, err
+}
+
 func (q *storyQuery) CreateStory(newStory *entities.Story) (*entities.Story, error) {
 	q.log.Debugf("Creating Story{name=%s}", newStory.Name)
 
@@ -79,15 +118,41 @@ func (q *storyQuery) Exists(storyId string) bool {
 func (q *storyQuery) UpdateStory(story *entities.Story) error {
 	q.log.Debugf("Updating Story{id=%s}", story.ID)
 
-	r := Database.Save(&story)
-	ilog.ErrorlnIf(r.Error, q.log)
+	r := Database.Omit("version").Save(&story)
+	if r.Error == nil {
+		r.Error = bumpVersion(&entities.Story{}, story.ID)
+	}
 
+	ilog.ErrorlnIf(r.Error, q.log)
 	return r.Error
 }
 
+// PatchStory persists only the given columns of the story
+func (q *storyQuery) PatchStory(storyId string, changes map[string]interface{}) error {
+	q.log.Debugf("Patching Story{id=%s}", storyId)
+
+	changes["updated_at"] = time.Now()
+	changes["version"] = nextVersion()
+	err := Database.
+		Model(&entities.Story{}).
+		Where("id = ?", storyId).
+		Updates(changes).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
 func (q *storyQuery) DeleteStory(storyId string) error {
 	q.log.Debugf("Deleting Story{id=%s}", storyId)
 
+	// The project losing the story is changed
+	err := touch(&entities.Project{}, "id = (SELECT project_id FROM stories WHERE stories.id = ?)", storyId)
+	if err != nil {
+		q.log.Error("could not update the project of the story: ", err)
+		return err
+	}
+
 	var deletedStory entities.Story
 	r := Database.Where("id = ?", storyId).Delete(&deletedStory)
 	ilog.ErrorlnIf(r.Error, q.log)
@@ -10,6 +10,8 @@ type TagQuery interface {
 	Exists(tagId uint) bool
 	ExistsWithName(name, projectId string) bool
 	GetTagById(tagId uint, projectId string) (*entities.Tag, error)
+	GetTagsByProjectIds(projectIds []string) (entities.TagList, error)
+	GetTagsByTaskIds(taskIds []string) (map[string]entities.TagList, error)
 	CreateTag(newTag entities.Tag) (*entities.Tag, error)
 	UpdateTag(newTag entities.Tag) (*entities.Tag, error)
 	DeleteTag(tagId uint) (*entities.Tag, error)
@@ -55,12 +57,56 @@ func (q *tagQuery) GetTagById(tagId uint, projectId string) (*entities.Tag, erro
 	return &tag, err
 }
 
+func (q *tagQuery) GetTagsByProjectIds(projectIds []string) (entities.TagList, error) {
+	q.log.Debugf("Fetching the Tags of %d Projects", len(projectIds))
+
+	var tags entities.TagList
+	err := Database.Where("project_id IN (?)", projectIds).Order("name").Find(&tags).Error
+	ilog.ErrorlnIf(err, q.log)
+
+	return tags, err
+}
+
+// GetTagsByTaskIds fetches the tags of each of the given tasks, keyed by the task's id
+func (q *tagQuery) GetTagsByTaskIds(taskIds []string) (map[string]entities.TagList, error) {
+	q.log.Debugf("Fetching the Tags of %d Tasks", len(taskIds))
+
+	var rows []struct {
+		entities.Tag
+		TaskId string
+	}
+
+	err := Database.
+		Table("tags").
+		Select("tags.*, task_tags.task_id").
+		Joins("JOIN task_tags ON task_tags.tag_id = tags.id").
+		Where("task_tags.task_id IN (?)", taskIds).
+		Order("tags.name").
+		Scan(&rows).
+		Error
+
+	if err != nil {
+		q.log.Error(err)
+		return nil, err
+	}
+
+	tags := make(map[string]entities.TagList)
+	for _, row := range rows {
+		tags[row.TaskId] = append(tags[row.TaskId], row.Tag)
+	}
+
+	return tags, nil
+}
+
 func (q *tagQuery) CreateTag(newTag entities.Tag) (*entities.Tag, error) {
 	q.log.Debugf("Creating Tag{name=%s}", newTag.Name)
 
 	r := Database.Create(&newTag)
-	ilog.ErrorlnIf(r.Error, q.log)
+	if r.Error == nil {
+		r.Error = q.touchProject(newTag.ID)
+	}
 
+	ilog.ErrorlnIf(r.Error, q.log)
 	return &newTag, r.Error
 }
 
@@ -68,6 +114,15 @@ func (q *tagQuery) UpdateTag(newTag entities.Tag) (*entities.Tag, error) {
 	q.log.Debugf("Updating Tag with tagId %d", newTag.ID)
 
 	err := Database.Save(newTag).Error
+	if err == nil {
+		err = q.touchProject(newTag.ID)
+	}
+
+	// The tasks holding the tag are changed
+	if err == nil {
+		err = touch(&entities.Task{}, "id IN (SELECT task_id FROM task_tags WHERE task_tags.tag_id = ?)", newTag.ID)
+	}
+
 	if err != nil {
 		q.log.Error("Could not update Tag: ", err)
 		return nil, err
@@ -79,8 +134,19 @@ func (q *tagQuery) UpdateTag(newTag entities.Tag) (*entities.Tag, error) {
 func (q *tagQuery) DeleteTag(tagId uint) (*entities.Tag, error) {
 	q.log.Debugf("Deleting tag with tagId %d", tagId)
 
+	// The project and the tasks losing the tag are changed
+	if err := q.touchProject(tagId); err != nil {
+		return nil, err
+	}
+
+	err := touch(&entities.Task{}, "id IN (SELECT task_id FROM task_tags WHERE task_tags.tag_id = ?)", tagId)
+	if err != nil {
+		q.log.Error("could not update the tasks of the tag: ", err)
+		return nil, err
+	}
+
 	// Delete the related task_tag data
-	err := Database.Exec("DELETE FROM task_tags WHERE task_tags.tag_id = ?", tagId).Error
+	err = Database.Exec("DELETE FROM task_tags WHERE task_tags.tag_id = ?", tagId).Error
 	if err != nil {
 		q.log.Error("could not delete task_tags: ", err)
 		return nil, err
@@ -111,7 +177,7 @@ func (q *tagQuery) AddTagToTask(taskId string, tagId uint) error {
 		return errors.New("issue relating tag with task")
 	}
 
-	return nil
+	return q.touchTask(taskId)
 }
 
 func (q *tagQuery) RemoveTagFromTask(taskId string, tagId uint) error {
@@ -122,5 +188,21 @@ func (q *tagQuery) RemoveTagFromTask(taskId string, tagId uint) error {
 		return err
 	}
 
-	return nil
+	return q.touchTask(taskId)
+}
+
+// touchTask marks the task as changed by a change to its tags
+func (q *tagQuery) touchTask(taskId string) error {
+	err := touch(&entities.Task{}, "id = ?", taskId)
+	ilog.ErrorlnIf(err, q.log)
+
+	return err
+}
+
+// touchProject marks the project of the tag as changed by a change to its tags
+func (q *tagQuery) touchProject(tagId uint) error {
+	err := touch(&entities.Project{}, "id = (SELECT project_id FROM tags WHERE tags.id = ?)", tagId)
+	ilog.ErrorlnIf(err, q.log)
+
+	return err
 }
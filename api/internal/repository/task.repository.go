@@ -3,14 +3,17 @@ package repository
 import (
 	"godo/internal/helper/ilog"
 	"godo/internal/repository/entities"
+	"time"
 )
 
 type TaskQuery interface {
-	Exists(accountId, taskId string) bool
-	GetAllTasks(accountId string) (entities.TaskList, error)
-	GetTaskById(taskId, accountId string) (entities.Task, error)
+	Exists(taskId string, scope ProjectScope) bool
+	GetAllTasks(scope ProjectScope, preload Preload) (entities.TaskList, error)
+	GetTaskById(taskId string, scope ProjectScope, preload Preload) (entities.Task, error)
+	GetTasksByStoryIds(storyIds []string, scope ProjectScope) (entities.TaskList, error)
 	CreateTask(newTask entities.Task) (entities.Task, error)
 	UpdateTask(newTask *entities.Task) (*entities.Task, error)
+	PatchTask(taskId string, changes map[string]interface{}) error
 }
 
 type taskQuery struct {
@@ -21,44 +24,66 @@ func (d *dao) NewTaskQuery(logger ilog.StdLogger) TaskQuery {
 	return &taskQuery{log: logger}
 }
 
-func (q *taskQuery) GetAllTasks(accountId string) (entities.TaskList, error) {
-	q.log.Infof("Fetching all Tasks with accountId %s", accountId)
+// GetAllTasks fetches the tasks in scope, loading the associations given by preload
+func (q *taskQuery) GetAllTasks(scope ProjectScope, preload Preload) (entities.TaskList, error) {
+	q.log.Infof("Fetching all Tasks visible to User{id=%d}", scope.UserId)
 
 	var tasks entities.TaskList
 	err := Database.
-		Preload("Creator", "account_id = ?", accountId).
-		Joins("JOIN users on tasks.creator_id = users.id").
-		Where("users.account_id = ?", accountId).
+		Joins("JOIN stories ON tasks.story_id = stories.id").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(preload.apply, scope.apply).
 		Find(&tasks).
 		Error
 
 	ilog.ErrorlnIf(err, q.log)
-	return tasks, nil
+	return tasks, err
 }
 
-func (q *taskQuery) GetTaskById(taskId, accountId string) (entities.Task, error) {
+// GetTaskById fetches the task in scope, loading the associations given by preload
+func (q *taskQuery) GetTaskById(taskId string, scope ProjectScope, preload Preload) (entities.Task, error) {
 	q.log.Infof("Fetching Task with id %s", taskId)
 
 	var task entities.Task
 	err := Database.
-		Preload("Creator", "account_id = ?", accountId).
-		Joins("JOIN users on tasks.creator_id = users.id").
-		Where("users.account_id = ?", accountId).
-		Find(&task, "tasks.id = ?", taskId).
+		Joins("JOIN stories ON tasks.story_id = stories.id").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(preload.apply, scope.apply).
+		First(&task, "tasks.id = ?", taskId).
 		Error
 
 	ilog.ErrorlnIf(err, q.log)
 	return task, err
 }
 
-func (q *taskQuery) Exists(accountId, taskId string) bool {
+// GetTasksByStoryIds fetches the tasks of the stories in scope among those given, without loading their tags
+func (q *taskQuery) GetTasksByStoryIds(storyIds []string, scope ProjectScope) (entities.TaskList, error) {
+	q.log.Infof("Fetching the Tasks of %d Stories", len(storyIds))
+
+	var tasks entities.TaskList
+	err := Database.
+		Select("tasks.*").
+		Joins("JOIN stories ON tasks.story_id = stories.id").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(scope.apply).
+		Where("tasks.story_id IN (?)", storyIds).
+		Order("tasks.created_at").
+		Find(&tasks).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return tasks, err
+}
+
+func (q *taskQuery) Exists(taskId string, scope ProjectScope) bool {
 	q.log.Infof("Checking if task with Id %s exists", taskId)
 
 	var task entities.Task
 	r := Database.
-		Joins("JOIN users ON tasks.creator_id = users.id").
-		Where("users.account_id = ?", accountId).
-		First(&task, "id = ?", taskId)
+		Joins("JOIN stories ON tasks.story_id = stories.id").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(scope.apply).
+		First(&task, "tasks.id = ?", taskId)
 
 	ilog.ErrorlnIf(r.Error, q.log)
 	return r.RowsAffected == 1
@@ -76,7 +101,11 @@ func (q *taskQuery) CreateTask(newTask entities.Task) (entities.Task, error) {
 func (q *taskQuery) UpdateTask(newTask *entities.Task) (*entities.Task, error) {
 	q.log.Infof("Updating task with taskId %s", newTask.ID)
 
-	err := Database.Save(newTask).Error
+	err := Database.Omit("version").Save(newTask).Error
+	if err == nil {
+		err = bumpVersion(&entities.Task{}, newTask.ID)
+	}
+
 	if err != nil {
 		q.log.Error("Could not update task", err)
 		return nil, err
@@ -84,3 +113,19 @@ func (q *taskQuery) UpdateTask(newTask *entities.Task) (*entities.Task, error) {
 
 	return newTask, nil
 }
+
+// PatchTask persists only the given columns of the task
+func (q *taskQuery) PatchTask(taskId string, changes map[string]interface{}) error {
+	q.log.Infof("Patching task with taskId %s", taskId)
+
+	changes["updated_at"] = time.Now()
+	changes["version"] = nextVersion()
+	err := Database.
+		Model(&entities.Task{}).
+		Where("id = ?", taskId).
+		Updates(changes).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
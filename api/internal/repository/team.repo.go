@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"godo/internal/helper/ilog"
+	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TaskStatusCount the number of tasks assigned to a user with the given status
+type TaskStatusCount struct {
+	AssigneeId uint
+	Status     enums.ProgressStatus
+	Count      int64
+}
+
+type TeamQuery interface {
+	GetTeams(accountId string) (entities.TeamList, error)
+	GetTeamById(teamId, accountId string) (*entities.Team, error)
+	CreateTeam(team *entities.Team) (*entities.Team, error)
+	UpdateTeam(teamId, name string, leadId *uint) error
+	DeleteTeam(teamId string) error
+	AddMember(teamId string, userId uint) error
+	RemoveMember(teamId string, userId uint) (bool, error)
+	GetOpenTaskCounts(teamId string, scope ProjectScope) ([]TaskStatusCount, error)
+}
+
+type teamQuery struct {
+	log ilog.StdLogger
+}
+
+func (d *dao) NewTeamQuery(logger ilog.StdLogger) TeamQuery {
+	return &teamQuery{log: logger}
+}
+
+func (q *teamQuery) GetTeams(accountId string) (entities.TeamList, error) {
+	q.log.Debugf("Fetching the Teams in Account{id=%s}", accountId)
+
+	var teams entities.TeamList
+	err := Database.
+		Preload("Lead").
+		Where("account_id = ?", accountId).
+		Order("name").
+		Find(&teams).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return teams, err
+}
+
+func (q *teamQuery) GetTeamById(teamId, accountId string) (*entities.Team, error) {
+	q.log.Debugf("Fetching Team{id=%s} in Account{id=%s}", teamId, accountId)
+
+	var team entities.Team
+	err := Database.
+		Preload("Lead").
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Order("team_members.created_at")
+		}).
+		Preload("Members.User").
+		First(&team, "id = ? AND account_id = ?", teamId, accountId).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return &team, err
+}
+
+// CreateTeam creates the team, making its lead the first member
+func (q *teamQuery) CreateTeam(team *entities.Team) (*entities.Team, error) {
+	q.log.Debugf("Creating Team with name %s", team.Name)
+
+	tx := Database.Begin()
+	if err := tx.Create(team).Error; err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return nil, err
+	}
+
+	if team.LeadId != nil {
+		if err := addTeamMember(tx, team.ID, *team.LeadId); err != nil {
+			tx.Rollback()
+			q.log.Error(err)
+			return nil, err
+		}
+	}
+
+	return team, tx.Commit().Error
+}
+
+// UpdateTeam renames the team and changes its lead, adding the lead to the team if they are not a member
+func (q *teamQuery) UpdateTeam(teamId, name string, leadId *uint) error {
+	q.log.Debugf("Updating Team{id=%s}", teamId)
+
+	tx := Database.Begin()
+	err := tx.Model(&entities.Team{}).
+		Where("id = ?", teamId).
+		Updates(map[string]interface{}{"name": name, "lead_id": leadId, "updated_at": time.Now()}).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	if leadId != nil {
+		if err := addTeamMember(tx, teamId, *leadId); err != nil {
+			tx.Rollback()
+			q.log.Error(err)
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteTeam deletes the team and its memberships; the projects it owned are left without a team
+func (q *teamQuery) DeleteTeam(teamId string) error {
+	q.log.Debugf("Deleting Team{id=%s}", teamId)
+
+	tx := Database.Begin()
+	err := tx.Model(&entities.Project{}).Where("team_id = ?", teamId).Update("team_id", gorm.Expr("NULL")).Error
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	if err := tx.Where("team_id = ?", teamId).Delete(&entities.TeamMember{}).Error; err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	if err := tx.Where("id = ?", teamId).Delete(&entities.Team{}).Error; err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (q *teamQuery) AddMember(teamId string, userId uint) error {
+	q.log.Debugf("Adding User{id=%d} to Team{id=%s}", userId, teamId)
+
+	err := addTeamMember(Database, teamId, userId)
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+// RemoveMember removes the user from the team, and as its lead if they led it; false is returned if they were not a member
+func (q *teamQuery) RemoveMember(teamId string, userId uint) (bool, error) {
+	q.log.Debugf("Removing User{id=%d} from Team{id=%s}", userId, teamId)
+
+	tx := Database.Begin()
+	r := tx.Where("team_id = ? AND user_id = ?", teamId, userId).Delete(&entities.TeamMember{})
+	if r.Error != nil {
+		tx.Rollback()
+		q.log.Error(r.Error)
+		return false, r.Error
+	}
+
+	err := tx.Model(&entities.Team{}).
+		Where("id = ? AND lead_id = ?", teamId, userId).
+		Update("lead_id", gorm.Expr("NULL")).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return false, err
+	}
+
+	return r.RowsAffected == 1, tx.Commit().Error
+}
+
+// GetOpenTaskCounts counts the tasks in scope which are assigned to members of the team and not yet complete
+func (q *teamQuery) GetOpenTaskCounts(teamId string, scope ProjectScope) ([]TaskStatusCount, error) {
+	q.log.Debugf("Counting the open tasks of the members of Team{id=%s}", teamId)
+
+	var counts []TaskStatusCount
+	err := Database.
+		Table("tasks").
+		Select("tasks.assignee_id, tasks.status, count(*) AS count").
+		Joins("JOIN stories ON tasks.story_id = stories.id").
+		Joins("JOIN projects ON stories.project_id = projects.id").
+		Scopes(scope.apply).
+		Where("tasks.assignee_id IN (SELECT user_id FROM team_members WHERE team_id = ?)", teamId).
+		Where("tasks.status <> ? AND tasks.deleted_at IS NULL", enums.Complete).
+		Group("tasks.assignee_id, tasks.status").
+		Scan(&counts).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return counts, err
+}
+
+func addTeamMember(db *gorm.DB, teamId string, userId uint) error {
+	var member entities.TeamMember
+	return db.
+		Where(entities.TeamMember{TeamId: teamId, UserId: userId}).
+		FirstOrCreate(&member).
+		Error
+}
@@ -1,17 +1,62 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	ehand "godo/internal/api/errorhandler"
 	"godo/internal/helper/ilog"
 	"godo/internal/helper/validate"
 	"godo/internal/repository/entities"
+	"godo/internal/repository/enums"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+const (
+	// The number of times allocating a discriminator is attempted before giving up
+	maxDiscriminatorAttempts = 5
+
+	// The PostgreSQL error code for a unique constraint violation
+	uniqueViolation = "23505"
+)
+
+const (
+	// Assigns the open tasks in the account's projects from one user to another
+	reassignOpenTasks = `UPDATE tasks SET assignee_id = ?
+		WHERE assignee_id = ? AND status <> ? AND deleted_at IS NULL AND story_id IN (
+			SELECT stories.id FROM stories JOIN projects ON stories.project_id = projects.id
+			WHERE projects.account_id = ?)`
+
+	// Copies one user's memberships of the account's projects to another, keeping the greater role where both are members
+	transferMemberships = `INSERT INTO project_members (project_id, user_id, role, created_at)
+		SELECT project_id, ?, role, now() FROM project_members
+		WHERE user_id = ? AND project_id IN (SELECT id FROM projects WHERE account_id = ?)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET role = GREATEST(project_members.role, EXCLUDED.role)`
+
+	// Removes a user from the account's projects
+	removeMemberships = `DELETE FROM project_members
+		WHERE user_id = ? AND project_id IN (SELECT id FROM projects WHERE account_id = ?)`
 )
 
 type ApiUserQuery interface {
 	CreateUser(user entities.User) (*entities.User, error)
+	CreateAccountOwner(account *entities.Account, owner entities.User) (*entities.User, error)
 	GetUserByEmailAddress(email string) (*entities.User, error)
 	UserWithEmailAddressExists(email string) (bool, error)
+	UpdateUser(user *entities.User) error
+	GetUserById(userId uint, accountId string) (*entities.User, error)
+	GetUsersInAccount(accountId string) (entities.UserList, error)
+	GetUsersByIds(userIds []uint, accountId string) (entities.UserList, error)
+	UpdateProfile(user *entities.User, name, username string) error
+	GetUserByHandle(username string, discriminator uint32, accountId string) (*entities.User, error)
+	ChangeRole(userId uint, accountId string, role enums.AccountRole) error
+	DeactivateUser(userId uint, accountId string) error
+	ReactivateUser(userId uint, accountId string) error
+	OffboardUser(userId, successorId uint, accountId string) (*entities.Offboarding, error)
+	ReserveTwoFactorAttempt(userId uint, lockUntil func(failures uint) *time.Time) (uint, bool, error)
 }
 
 type apiUserQuery struct {
@@ -23,6 +68,21 @@ func (d *dao) NewApiUserQuery(logger ilog.StdLogger) ApiUserQuery {
 }
 
 func (q *apiUserQuery) CreateUser(newUser entities.User) (*entities.User, error) {
+	return q.createUser(nil, newUser)
+}
+
+// CreateAccountOwner creates the account along with its owner, in one transaction such that an account is
+// never left without anyone to manage it
+func (q *apiUserQuery) CreateAccountOwner(account *entities.Account, owner entities.User) (*entities.User, error) {
+	q.log.Debugf("Creating Account{name=%s} with its owner", account.Name)
+
+	owner.Role = enums.Owner
+	return q.createUser(account, owner)
+}
+
+// createUser inserts the user, making them a member of their account; when given, the account is created in
+// the same transaction as the user
+func (q *apiUserQuery) createUser(newAccount *entities.Account, newUser entities.User) (*entities.User, error) {
 	q.log.Info("Registering new user")
 
 	// Check if a user with the username or email already exists
@@ -34,15 +94,21 @@ func (q *apiUserQuery) CreateUser(newUser entities.User) (*entities.User, error)
 		return nil, ehand.ErrorUserNotFound
 	}
 
-	// Add the discriminator to the user
-	discriminator := q.GetNextDiscriminator(newUser.Username)
-	newUser.Discriminator = discriminator
+	// Validate the user; the discriminator is allocated when the user is inserted, as is the account of a
+	// user created with their account
+	newUser.Discriminator = 1
+	newUser.DefaultAccountId = newUser.AccountId
+
+	var err error
+	if newAccount != nil {
+		err = validate.StructExcept(newUser, "DefaultAccountId")
+	} else {
+		err = validate.Struct(newUser)
+	}
 
-	// Validate the user
-	err := validate.Struct(newUser)
 	if err != nil {
 		q.log.Warn("The user did not pass validation. ", err)
-		return nil, fmt.Errorf("the user is not valid: %s", err)
+		return nil, fmt.Errorf("the user is not valid: %w", err)
 	}
 
 	// Hash the user's password
@@ -51,10 +117,37 @@ func (q *apiUserQuery) CreateUser(newUser entities.User) (*entities.User, error)
 		return nil, err
 	}
 
-	// Insert the user into the database
-	err = Database.Create(&newUser).Error
-	ilog.ErrorlnIf(err, q.log)
+	// Insert the user into the database, making them a member of their account
+	member := entities.AccountMember{AccountId: newUser.AccountId, Role: newUser.Role}
+	err = q.withDiscriminator(&newUser, func() error {
+		tx := Database.Begin()
+		if newAccount != nil {
+			if err := tx.Create(newAccount).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
 
+			newUser.AccountId = newAccount.ID
+			newUser.DefaultAccountId = newAccount.ID
+			member.AccountId = newAccount.ID
+		}
+
+		if err := tx.Create(&newUser).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		member.UserId = newUser.ID
+		if err := tx.Create(&member).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		return tx.Commit().Error
+	})
+
+	ilog.ErrorlnIf(err, q.log)
+	newUser.ApplyMembership(&member)
 	return &newUser, err
 }
 
@@ -82,24 +175,366 @@ func (q *apiUserQuery) UserWithEmailAddressExists(email string) (bool, error) {
 	return count >= 1, r.Error
 }
 
-func (q *apiUserQuery) GetNextDiscriminator(username string) uint32 {
-	var result uint32
+func (q *apiUserQuery) UpdateUser(user *entities.User) error {
+	q.log.Debugf("Updating User{id=%d}", user.ID)
+
+	err := Database.Save(user).Error
+	ilog.ErrorlnIf(err, q.log)
+
+	return err
+}
+
+func (q *apiUserQuery) GetUserById(userId uint, accountId string) (*entities.User, error) {
+	q.log.Debugf("Fetching User{id=%d} in Account{id=%s}", userId, accountId)
+
+	var member entities.AccountMember
+	err := Database.
+		Preload("User").
+		First(&member, "account_id = ? AND user_id = ?", accountId, userId).
+		Error
+
+	if err != nil {
+		q.log.Error(err)
+		return nil, ehand.ErrorUserNotFound
+	}
+
+	return memberToUser(&member), nil
+}
+
+func (q *apiUserQuery) GetUsersInAccount(accountId string) (entities.UserList, error) {
+	q.log.Debugf("Fetching all Users in Account{id=%s}", accountId)
+
+	var members entities.AccountMemberList
+	err := Database.
+		Preload("User").
+		Joins("JOIN users ON users.id = account_members.user_id").
+		Where("account_members.account_id = ?", accountId).
+		Order("users.username, users.discriminator").
+		Find(&members).
+		Error
+
+	if err != nil {
+		q.log.Error(err)
+		return nil, err
+	}
+
+	users := make(entities.UserList, 0, len(members))
+	for _, member := range members {
+		users = append(users, memberToUser(member))
+	}
+
+	return users, nil
+}
+
+// GetUsersByIds fetches the members of the account among the given users
+func (q *apiUserQuery) GetUsersByIds(userIds []uint, accountId string) (entities.UserList, error) {
+	q.log.Debugf("Fetching %d Users in Account{id=%s}", len(userIds), accountId)
+
+	var members entities.AccountMemberList
+	err := Database.
+		Preload("User").
+		Where("account_id = ? AND user_id IN (?)", accountId, userIds).
+		Find(&members).
+		Error
+
+	if err != nil {
+		q.log.Error(err)
+		return nil, err
+	}
+
+	users := make(entities.UserList, 0, len(members))
+	for _, member := range members {
+		users = append(users, memberToUser(member))
+	}
+
+	return users, nil
+}
+
+// UpdateProfile updates the user's name and username, issuing a new discriminator if the username changes
+func (q *apiUserQuery) UpdateProfile(user *entities.User, name, username string) error {
+	q.log.Debugf("Updating the profile of User{id=%d}", user.ID)
+
+	user.Name = name
+	if err := validate.Struct(user); err != nil {
+		q.log.Warn("The user did not pass validation. ", err)
+		return fmt.Errorf("the user is not valid: %w", err)
+	}
+
+	save := func() error {
+		return Database.Save(user).Error
+	}
+
+	var err error
+	if username != user.Username {
+		user.Username = username
+		err = q.withDiscriminator(user, save)
+	} else {
+		err = save()
+	}
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+// ChangeRole updates the user's role in the account, refusing to demote the last owner of the account.
+// The account's owners are locked for the duration so concurrent demotions can't both succeed.
+func (q *apiUserQuery) ChangeRole(userId uint, accountId string, role enums.AccountRole) error {
+	q.log.Debugf("Changing the role of User{id=%d} to %s", userId, role)
+
+	tx := Database.Begin()
+
+	owners, err := lockActiveOwners(tx, accountId)
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	demotingOwner := false
+	for _, owner := range owners {
+		if owner.UserId == userId && role != enums.Owner {
+			demotingOwner = true
+		}
+	}
+
+	if demotingOwner && len(owners) <= 1 {
+		tx.Rollback()
+		return ehand.ErrorLastOwner
+	}
+
+	err = tx.Model(&entities.AccountMember{}).
+		Where("user_id = ? AND account_id = ?", userId, accountId).
+		Update("role", role).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ReserveTwoFactorAttempt counts an attempt at a two-factor code against the user before the code is checked,
+// such that concurrent guesses are each counted, returning the consecutive failures including it. The user
+// is locked out until the time lockUntil gives for that count; while they are, the attempt is refused and
+// not counted. The count is reset by saving the user once a code is accepted.
+func (q *apiUserQuery) ReserveTwoFactorAttempt(userId uint, lockUntil func(failures uint) *time.Time) (uint, bool, error) {
+	tx := Database.Begin()
+
+	var user entities.User
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", userId).
+		First(&user).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return 0, false, err
+	}
+
+	if user.TwoFactorLockedUntil != nil && user.TwoFactorLockedUntil.After(time.Now()) {
+		tx.Rollback()
+		return user.TwoFactorFailures, false, nil
+	}
+
+	failures := user.TwoFactorFailures + 1
+	err = tx.Model(&entities.User{}).
+		Where("id = ?", userId).
+		UpdateColumns(map[string]interface{}{
+			"two_factor_failures":     failures,
+			"two_factor_locked_until": lockUntil(failures),
+		}).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return 0, false, err
+	}
+
+	return failures, true, tx.Commit().Error
+}
+
+// DeactivateUser prevents the user from acting within the account
+func (q *apiUserQuery) DeactivateUser(userId uint, accountId string) error {
+	q.log.Debugf("Deactivating User{id=%d}", userId)
+
+	tx := Database.Begin()
+	if err := q.deactivate(tx, userId, accountId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (q *apiUserQuery) ReactivateUser(userId uint, accountId string) error {
+	q.log.Debugf("Reactivating User{id=%d}", userId)
+
+	err := Database.Model(&entities.AccountMember{}).
+		Where("user_id = ? AND account_id = ?", userId, accountId).
+		Update("deactivated_at", gorm.Expr("NULL")).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+// OffboardUser deactivates the user in the account, assigning their open tasks to the successor and handing
+// the successor their memberships of the account's projects. The user remains the creator of their work.
+func (q *apiUserQuery) OffboardUser(userId, successorId uint, accountId string) (*entities.Offboarding, error) {
+	q.log.Debugf("Offboarding User{id=%d} to User{id=%d}", userId, successorId)
+
+	tx := Database.Begin()
+	if err := q.deactivate(tx, userId, accountId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tasks := tx.Exec(reassignOpenTasks, successorId, userId, enums.Complete, accountId)
+	if tasks.Error != nil {
+		tx.Rollback()
+		q.log.Error(tasks.Error)
+		return nil, tasks.Error
+	}
+
+	memberships := tx.Exec(transferMemberships, successorId, userId, accountId)
+	if memberships.Error != nil {
+		tx.Rollback()
+		q.log.Error(memberships.Error)
+		return nil, memberships.Error
+	}
+
+	err := tx.Exec(removeMemberships, userId, accountId).Error
+	if err != nil {
+		tx.Rollback()
+		q.log.Error(err)
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &entities.Offboarding{
+		UserId:              userId,
+		SuccessorId:         successorId,
+		TasksReassigned:     tasks.RowsAffected,
+		ProjectsTransferred: memberships.RowsAffected,
+	}, nil
+}
+
+// deactivate marks the user's membership of the account as deactivated, refusing to deactivate
+// the last active owner of the account. The account's owners are locked until tx completes.
+func (q *apiUserQuery) deactivate(tx *gorm.DB, userId uint, accountId string) error {
+	owners, err := lockActiveOwners(tx, accountId)
+	if err != nil {
+		q.log.Error(err)
+		return err
+	}
+
+	for _, owner := range owners {
+		if owner.UserId == userId && len(owners) <= 1 {
+			return ehand.ErrorLastOwner
+		}
+	}
+
+	err = tx.Model(&entities.AccountMember{}).
+		Where("user_id = ? AND account_id = ?", userId, accountId).
+		Update("deactivated_at", time.Now()).
+		Error
+
+	ilog.ErrorlnIf(err, q.log)
+	return err
+}
+
+func (q *apiUserQuery) GetUserByHandle(username string, discriminator uint32, accountId string) (*entities.User, error) {
+	q.log.Debugf("Fetching User{handle=%s#%d} in Account{id=%s}", username, discriminator, accountId)
+
+	var member entities.AccountMember
+	err := Database.
+		Preload("User").
+		Joins("JOIN users ON users.id = account_members.user_id").
+		Where("users.username = ? AND users.discriminator = ?", username, discriminator).
+		First(&member, "account_members.account_id = ?", accountId).
+		Error
+
+	if err != nil {
+		q.log.Error(err)
+		return nil, ehand.ErrorUserNotFound
+	}
+
+	return memberToUser(&member), nil
+}
+
+// withDiscriminator allocates the next discriminator for the user's username and saves the user.
+// Concurrent allocations of the same discriminator are rejected by the unique index on the handle,
+// in which case a new discriminator is allocated and the save retried.
+func (q *apiUserQuery) withDiscriminator(user *entities.User, save func() error) error {
+	for attempt := 1; ; attempt++ {
+		discriminator, err := q.GetNextDiscriminator(user.Username)
+		if err != nil {
+			return err
+		}
+
+		user.Discriminator = discriminator
+		err = save()
+		if err == nil || !isHandleConflict(err) || attempt == maxDiscriminatorAttempts {
+			return err
+		}
+
+		q.log.Infof("Discriminator %d for username %s was taken concurrently, retrying", discriminator, user.Username)
+	}
+}
+
+// GetNextDiscriminator returns the discriminator following the highest in use for the username
+func (q *apiUserQuery) GetNextDiscriminator(username string) (uint32, error) {
+	var result sql.NullInt64
 	row := Database.Table("users").
 		Where("username = ?", username).
 		Select("max(discriminator)").
 		Row()
 
-	err := row.Scan(&result)
-	if err != nil {
+	if err := row.Scan(&result); err != nil {
 		q.log.Error(err.Error())
+		return 0, err
+	}
+
+	if !result.Valid || result.Int64 < 1 {
 		q.log.Infof("No user with username %s exists. Using discriminator of 1.", username)
-		return 1
+		return 1, nil
 	}
 
-	if result < 1 {
-		q.log.Warnf("A less than 1 result of %d was returned for the discriminator", result)
-		return 1
+	if result.Int64 >= entities.MaxDiscriminator {
+		q.log.Warnf("Every discriminator for username %s is in use", username)
+		return 0, ehand.ErrorUsernameUnavailable
 	}
 
-	return result + 1
+	return uint32(result.Int64) + 1, nil
+}
+
+// lockActiveOwners returns the active owners of the account, locking them until tx completes
+func lockActiveOwners(tx *gorm.DB, accountId string) (entities.AccountMemberList, error) {
+	var owners entities.AccountMemberList
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("account_id = ? AND role = ? AND deactivated_at IS NULL", accountId, enums.Owner).
+		Find(&owners).
+		Error
+
+	return owners, err
+}
+
+// memberToUser returns the member's user acting within the member's account
+func memberToUser(member *entities.AccountMember) *entities.User {
+	user := member.User
+	user.ApplyMembership(member)
+	return &user
+}
+
+func isHandleConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "idx_users_handle"
 }
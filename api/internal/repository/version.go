@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// nextVersion the version of the project, story or task being updated; each change increments its
+// version, from which the ETags of its responses are computed
+func nextVersion() *gorm.SqlExpr {
+	return gorm.Expr("version + 1")
+}
+
+// bumpVersion increments the version of the entity, as when saved without its version
+func bumpVersion(model interface{}, id string) error {
+	return Database.Model(model).Where("id = ?", id).UpdateColumn("version", nextVersion()).Error
+}
+
+// touch marks the projects, stories or tasks matching the condition as changed by a change to what they
+// hold which leaves no timestamp of its own, such as a deleted story or tag, such that their responses are
+// neither answered as not modified since before it nor given the same ETag
+func touch(model interface{}, query string, args ...interface{}) error {
+	return Database.
+		Model(model).
+		Where(query, args...).
+		Updates(map[string]interface{}{"updated_at": time.Now(), "version": nextVersion()}).
+		Error
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"godo/internal/api/services"
 	"godo/internal/helper/router_builder"
+	"godo/internal/helper/validate"
 	"godo/internal/repository"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	_ "github.com/jinzhu/gorm/dialects/postgres"
@@ -18,13 +23,60 @@ func main() {
 
 	config := configuration.LoadConfig(configLogger)
 
+	if err := validate.CheckTags(); err != nil {
+		logger.Fatal("The validation rules are not valid: ", err)
+	}
+
+	// The API documents are generated without a database
+	if len(os.Args) > 3 && os.Args[1] == "document" {
+		rb := router_builder.New(repository.NewDetachedDAO(daoLogger), config)
+		rb.Init()
+		document(rb, os.Args[2], os.Args[3])
+		return
+	}
+
 	//repository.CreateAndPopulateDatabase(logger)
 	dao := repository.NewDAO(daoLogger)
 
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rotateSigningKeys(dao, config)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "purge-accounts" {
+		purgeAccounts(dao, config)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "backfill-owners" {
+		backfillOwners(dao, config)
+		return
+	}
+
+	if len(os.Args) > 2 && os.Args[1] == "erase-user" {
+		eraseUser(dao, os.Args[2])
+		return
+	}
+
 	rb := router_builder.New(dao, config)
 	router := rb.Init()
 
-	router.Handle("/swagger.yaml", http.FileServer(http.Dir("./")))
+	if len(os.Args) > 1 && os.Args[1] == "check-routes" {
+		checkRoutes(rb)
+		return
+	}
+
+	// Requests are validated against the API documents, so the server does not start while they do not
+	// match the routes
+	if err := rb.CheckRoutes(); err != nil {
+		logger.Fatal(err)
+	}
+
+	go purgeAccountsPeriodically(dao, config)
+
+	if config.GrpcPort != "" {
+		go serveGRPC(rb, config)
+	}
 
 	srv := &http.Server{
 		Addr:         "0.0.0.0:" + config.ApiPort,
@@ -37,3 +89,137 @@ func main() {
 		logger.Fatal(err)
 	}
 }
+
+// serveGRPC serves the gRPC API alongside the REST API
+func serveGRPC(rb router_builder.RouterBuilder, config configuration.Config) {
+	logger := ilog.MakeLoggerWithTag("GRPC")
+
+	listener, err := net.Listen("tcp", "0.0.0.0:"+config.GrpcPort)
+	if err != nil {
+		logger.Fatal("Could not listen for gRPC calls: ", err)
+	}
+
+	logger.Infof("Serving the gRPC API on port %s", config.GrpcPort)
+	if err := rb.InitGRPC().Serve(listener); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// rotateSigningKeys creates a new JWT signing key, e.g. `go run . rotate-keys`.
+// Running instances pick up the new key within a minute; the previous keys remain
+// valid for verification for JWT_KEY_GRACE_PERIOD so that no one is logged out.
+func rotateSigningKeys(dao repository.DAO, config configuration.Config) {
+	logger := ilog.MakeLoggerWithTag("RotateKeys")
+
+	keyService := services.NewSigningKeyService(
+		dao.NewSigningKeyQuery(logger),
+		config.JWTAlgorithm,
+		config.JWTKeyGracePeriod,
+		[]byte(config.JWTKey),
+		logger,
+	)
+
+	key, err := keyService.RotateKeys()
+	if err != nil {
+		logger.Fatal("Could not rotate the signing keys: ", err)
+	}
+
+	logger.Infof("Rotated the signing keys, now signing with %s key %s", key.Algorithm, key.Kid)
+}
+
+// checkRoutes fails unless every documented operation is routed and every route documented,
+// e.g. `go run . check-routes`
+func checkRoutes(rb router_builder.RouterBuilder) {
+	logger := ilog.MakeLoggerWithTag("CheckRoutes")
+
+	if err := rb.CheckRoutes(); err != nil {
+		logger.Fatal(err)
+	}
+
+	logger.Info("The routes match the API documents")
+}
+
+// document writes the API document of a version to ./swagger/<version>.yaml from the document go-swagger
+// scanned from the handlers, e.g. `go run . document v1 ./swagger/v1.scanned.yaml`; see `make swagger`
+func document(rb router_builder.RouterBuilder, version string, scannedPath string) {
+	logger := ilog.MakeLoggerWithTag("Document")
+
+	scanned, err := os.ReadFile(scannedPath)
+	if err != nil {
+		logger.Fatal("Could not read the scanned API document: ", err)
+	}
+
+	doc, err := rb.Document(version, scanned)
+	if err != nil {
+		logger.Fatal("Could not generate the API document: ", err)
+	}
+
+	if err := os.WriteFile("./swagger/"+version+".yaml", doc, 0644); err != nil {
+		logger.Fatal("Could not write the API document: ", err)
+	}
+
+	logger.Infof("Wrote the API document of %s", version)
+}
+
+// eraseUser anonymises a user in answer to an erasure request received outside the API,
+// e.g. `go run . erase-user 42`; their work is kept, attributed to a tombstone identity
+func eraseUser(dao repository.DAO, userId string) {
+	logger := ilog.MakeLoggerWithTag("EraseUser")
+	personalDataService := services.NewPersonalDataService(dao.NewPersonalDataQuery(logger), logger)
+
+	id, err := strconv.ParseUint(userId, 10, 32)
+	if err != nil {
+		logger.Fatalf("%s is not a valid user ID", userId)
+	}
+
+	if err := personalDataService.EraseUser(uint(id)); err != nil {
+		logger.Fatalf("Could not erase User{id=%d}: %s", id, err)
+	}
+
+	logger.Infof("Erased the personal data of User{id=%d}", id)
+}
+
+// How often the server erases accounts whose deletion grace period has passed
+const accountPurgeInterval = time.Hour
+
+// purgeAccounts erases every account whose scheduled deletion is due, e.g. `go run . purge-accounts`
+func purgeAccounts(dao repository.DAO, config configuration.Config) {
+	logger := ilog.MakeLoggerWithTag("PurgeAccounts")
+	accountService := services.NewAccountService(dao.NewAccountQuery(logger), config.AccountDeletionGracePeriod, logger)
+
+	purged, err := accountService.PurgeDueAccounts()
+	if err != nil {
+		logger.Fatal("Could not purge the accounts scheduled for deletion: ", err)
+	}
+
+	logger.Infof("Purged %d account(s) scheduled for deletion", purged)
+}
+
+// backfillOwners makes the earliest user of every account without an owner its owner, e.g.
+// `go run . backfill-owners`; run once after upgrading, as the accounts created before account roles
+// were added have no owner to administer them
+func backfillOwners(dao repository.DAO, config configuration.Config) {
+	logger := ilog.MakeLoggerWithTag("BackfillOwners")
+	accountService := services.NewAccountService(dao.NewAccountQuery(logger), config.AccountDeletionGracePeriod, logger)
+
+	promoted, err := accountService.BackfillOwners()
+	if err != nil {
+		logger.Fatal("Could not backfill the owners of the accounts: ", err)
+	}
+
+	logger.Infof("Made the earliest user the owner of %d account(s) without one", promoted)
+}
+
+func purgeAccountsPeriodically(dao repository.DAO, config configuration.Config) {
+	logger := ilog.MakeLoggerWithTag("PurgeAccounts")
+	accountService := services.NewAccountService(dao.NewAccountQuery(logger), config.AccountDeletionGracePeriod, logger)
+
+	ticker := time.NewTicker(accountPurgeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := accountService.PurgeDueAccounts(); err != nil {
+			logger.Error("Could not purge the accounts scheduled for deletion: ", err)
+		}
+	}
+}